@@ -0,0 +1,207 @@
+// Package apperr defines the typed errors the service layer returns and how
+// they are reported to gRPC clients.
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Kind classifies an error by what the client can do about it
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	// KindNotFound means the referenced appointment, room or record does not exist
+	KindNotFound
+	// KindConflict means the request clashes with existing state, such as a taken slot
+	KindConflict
+	// KindFailedPrecondition means the record is not in a state that allows the request
+	KindFailedPrecondition
+	// KindUnavailable means a database or downstream service could not be reached
+	KindUnavailable
+	// KindInvalidArgument means the request itself is malformed; see Violations
+	KindInvalidArgument
+	// KindUnauthenticated means the caller could not be identified
+	KindUnauthenticated
+	// KindPermissionDenied means the caller may not make this request
+	KindPermissionDenied
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindConflict:
+		return "conflict"
+	case KindFailedPrecondition:
+		return "failed precondition"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInvalidArgument:
+		return "invalid argument"
+	case KindUnauthenticated:
+		return "unauthenticated"
+	case KindPermissionDenied:
+		return "permission denied"
+	}
+	return "unknown"
+}
+
+// Error is a domain error of a known kind. The optional fields are sent to
+// the client as structured status details.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+
+	// Reason is a short UPPER_SNAKE_CASE code sent with Metadata as ErrorInfo
+	Reason   string
+	Metadata map[string]string
+	// PaymentURL is where the patient completes a pending payment
+	PaymentURL string
+	// SuggestedSlots are times the client may book instead
+	SuggestedSlots []time.Time
+	// Violations lists the request fields that failed validation
+	Violations []FieldViolation
+}
+
+// FieldViolation names a request field by its proto name and what is wrong with it
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Kind.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func FailedPrecondition(message string) *Error {
+	return &Error{Kind: KindFailedPrecondition, Message: message}
+}
+
+func Unauthenticated(message string) *Error {
+	return &Error{Kind: KindUnauthenticated, Message: message}
+}
+
+func PermissionDenied(message string) *Error {
+	return &Error{Kind: KindPermissionDenied, Message: message}
+}
+
+// InvalidArgument reports every field violation of a request at once
+func InvalidArgument(violations ...FieldViolation) *Error {
+	parts := make([]string, len(violations))
+	for i, v := range violations {
+		parts[i] = v.Field + " " + v.Description
+	}
+	return &Error{Kind: KindInvalidArgument, Message: "invalid request: " + strings.Join(parts, "; "), Violations: violations}
+}
+
+// Unavailable wraps the failure of a dependency with the message shown to the client
+func Unavailable(message string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Message: message, Err: err}
+}
+
+// WithReason attaches a machine readable reason and metadata
+func (e *Error) WithReason(reason string, metadata map[string]string) *Error {
+	e.Reason = reason
+	e.Metadata = metadata
+	return e
+}
+
+func (e *Error) WithPaymentURL(url string) *Error {
+	e.PaymentURL = url
+	return e
+}
+
+func (e *Error) WithSuggestedSlots(slots ...time.Time) *Error {
+	e.SuggestedSlots = append(e.SuggestedSlots, slots...)
+	return e
+}
+
+// KindOf reports the kind of err. Errors that were not created by this
+// package are classified where the cause is unambiguous: missing rows,
+// unique key violations, network failures, lost database connections and
+// unreachable gRPC services.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return KindNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return KindConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return KindUnavailable
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.As(err, &connectErr):
+		return KindUnavailable
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && isConnectionFailure(pgErr.Code) {
+		return KindUnavailable
+	}
+	if st, ok := status.FromError(err); ok && (st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded) {
+		return KindUnavailable
+	}
+	return KindUnknown
+}
+
+// isConnectionFailure reports whether a Postgres SQLSTATE means the server
+// dropped or refused the connection rather than rejecting the statement
+func isConnectionFailure(code string) bool {
+	switch code {
+	case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+		return true
+	}
+	return strings.HasPrefix(code, "08") // connection_exception
+}
+
+// Reason returns the machine readable reason carried by err, if any
+func Reason(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
+
+// PaymentURL returns the payment link carried by err, if any
+func PaymentURL(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.PaymentURL
+	}
+	return ""
+}
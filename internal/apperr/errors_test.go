@@ -0,0 +1,35 @@
+package apperr
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestKindOfDatabaseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"missing row", gorm.ErrRecordNotFound, KindNotFound},
+		{"unique violation", gorm.ErrDuplicatedKey, KindConflict},
+		{"bad connection", fmt.Errorf("query: %w", driver.ErrBadConn), KindUnavailable},
+		{"connection refused", &pgconn.ConnectError{Config: &pgconn.Config{}}, KindUnavailable},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, KindUnavailable},
+		{"server shutting down", &pgconn.PgError{Code: "57P01"}, KindUnavailable},
+		{"undefined column", &pgconn.PgError{Code: "42703"}, KindUnknown},
+		{"plain error", errors.New("boom"), KindUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain identifies this service in ErrorInfo details
+const ErrorDomain = "appointment.hospconnect"
+
+// Code maps err to the gRPC code a client should see
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if errors.Is(err, context.Canceled) {
+		return codes.Canceled
+	}
+	switch KindOf(err) {
+	case KindNotFound:
+		return codes.NotFound
+	case KindConflict:
+		return codes.AlreadyExists
+	case KindFailedPrecondition:
+		return codes.FailedPrecondition
+	case KindInvalidArgument:
+		return codes.InvalidArgument
+	case KindUnauthenticated:
+		return codes.Unauthenticated
+	case KindPermissionDenied:
+		return codes.PermissionDenied
+	case KindUnavailable:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return codes.DeadlineExceeded
+		}
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
+
+// HTTPStatus is the StatusCode legacy response bodies carry for err. Errors
+// of unknown kind keep the historical 400.
+func HTTPStatus(err error) int {
+	switch Code(err) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadRequest
+}
+
+// Status converts err to a gRPC status carrying its details. Status errors
+// from downstream services are passed on unchanged unless wrapped in an Error.
+func Status(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		return status.New(Code(err), err.Error())
+	}
+
+	var details []protoadapt.MessageV1
+	if e.Reason != "" || len(e.SuggestedSlots) > 0 {
+		info := &errdetails.ErrorInfo{Reason: e.Reason, Domain: ErrorDomain, Metadata: map[string]string{}}
+		for k, v := range e.Metadata {
+			info.Metadata[k] = v
+		}
+		if len(e.SuggestedSlots) > 0 {
+			slots := make([]string, len(e.SuggestedSlots))
+			for i, slot := range e.SuggestedSlots {
+				slots[i] = slot.UTC().Format(time.RFC3339)
+			}
+			info.Metadata["suggested_slots"] = strings.Join(slots, ",")
+		}
+		details = append(details, info)
+	}
+	if len(e.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Description})
+		}
+		details = append(details, badRequest)
+	}
+	if e.PaymentURL != "" {
+		details = append(details, &errdetails.Help{Links: []*errdetails.Help_Link{{Description: "Complete the pending payment", Url: e.PaymentURL}}})
+	}
+	if e.Kind == KindFailedPrecondition && e.Reason != "" {
+		details = append(details, &errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: e.Reason, Description: e.Message}}})
+	}
+
+	st := status.New(Code(err), err.Error())
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st
+}
@@ -0,0 +1,230 @@
+// Package audit builds the entries of the tamper-evident audit log and
+// checks the hash chain that links them.
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Audited actions
+const (
+	ActionCreate           = "appointment.create"
+	ActionCancel           = "appointment.cancel"
+	ActionComplete         = "appointment.complete"
+	ActionStatusChange     = "appointment.status_change"
+	ActionPaymentCollected = "appointment.payment_collected"
+	ActionRoomCreate       = "appointment.room_create"
+	ActionPatientErase     = "patient.erase"
+)
+
+// Audited reads
+const (
+	ActionRead          = "appointment.read"
+	ActionSearch        = "appointment.search"
+	ActionHistoryRead   = "patient.history_read"
+	ActionDocumentRead  = "patient.document_read"
+	ActionPatientExport = "patient.export"
+	ActionReportExport  = "report.export"
+)
+
+// Actions lists every action the log records, for filtering queries
+var Actions = []string{
+	ActionCreate, ActionCancel, ActionComplete, ActionStatusChange, ActionPaymentCollected, ActionRoomCreate, ActionPatientErase,
+	ActionRead, ActionSearch, ActionHistoryRead, ActionDocumentRead, ActionPatientExport, ActionReportExport,
+}
+
+// ActorSystem is recorded for changes made by scheduled jobs
+const ActorSystem = "system"
+
+// RequestIdHeader carries the request id in and out of every call
+const RequestIdHeader = "x-request-id"
+
+type requestIdKey struct{}
+
+// RequestId returns the id of the request ctx belongs to, if any
+func RequestId(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// RequestIdInterceptor uses the caller's request id or assigns one, and echoes it in the response header
+func RequestIdInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIdHeader); len(values) > 0 && len(values[0]) <= 128 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIdHeader, id))
+	return handler(context.WithValue(ctx, requestIdKey{}, id), req)
+}
+
+// NewEntry describes an action on an appointment by the caller in ctx.
+// before and after are stored as JSON; pass nil when there is no value.
+func NewEntry(ctx context.Context, action string, appointmentId int, before, after interface{}) domain.AuditEntry {
+	entry := domain.AuditEntry{
+		RequestId:     RequestId(ctx),
+		ActorId:       ActorSystem,
+		ActorRole:     ActorSystem,
+		Action:        action,
+		AppointmentId: appointmentId,
+		Before:        snapshot(before),
+		After:         snapshot(after),
+	}
+	if id, ok := auth.FromContext(ctx); ok {
+		entry.ActorId = id.Subject
+		entry.ActorRole = id.Role
+	}
+	return entry
+}
+
+// NewReadEntry describes a read by the caller in ctx of data about patientId.
+// details, usually the query, is stored as After.
+func NewReadEntry(ctx context.Context, action string, appointmentId int, patientId string, details interface{}) domain.AuditEntry {
+	entry := NewEntry(ctx, action, appointmentId, nil, details)
+	entry.PatientId = patientId
+	return entry
+}
+
+func snapshot(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Seal links entry to the previous hash, stamping its time. Postgres keeps
+// microseconds, so the time is truncated to what will be read back.
+func Seal(entry domain.AuditEntry, prevHash string, now time.Time) domain.AuditEntry {
+	entry.CreatedAt = now.UTC().Truncate(time.Microsecond)
+	entry.PrevHash = prevHash
+	entry.PayloadDigest = PayloadDigest(entry)
+	entry.IdentityDigest = IdentityDigest(entry)
+	entry.Hash = Hash(entry)
+	return entry
+}
+
+// PayloadDigest hashes the before and after values of an entry
+func PayloadDigest(entry domain.AuditEntry) string {
+	return digest(entry.Before, entry.After)
+}
+
+// IdentityDigest hashes the request and actor ids of an entry
+func IdentityDigest(entry domain.AuditEntry) string {
+	return digest(entry.RequestId, entry.ActorId)
+}
+
+// Hash is the chain hash of an entry: its previous hash, metadata and the
+// identity and payload digests
+func Hash(entry domain.AuditEntry) string {
+	return digest(
+		entry.PrevHash,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		entry.IdentityDigest,
+		entry.ActorRole,
+		entry.Action,
+		strconv.Itoa(entry.AppointmentId),
+		entry.PayloadDigest,
+	)
+}
+
+// digest hashes the JSON array of fields, so field boundaries are unambiguous
+func digest(fields ...string) string {
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks that entries, in chain order, follow prevHash and that each
+// entry's ids, payload and hash match. Redacted entries have no original ids
+// or payload left to check. It stops at the first broken link.
+func Verify(entries []domain.AuditEntry, prevHash string, result *domain.AuditVerification) bool {
+	for _, entry := range entries {
+		switch {
+		case entry.PrevHash != prevHash:
+			result.BrokenAt, result.Reason = entry.Id, "entry does not link to the previous entry"
+		case !entry.Redacted && PayloadDigest(entry) != entry.PayloadDigest:
+			result.BrokenAt, result.Reason = entry.Id, "before or after values were changed"
+		case !entry.Redacted && IdentityDigest(entry) != entry.IdentityDigest:
+			result.BrokenAt, result.Reason = entry.Id, "request or actor id was changed"
+		case Hash(entry) != entry.Hash:
+			result.BrokenAt, result.Reason = entry.Id, "entry fields were changed"
+		}
+		if result.BrokenAt != 0 {
+			result.Valid = false
+			return false
+		}
+		result.Checked++
+		result.LastHash = entry.Hash
+		prevHash = entry.Hash
+	}
+	return true
+}
+
+// Anchor signs the head of the chain with key
+func Anchor(key []byte, head domain.AuditEntry, now time.Time) domain.AuditAnchor {
+	anchor := domain.AuditAnchor{
+		CreatedAt: now.UTC().Truncate(time.Microsecond),
+		EntryId:   head.Id,
+		Hash:      head.Hash,
+	}
+	anchor.Signature = AnchorSignature(key, anchor)
+	return anchor
+}
+
+// AnchorSignature is the HMAC of the anchored entry id, hash and time
+func AnchorSignature(key []byte, anchor domain.AuditAnchor) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(digest(
+		strconv.FormatUint(uint64(anchor.EntryId), 10),
+		anchor.Hash,
+		anchor.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckAnchors matches anchors, sorted by entry id, against the next entries
+// of the chain. Each anchor must name an entry that is still there with the
+// same hash and, when key is set, carry a valid signature. It returns the
+// anchors that lie past entries, to be checked with the next batch.
+func CheckAnchors(key []byte, entries []domain.AuditEntry, anchors []domain.AuditAnchor, result *domain.AuditVerification) ([]domain.AuditAnchor, bool) {
+	for _, entry := range entries {
+		for len(anchors) > 0 && anchors[0].EntryId <= entry.Id {
+			anchor := anchors[0]
+			switch {
+			case len(key) > 0 && !hmac.Equal([]byte(AnchorSignature(key, anchor)), []byte(anchor.Signature)):
+				result.BrokenAt, result.Reason = anchor.EntryId, "anchor signature is invalid"
+			case anchor.EntryId < entry.Id:
+				result.BrokenAt, result.Reason = anchor.EntryId, "anchored entry is missing"
+			case anchor.Hash != entry.Hash:
+				result.BrokenAt, result.Reason = entry.Id, "entry does not match its anchor"
+			}
+			if result.BrokenAt != 0 {
+				result.Valid = false
+				return anchors, false
+			}
+			result.Anchors++
+			anchors = anchors[1:]
+		}
+	}
+	return anchors, true
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// testChain seals n entries in order, as appendAudit would
+func testChain(n int) []domain.AuditEntry {
+	at := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	var entries []domain.AuditEntry
+	prevHash := ""
+	for i := 1; i <= n; i++ {
+		entry := NewEntry(context.Background(), ActionCreate, i, nil, map[string]int{"appointment_id": i})
+		entry = Seal(entry, prevHash, at.Add(time.Duration(i)*time.Minute))
+		entry.Id = uint(i)
+		entries = append(entries, entry)
+		prevHash = entry.Hash
+	}
+	return entries
+}
+
+func TestVerify(t *testing.T) {
+	entries := testChain(3)
+	result := domain.AuditVerification{Valid: true}
+	if !Verify(entries, "", &result) || result.Checked != 3 || result.LastHash != entries[2].Hash {
+		t.Fatalf("intact chain: %+v", result)
+	}
+
+	entries[1].After = `{"appointment_id":99}`
+	result = domain.AuditVerification{Valid: true}
+	if Verify(entries, "", &result) || result.BrokenAt != 2 {
+		t.Fatalf("changed payload: %+v", result)
+	}
+
+	// A rewritten chain is consistent with itself; only an anchor catches it
+	entries = testChain(3)
+	entries[1].After = `{"appointment_id":99}`
+	entries[1] = Seal(entries[1], entries[0].Hash, entries[1].CreatedAt)
+	entries[2] = Seal(entries[2], entries[1].Hash, entries[2].CreatedAt)
+	result = domain.AuditVerification{Valid: true}
+	if !Verify(entries, "", &result) {
+		t.Fatalf("rewritten chain should verify on its own: %+v", result)
+	}
+}
+
+func TestCheckAnchors(t *testing.T) {
+	key := []byte("anchor-key")
+	at := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	entries := testChain(4)
+
+	rewritten := testChain(4)
+	rewritten[2].After = `{"appointment_id":99}`
+	rewritten[2] = Seal(rewritten[2], rewritten[1].Hash, rewritten[2].CreatedAt)
+	rewritten[3] = Seal(rewritten[3], rewritten[2].Hash, rewritten[3].CreatedAt)
+
+	forged := Anchor([]byte("other-key"), entries[1], at)
+	tests := []struct {
+		name     string
+		key      []byte
+		entries  []domain.AuditEntry
+		anchors  []domain.AuditAnchor
+		brokenAt uint
+		reason   string
+		left     int
+	}{
+		{"intact", key, entries, []domain.AuditAnchor{Anchor(key, entries[1], at), Anchor(key, entries[3], at)}, 0, "", 0},
+		{"anchor past the batch", key, entries[:2], []domain.AuditAnchor{Anchor(key, entries[1], at), Anchor(key, entries[3], at)}, 0, "", 1},
+		{"rewritten chain", key, rewritten, []domain.AuditAnchor{Anchor(key, entries[3], at)}, 4, "entry does not match its anchor", 0},
+		{"removed entry", key, append(entries[:1:1], entries[2:]...), []domain.AuditAnchor{Anchor(key, entries[1], at)}, 2, "anchored entry is missing", 0},
+		{"forged signature", key, entries, []domain.AuditAnchor{forged}, 2, "anchor signature is invalid", 0},
+		{"no key checks hashes only", nil, entries, []domain.AuditAnchor{forged}, 0, "", 0},
+		{"caller anchor", nil, rewritten, []domain.AuditAnchor{{EntryId: 3, Hash: entries[2].Hash}}, 3, "entry does not match its anchor", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := domain.AuditVerification{Valid: true}
+			left, ok := CheckAnchors(tt.key, tt.entries, tt.anchors, &result)
+			if ok != (tt.brokenAt == 0) || result.BrokenAt != tt.brokenAt || result.Reason != tt.reason {
+				t.Fatalf("got ok=%v %+v, want broken at %d: %q", ok, result, tt.brokenAt, tt.reason)
+			}
+			if ok && len(left) != tt.left {
+				t.Errorf("%d anchors left, want %d", len(left), tt.left)
+			}
+		})
+	}
+}
+
+func TestAnchorSignatureCoversEveryField(t *testing.T) {
+	key := []byte("anchor-key")
+	anchor := Anchor(key, testChain(1)[0], time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
+	for name, change := range map[string]func(*domain.AuditAnchor){
+		"entry id": func(a *domain.AuditAnchor) { a.EntryId++ },
+		"hash":     func(a *domain.AuditAnchor) { a.Hash = "00" + a.Hash[2:] },
+		"time":     func(a *domain.AuditAnchor) { a.CreatedAt = a.CreatedAt.Add(time.Second) },
+	} {
+		changed := anchor
+		change(&changed)
+		if AnchorSignature(key, changed) == anchor.Signature {
+			t.Errorf("changing the %s keeps the signature", name)
+		}
+	}
+}
+
+func TestNewReadEntry(t *testing.T) {
+	entry := NewReadEntry(context.Background(), ActionHistoryRead, 0, "p1", map[string]string{"patient_id": "p1"})
+	if entry.PatientId != "p1" || entry.After != `{"patient_id":"p1"}` || entry.ActorId != ActorSystem {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestVerifyAfterErasure(t *testing.T) {
+	entries := testChain(3)
+	entries[1].ActorId, entries[1].RequestId = "p1", "req-1"
+	entries[1] = Seal(entries[1], entries[0].Hash, entries[1].CreatedAt)
+	entries[2] = Seal(entries[2], entries[1].Hash, entries[2].CreatedAt)
+
+	// Changing an id without erasure breaks the chain
+	tampered := append([]domain.AuditEntry(nil), entries...)
+	tampered[1].ActorId = "d1"
+	result := domain.AuditVerification{Valid: true}
+	if Verify(tampered, "", &result) || result.BrokenAt != 2 || result.Reason != "request or actor id was changed" {
+		t.Fatalf("changed actor id: %+v", result)
+	}
+
+	// Erasure clears the payload and request id and pseudonymises the actor, as ErasePatientData does
+	erased := append([]domain.AuditEntry(nil), entries...)
+	erased[1].Before, erased[1].After, erased[1].RequestId = "", "", ""
+	erased[1].ActorId = "erased-7a1c"
+	erased[1].Redacted = true
+	result = domain.AuditVerification{Valid: true}
+	if !Verify(erased, "", &result) || result.Checked != 3 {
+		t.Fatalf("erased entry should still verify: %+v", result)
+	}
+}
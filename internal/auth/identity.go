@@ -0,0 +1,47 @@
+// Package auth authenticates callers from gateway-issued JWTs or mTLS client
+// certificates and enforces per-RPC role policies.
+package auth
+
+import "context"
+
+// Roles a caller can hold
+const (
+	RolePatient   = "patient"
+	RoleDoctor    = "doctor"
+	RoleAdmin     = "admin"
+	RoleFrontDesk = "front-desk"
+	// RoleService is held by other hosp-connect services calling over mTLS
+	RoleService = "service"
+)
+
+// CertificateRoles are the roles a client certificate may name in its OU.
+// Staff tools sign in as front desk this way; admins, doctors and patients
+// must present a token, which carries who they are.
+var CertificateRoles = []string{RoleService, RoleFrontDesk}
+
+// Where an identity was established
+const (
+	SourceJWT  = "jwt"
+	SourceMTLS = "mtls"
+)
+
+// Identity is the authenticated caller. Subject is the patient, doctor or
+// staff id for people and the certificate common name for services.
+type Identity struct {
+	Subject string
+	Role    string
+	Source  string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying id
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the caller set by the interceptor, if any
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
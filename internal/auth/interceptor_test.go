@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+)
+
+const testMethod = "/test.Service/Call"
+
+// call runs req through a's unary interceptor with token as the bearer
+// token, returning the identity the handler saw
+func call(a *Authorizer, method, token string, req interface{}) (Identity, bool, error) {
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+	var (
+		id     Identity
+		hasId  bool
+		called bool
+	)
+	_, err := a.UnaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		id, hasId = FromContext(ctx)
+		return nil, nil
+	})
+	if err == nil && !called {
+		panic("handler was not called")
+	}
+	return id, hasId, err
+}
+
+func testAuthorizer(mode string) *Authorizer {
+	return NewAuthorizer(&Verifier{Keys: TestKeySet()}, map[string]Policy{
+		testMethod: {Roles: []string{RolePatient}, Owner: map[string]func(interface{}) string{
+			RolePatient: func(req interface{}) string { return req.(string) },
+		}},
+	}, mode)
+}
+
+func token(t *testing.T, subject, role string, ttl time.Duration) string {
+	t.Helper()
+	tok, err := SignTestToken(subject, role, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+func TestAuthorizerEnforce(t *testing.T) {
+	a := testAuthorizer(ModeEnforce)
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		req      string
+		wantKind apperr.Kind
+	}{
+		{"own record", testMethod, token(t, "p1", RolePatient, time.Minute), "p1", apperr.KindUnknown},
+		{"admin", testMethod, token(t, "a1", RoleAdmin, time.Minute), "p1", apperr.KindUnknown},
+		{"no token", testMethod, "", "p1", apperr.KindUnauthenticated},
+		{"expired", testMethod, token(t, "p1", RolePatient, -time.Hour), "p1", apperr.KindUnauthenticated},
+		{"forged", testMethod, token(t, "p1", RolePatient, time.Minute) + "x", "p1", apperr.KindUnauthenticated},
+		{"other patient", testMethod, token(t, "p2", RolePatient, time.Minute), "p1", apperr.KindPermissionDenied},
+		{"wrong role", testMethod, token(t, "d1", RoleDoctor, time.Minute), "p1", apperr.KindPermissionDenied},
+		{"no policy", "/test.Service/Unlisted", token(t, "a1", RoleAdmin, time.Minute), "p1", apperr.KindPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := call(a, tt.method, tt.token, tt.req)
+			if tt.wantKind == apperr.KindUnknown {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("got %v, want kind %v", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestAuthorizerEnforcePassesIdentity(t *testing.T) {
+	id, ok, err := call(testAuthorizer(ModeEnforce), testMethod, token(t, "p1", RolePatient, time.Minute), "p1")
+	if err != nil || !ok || id.Subject != "p1" || id.Role != RolePatient || id.Source != SourceJWT {
+		t.Errorf("got %+v %v %v", id, ok, err)
+	}
+}
+
+func TestAuthorizerLogModeLetsRejectedCallsThrough(t *testing.T) {
+	a := testAuthorizer(ModeLog)
+	if _, ok, err := call(a, testMethod, "", "p1"); err != nil || ok {
+		t.Errorf("unauthenticated call: identity %v, err %v", ok, err)
+	}
+	// A caller who authenticated keeps their identity even when the policy rejects them
+	id, ok, err := call(a, testMethod, token(t, "p2", RolePatient, time.Minute), "p1")
+	if err != nil || !ok || id.Subject != "p2" {
+		t.Errorf("denied call: got %+v %v %v", id, ok, err)
+	}
+}
+
+func TestAuthorizerOffSkipsAuthentication(t *testing.T) {
+	a := NewAuthorizer(nil, nil, ModeOff)
+	if _, ok, err := call(a, "/test.Service/Unlisted", "not-a-token", nil); err != nil || ok {
+		t.Errorf("identity %v, err %v", ok, err)
+	}
+}
+
+func TestCertificateRoles(t *testing.T) {
+	a := testAuthorizer(ModeEnforce)
+	for _, tt := range []struct {
+		ou   []string
+		role string
+		ok   bool
+	}{
+		{nil, RoleService, true},
+		{[]string{RoleFrontDesk}, RoleFrontDesk, true},
+		{[]string{RoleAdmin}, "", false},
+		{[]string{RoleDoctor}, "", false},
+		{[]string{"root"}, "", false},
+	} {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: "billing-tool", OrganizationalUnit: tt.ou}}
+		ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		}})
+		id, err := a.authenticate(ctx)
+		if tt.ok && (err != nil || id.Role != tt.role || id.Source != SourceMTLS) {
+			t.Errorf("OU %v: got %+v, %v, want role %q", tt.ou, id, err, tt.role)
+		}
+		if !tt.ok && apperr.KindOf(err) != apperr.KindUnauthenticated {
+			t.Errorf("OU %v: got %+v, %v, want unauthenticated", tt.ou, id, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far exp and nbf may be off between the gateway and us
+const clockSkew = 30 * time.Second
+
+// Claims are the JWT claims the gateway sets
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// audience accepts both the string and the array form of aud
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Verifier checks token signatures against a key set and validates the
+// standard claims
+type Verifier struct {
+	Keys *KeySet
+	// Issuer and Audience are checked when set
+	Issuer   string
+	Audience string
+	Now      func() time.Time
+}
+
+// Verify returns the claims of a valid token
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, errors.New("malformed token")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Claims{}, errors.New("malformed token header")
+	}
+	key, ok := v.Keys.keys[h.Kid]
+	if !ok {
+		return Claims{}, fmt.Errorf("unknown signing key %q", h.Kid)
+	}
+	if h.Alg != key.alg {
+		return Claims{}, fmt.Errorf("key %q does not sign with %s", h.Kid, h.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, errors.New("malformed token signature")
+	}
+	if err := verifySignature(key, parts[0]+"."+parts[1], signature); err != nil {
+		return Claims{}, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, errors.New("malformed token claims")
+	}
+	return claims, v.validate(claims)
+}
+
+func (v *Verifier) validate(c Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if c.ExpiresAt == 0 {
+		return errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return errors.New("token has expired")
+	}
+	if c.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return errors.New("token was issued by an unknown issuer")
+	}
+	if v.Audience != "" && !contains(c.Audience, v.Audience) {
+		return errors.New("token is not meant for this service")
+	}
+	if c.Subject == "" || c.Role == "" {
+		return errors.New("token has no subject or role")
+	}
+	return nil
+}
+
+func verifySignature(key verificationKey, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch key.alg {
+	case AlgRS256:
+		if err := rsa.VerifyPKCS1v15(key.rsa, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+	case AlgHS256:
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return errors.New("unsupported signing algorithm")
+	}
+	return nil
+}
+
+// SignHS256 issues a token signed with a shared secret
+func SignHS256(kid string, secret []byte, claims Claims) (string, error) {
+	h, err := encodeSegment(header{Alg: AlgHS256, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	c, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(h + "." + c))
+	return h + "." + c + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func encodeSegment(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Signing algorithms a key can verify. The algorithm belongs to the key, so a
+// token cannot pick a weaker one than the key was registered with.
+const (
+	AlgRS256 = "RS256"
+	AlgHS256 = "HS256"
+)
+
+type verificationKey struct {
+	alg    string
+	rsa    *rsa.PublicKey
+	secret []byte
+}
+
+// KeySet holds the keys tokens may be signed with, by key id
+type KeySet struct {
+	keys map[string]verificationKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: map[string]verificationKey{}}
+}
+
+// AddRSA registers an RS256 public key
+func (k *KeySet) AddRSA(kid string, key *rsa.PublicKey) {
+	k.keys[kid] = verificationKey{alg: AlgRS256, rsa: key}
+}
+
+// AddHMAC registers an HS256 shared secret
+func (k *KeySet) AddHMAC(kid string, secret []byte) {
+	k.keys[kid] = verificationKey{alg: AlgHS256, secret: secret}
+}
+
+// Merge adds every key of other, replacing keys with the same id
+func (k *KeySet) Merge(other *KeySet) {
+	for kid, key := range other.keys {
+		k.keys[kid] = key
+	}
+}
+
+// Len reports how many keys are registered
+func (k *KeySet) Len() int {
+	return len(k.keys)
+}
+
+// jwks is the JSON Web Key Set document format
+type jwks struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		K   string `json:"k"`
+	} `json:"keys"`
+}
+
+// LoadJWKSFile reads a JSON Web Key Set with RSA and symmetric keys
+func LoadJWKSFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON Web Key Set with RSA and symmetric keys
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc jwks
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+	set := NewKeySet()
+	for _, key := range doc.Keys {
+		if key.Kid == "" {
+			return nil, errors.New("every key in the key set needs a kid")
+		}
+		switch key.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: invalid modulus", key.Kid)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(key.E)
+			if err != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("key %s: invalid exponent", key.Kid)
+			}
+			set.AddRSA(key.Kid, &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			})
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil || len(secret) < 32 {
+				return nil, fmt.Errorf("key %s: secret must be at least 32 bytes", key.Kid)
+			}
+			set.AddHMAC(key.Kid, secret)
+		default:
+			return nil, fmt.Errorf("key %s: unsupported key type %q", key.Kid, key.Kty)
+		}
+	}
+	return set, nil
+}
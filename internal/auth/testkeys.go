@@ -0,0 +1,30 @@
+package auth
+
+import "time"
+
+// TestKeyId is the kid of the test key set
+const TestKeyId = "hosp-connect-test"
+
+// testSecret is published on purpose so tests and local runs can mint tokens
+// offline. Never enable the test key set where real callers can reach the
+// service.
+var testSecret = []byte("hosp-connect-appointment-service-test-signing-key")
+
+// TestKeySet returns a key set that verifies tokens from SignTestToken
+func TestKeySet() *KeySet {
+	set := NewKeySet()
+	set.AddHMAC(TestKeyId, testSecret)
+	return set
+}
+
+// SignTestToken mints a token for subject and role that the test key set
+// accepts for ttl
+func SignTestToken(subject, role string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	return SignHS256(TestKeyId, testSecret, Claims{
+		Subject:   subject,
+		Role:      role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+}
@@ -0,0 +1,43 @@
+// Package cache holds small in-process caches for values that rarely change.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL caches values for a fixed time after they are set
+type TTL[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]entry[V]
+	now     func() time.Time
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func NewTTL[V any](ttl time.Duration) *TTL[V] {
+	return &TTL[V]{ttl: ttl, entries: map[string]entry[V]{}, now: time.Now}
+}
+
+// Get returns the cached value for key if it has not expired
+func (c *TTL[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *TTL[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry[V]{value: value, expires: c.now().Add(c.ttl)}
+}
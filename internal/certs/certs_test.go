@@ -0,0 +1,296 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA signs certificates for one test
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	serial int64
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+// writeCA writes the CA certificate to dir/name and returns its path
+func (ca *testCA) writeCA(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	writePEM(t, path, "CERTIFICATE", ca.cert.Raw)
+	return path
+}
+
+// issue signs a leaf for commonName, valid for dnsName when set, writes the
+// pair to dir/name.crt and dir/name.key and returns the paths and serial
+func (ca *testCA) issue(t *testing.T, dir, name, commonName, dnsName string) (string, string, int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if dnsName != "" {
+		tmpl.DNSNames = []string{dnsName}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return certFile, keyFile, ca.serial
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newReloader(t *testing.T, certFile, keyFile, caFile string) *Reloader {
+	t.Helper()
+	r, err := NewReloader(certFile, keyFile, caFile, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// handshake connects client to server over loopback TCP and returns the
+// state each side ended with
+func handshake(t *testing.T, server, client *tls.Config) (tls.ConnectionState, tls.ConnectionState, error, error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type result struct {
+		state tls.ConnectionState
+		err   error
+	}
+	served := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			served <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		s := tls.Server(conn, server)
+		err = s.Handshake()
+		served <- result{s.ConnectionState(), err}
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	c := tls.Client(conn, client)
+	clientErr := c.Handshake()
+	s := <-served
+	conn.Close()
+	return s.state, c.ConnectionState(), s.err, clientErr
+}
+
+// pki is a CA with a server and a client certificate in a temp dir
+type pki struct {
+	dir                   string
+	ca                    *testCA
+	caFile                string
+	serverCert, serverKey string
+	clientCert, clientKey string
+}
+
+func newPKI(t *testing.T) *pki {
+	p := &pki{dir: t.TempDir(), ca: newTestCA(t, "hosp-connect test CA")}
+	p.caFile = p.ca.writeCA(t, p.dir, "ca.crt")
+	p.serverCert, p.serverKey, _ = p.ca.issue(t, p.dir, "server", "appointment-service", "appointment.local")
+	p.clientCert, p.clientKey, _ = p.ca.issue(t, p.dir, "client", "api-gateway", "")
+	return p
+}
+
+func TestMutualTLSHandshake(t *testing.T) {
+	p := newPKI(t)
+	server := ServerConfig(newReloader(t, p.serverCert, p.serverKey, p.caFile), tls.RequireAndVerifyClientCert)
+	client := ClientConfig(newReloader(t, p.clientCert, p.clientKey, p.caFile), "appointment.local")
+
+	serverState, _, serverErr, clientErr := handshake(t, server, client)
+	if serverErr != nil || clientErr != nil {
+		t.Fatalf("handshake failed: server %v, client %v", serverErr, clientErr)
+	}
+	if len(serverState.VerifiedChains) == 0 || serverState.VerifiedChains[0][0].Subject.CommonName != "api-gateway" {
+		t.Errorf("server should see the verified client certificate, got %v", serverState.VerifiedChains)
+	}
+}
+
+func TestServerRejectsClientFromAnotherCA(t *testing.T) {
+	p := newPKI(t)
+	other := newTestCA(t, "someone else")
+	certFile, keyFile, _ := other.issue(t, p.dir, "stranger", "stranger", "")
+
+	server := ServerConfig(newReloader(t, p.serverCert, p.serverKey, p.caFile), tls.RequireAndVerifyClientCert)
+	client := ClientConfig(newReloader(t, certFile, keyFile, p.caFile), "appointment.local")
+	if _, _, serverErr, _ := handshake(t, server, client); serverErr == nil {
+		t.Fatal("server accepted a client certificate from an unknown CA")
+	}
+}
+
+func TestClientConfigRejectsWrongCA(t *testing.T) {
+	p := newPKI(t)
+	other := newTestCA(t, "someone else")
+	otherCA := other.writeCA(t, p.dir, "other-ca.crt")
+
+	server := ServerConfig(newReloader(t, p.serverCert, p.serverKey, ""), tls.NoClientCert)
+	client := ClientConfig(newReloader(t, "", "", otherCA), "appointment.local")
+	if _, _, _, clientErr := handshake(t, server, client); clientErr == nil {
+		t.Fatal("client trusted a server certificate from an unknown CA")
+	}
+}
+
+func TestClientConfigRejectsWrongHostname(t *testing.T) {
+	p := newPKI(t)
+	server := ServerConfig(newReloader(t, p.serverCert, p.serverKey, ""), tls.NoClientCert)
+
+	client := ClientConfig(newReloader(t, "", "", p.caFile), "payment.local")
+	if _, _, _, clientErr := handshake(t, server, client); clientErr == nil {
+		t.Fatal("client accepted a certificate issued for another host")
+	}
+
+	client = ClientConfig(newReloader(t, "", "", p.caFile), "appointment.local")
+	if _, _, serverErr, clientErr := handshake(t, server, client); serverErr != nil || clientErr != nil {
+		t.Fatalf("handshake with the right hostname failed: server %v, client %v", serverErr, clientErr)
+	}
+}
+
+func TestReloaderPicksUpRotatedCertificate(t *testing.T) {
+	p := newPKI(t)
+	reloader := newReloader(t, p.serverCert, p.serverKey, "")
+	server := ServerConfig(reloader, tls.NoClientCert)
+	client := ClientConfig(newReloader(t, "", "", p.caFile), "appointment.local")
+
+	servedSerial := func() int64 {
+		t.Helper()
+		_, state, serverErr, clientErr := handshake(t, server, client)
+		if serverErr != nil || clientErr != nil {
+			t.Fatalf("handshake failed: server %v, client %v", serverErr, clientErr)
+		}
+		return state.PeerCertificates[0].SerialNumber.Int64()
+	}
+	first := servedSerial()
+
+	// Rotate in place, as a cert manager renewing the files would
+	_, _, rotated := p.ca.issue(t, p.dir, "server", "appointment-service", "appointment.local")
+	touch(t, p.serverCert, p.serverKey)
+	time.Sleep(5 * time.Millisecond)
+	if got := servedSerial(); got != rotated || got == first {
+		t.Fatalf("served serial %d after rotation, want %d", got, rotated)
+	}
+
+	// A broken rotation keeps the last good pair
+	if err := os.WriteFile(p.serverCert, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, p.serverCert)
+	time.Sleep(5 * time.Millisecond)
+	if got := servedSerial(); got != rotated {
+		t.Fatalf("served serial %d after a failed reload, want %d", got, rotated)
+	}
+}
+
+func TestReloaderPicksUpRotatedCA(t *testing.T) {
+	p := newPKI(t)
+	server := ServerConfig(newReloader(t, p.serverCert, p.serverKey, ""), tls.NoClientCert)
+	other := newTestCA(t, "someone else")
+	caFile := other.writeCA(t, p.dir, "trusted.crt")
+	client := ClientConfig(newReloader(t, "", "", caFile), "appointment.local")
+
+	if _, _, _, clientErr := handshake(t, server, client); clientErr == nil {
+		t.Fatal("client trusted a server from a CA it was not given")
+	}
+	// The client config is already in use; the new CA must still be honoured
+	p.ca.writeCA(t, p.dir, "trusted.crt")
+	touch(t, caFile)
+	time.Sleep(5 * time.Millisecond)
+	if _, _, serverErr, clientErr := handshake(t, server, client); serverErr != nil || clientErr != nil {
+		t.Fatalf("handshake after CA rotation failed: server %v, client %v", serverErr, clientErr)
+	}
+}
+
+func TestNewReloaderRequiresPairedFiles(t *testing.T) {
+	p := newPKI(t)
+	if _, err := NewReloader(p.serverCert, "", "", time.Minute); err == nil {
+		t.Error("expected an error for a certificate without a key")
+	}
+	if _, err := NewReloader("", "", filepath.Join(p.dir, "missing.crt"), time.Minute); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+}
+
+// touch moves the modification time of files forward so a rewrite within
+// the file system's timestamp resolution still counts as a change
+func touch(t *testing.T, files ...string) {
+	t.Helper()
+	later := time.Now().Add(time.Duration(len(files)+1) * time.Minute)
+	for _, f := range files {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// ServerConfig serves the reloader's certificate. When the reloader has a CA
+// pool, client certificates are verified against it with clientAuth, so the
+// verified chain is available to the request authenticator.
+func ServerConfig(r *Reloader, clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// A fresh config per handshake picks up a rotated certificate or CA
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert := r.Certificate()
+			if cert == nil {
+				return nil, errors.New("no server certificate configured")
+			}
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				// gRPC requires HTTP/2 to be negotiated
+				NextProtos: []string{"h2"},
+			}
+			if pool := r.Pool(); pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = clientAuth
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// ClientConfig verifies the server against the reloader's CA pool, or the
+// system roots when it has none, and presents the reloader's certificate
+// when the server asks for one
+func ClientConfig(r *Reloader, serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if cert := r.Certificate(); cert != nil {
+				return cert, nil
+			}
+			// No certificate: continue without one and let the server decide
+			return &tls.Certificate{}, nil
+		},
+	}
+	if r.Pool() == nil {
+		return cfg
+	}
+
+	// RootCAs is fixed once the config is in use, so verify against the
+	// current pool by hand. VerifyConnection still runs with the built-in
+	// verification skipped.
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			Roots:         r.Pool(),
+			Intermediates: intermediates,
+			DNSName:       cs.ServerName,
+		})
+		return err
+	}
+	return cfg
+}
@@ -0,0 +1,137 @@
+// Package certs serves TLS certificates and CA pools from files and picks up
+// rotated files without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often the files are checked for changes
+const DefaultReloadInterval = time.Minute
+
+// Reloader holds a key pair and an optional CA pool loaded from files. The
+// files are checked for changes at most once per interval, during handshakes,
+// and a failed reload keeps serving the previous material.
+type Reloader struct {
+	certFile, keyFile, caFile string
+	interval                  time.Duration
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes []time.Time
+	checked  time.Time
+}
+
+// NewReloader loads the files once. certFile and keyFile may both be empty
+// when only a CA pool is needed; caFile may be empty when no pool is needed.
+func NewReloader(certFile, keyFile, caFile string, interval time.Duration) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile, interval: interval}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	var files []string
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (r *Reloader) load() error {
+	var modTimes []time.Time
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load key pair: %w", err)
+		}
+		cert = &pair
+	}
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in %s", r.caFile)
+		}
+	}
+
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	return nil
+}
+
+// refresh reloads the files when the interval has passed and any of them changed
+func (r *Reloader) refresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) < r.interval {
+		return
+	}
+	r.checked = time.Now()
+
+	changed := false
+	for i, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			log.Printf("Failed to check %s for changes: %v", f, err)
+			return
+		}
+		if !info.ModTime().Equal(r.modTimes[i]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		log.Printf("Failed to reload TLS files, keeping the previous ones: %v", err)
+		return
+	}
+	log.Printf("Reloaded TLS files %v", r.files())
+}
+
+// Certificate returns the current key pair
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.refresh()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert
+}
+
+// Pool returns the current CA pool
+func (r *Reloader) Pool() *x509.CertPool {
+	r.refresh()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pool
+}
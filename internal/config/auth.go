@@ -0,0 +1,57 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/handler"
+)
+
+// newAuthorizer builds the request authorizer from the gateway's key set.
+// AUTH_TEST_KEYS=true adds the published test key set for local runs.
+// AUTH_MODE=log or off relaxes enforcement while callers are migrated;
+// enforcing with no signing keys and no client CA would reject every call,
+// so that refuses to start.
+func newAuthorizer() *auth.Authorizer {
+	mode := os.Getenv("AUTH_MODE")
+	switch mode {
+	case "":
+		mode = auth.ModeEnforce
+	case auth.ModeEnforce, auth.ModeLog:
+	case auth.ModeOff:
+		log.Printf("WARNING: AUTH_MODE=off, every caller may call every RPC")
+		return auth.NewAuthorizer(nil, nil, mode)
+	default:
+		log.Fatalf("AUTH_MODE must be enforce, log or off, got %q", mode)
+	}
+
+	keys := auth.NewKeySet()
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		loaded, err := auth.LoadJWKSFile(path)
+		if err != nil {
+			log.Fatalf("Failed to load auth key set: %v", err)
+		}
+		keys.Merge(loaded)
+	}
+	if os.Getenv("AUTH_TEST_KEYS") == "true" {
+		log.Printf("WARNING: accepting tokens signed with the public test key set")
+		keys.Merge(auth.TestKeySet())
+	}
+	if keys.Len() == 0 {
+		if os.Getenv("TLS_CLIENT_CA_FILE") == "" && mode == auth.ModeEnforce {
+			log.Fatalf("AUTH_MODE=enforce needs AUTH_JWKS_FILE or TLS_CLIENT_CA_FILE, otherwise no caller can authenticate")
+		}
+		log.Printf("No token signing keys configured, only mTLS callers can authenticate")
+	}
+	if mode == auth.ModeLog {
+		log.Printf("WARNING: AUTH_MODE=log, calls that fail authorization are logged and allowed")
+	}
+
+	verifier := &auth.Verifier{
+		Keys:     keys,
+		Issuer:   os.Getenv("AUTH_ISSUER"),
+		Audience: os.Getenv("AUTH_AUDIENCE"),
+	}
+	return auth.NewAuthorizer(verifier, handler.Policies(), mode)
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+	// Zone data for images without a system zoneinfo database
+	_ "time/tzdata"
+)
+
+// clinicLocation loads CLINIC_TIMEZONE, the IANA zone such as "Asia/Kolkata"
+// that statistics days and weeks are cut in. It defaults to UTC.
+func clinicLocation() *time.Location {
+	name := os.Getenv("CLINIC_TIMEZONE")
+	if name == "" {
+		return time.UTC
+	}
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		log.Fatalf("Invalid CLINIC_TIMEZONE %q: %v", name, err)
+	}
+	return zone
+}
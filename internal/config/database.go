@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/privacy"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -15,13 +16,55 @@ func InitDatabase() *gorm.DB {
 		log.Fatal("DATABASE_URL environment variable not set")
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("failed to connect with postgres......")
 	}
-	err = db.AutoMigrate(&domain.Appointment{}, &domain.VideoTreatment{}, &domain.Specialization{})
+	err = db.AutoMigrate(&domain.Appointment{}, &domain.VideoTreatment{}, &domain.Specialization{}, &domain.Consultation{}, &domain.Prescription{}, &domain.FollowUp{}, &domain.VideoParticipant{}, &domain.ConsultationPrice{}, &domain.PriceSurcharge{}, &domain.PromoCode{}, &domain.PromoRedemption{}, &domain.AppointmentRating{}, &domain.AppointmentRollup{}, &domain.AuditEntry{}, &domain.AuditAnchor{})
 	if err != nil {
 		log.Fatal(err)
 	}
+	// The audit log is append-only: reject edits, deletes and truncation at the
+	// database. The one allowed edit is erasure: it clears the payload and the
+	// request id of an entry, and moves its actor and patient ids to the
+	// pseudonym. The digests still hold the originals, so the hash does not
+	// change. Anchors are never edited.
+	for _, stmt := range []string{
+		`CREATE OR REPLACE FUNCTION audit_entries_append_only() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP = 'UPDATE' AND NEW.redacted AND NOT OLD.redacted AND NEW.before = '' AND NEW.after = ''
+				AND NEW.request_id = '' AND (NEW.actor_id = OLD.actor_id OR NEW.actor_id LIKE '` + privacy.PseudonymPrefix + `%')
+				AND (NEW.patient_id IS NOT DISTINCT FROM OLD.patient_id OR NEW.patient_id LIKE '` + privacy.PseudonymPrefix + `%')
+				AND (NEW.id, NEW.created_at, NEW.actor_role, NEW.action, NEW.appointment_id,
+					NEW.payload_digest, NEW.identity_digest, NEW.prev_hash, NEW.hash)
+				IS NOT DISTINCT FROM (OLD.id, OLD.created_at, OLD.actor_role, OLD.action, OLD.appointment_id,
+					OLD.payload_digest, OLD.identity_digest, OLD.prev_hash, OLD.hash) THEN
+				RETURN NEW;
+			END IF;
+			RAISE EXCEPTION 'audit_entries is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_entries_append_only ON audit_entries`,
+		`CREATE TRIGGER audit_entries_append_only BEFORE UPDATE OR DELETE ON audit_entries
+		FOR EACH ROW EXECUTE FUNCTION audit_entries_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_entries_no_truncate ON audit_entries`,
+		`CREATE TRIGGER audit_entries_no_truncate BEFORE TRUNCATE ON audit_entries
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_entries_append_only()`,
+		`CREATE OR REPLACE FUNCTION audit_anchors_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_anchors is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_anchors_append_only ON audit_anchors`,
+		`CREATE TRIGGER audit_anchors_append_only BEFORE UPDATE OR DELETE ON audit_anchors
+		FOR EACH ROW EXECUTE FUNCTION audit_anchors_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_anchors_no_truncate ON audit_anchors`,
+		`CREATE TRIGGER audit_anchors_no_truncate BEFORE TRUNCATE ON audit_anchors
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_anchors_append_only()`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatal(err)
+		}
+	}
 	return db
 }
@@ -1,12 +1,22 @@
 package config
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 
 	"github.com/joho/godotenv"
 )
 
+// LocalEnvFile holds developer overrides, such as the fake payers, that must
+// never reach production. It is gitignored and kept out of the image.
+const LocalEnvFile = ".env.local"
+
 func LoadEnv() {
+	// godotenv keeps the first value it reads, so local overrides load first
+	if err := godotenv.Load(LocalEnvFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("Error loading %s file: %v", LocalEnvFile, err)
+	}
 	if err := godotenv.Load(); err != nil {
 		log.Fatal("Error loading .env file")
 	}
@@ -4,16 +4,20 @@ import (
 	"log"
 	"net"
 	"os"
+	"strings"
 
 	appointmentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
 	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
 	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
 	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/handler"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/payer"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/repository"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/service"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/utils"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/logs"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -28,13 +32,15 @@ func GRPCSetup(port string) (net.Listener, *grpc.Server) {
 	logger := logs.NewLogger()
 	db := InitDatabase()
 
-	appointmentRepo := repository.NewAppoinmentRepository(db)
+	clinicZone := clinicLocation()
+	appointmentRepo := repository.NewAppoinmentRepository(db, clinicZone)
 
-	userconn, err := grpc.NewClient(os.Getenv("USER_GRPC_SERVER"), grpc.WithInsecure())
+	clientTimeout := grpc.WithUnaryInterceptor(clientTimeoutInterceptor(envDuration("CLIENT_CALL_TIMEOUT", defaultClientCallTimeout)))
+	userconn, err := grpc.NewClient(os.Getenv("USER_GRPC_SERVER"), clientCredentials("USER_GRPC_SERVER_NAME"), clientTimeout)
 	if err != nil {
 		log.Fatalf("Failed to connect to doctor service: %v", err)
 	}
-	PaymentConn, err := grpc.NewClient(os.Getenv("PAYMENT_GRPC_SERVER"), grpc.WithInsecure())
+	PaymentConn, err := grpc.NewClient(os.Getenv("PAYMENT_GRPC_SERVER"), clientCredentials("PAYMENT_GRPC_SERVER_NAME"), clientTimeout)
 	if err != nil {
 		log.Fatalf("Failed to connect to payment service: %v", err)
 	}
@@ -43,14 +49,52 @@ func GRPCSetup(port string) (net.Listener, *grpc.Server) {
 	paymentClient := paymentpb.NewPaymentServiceClient(PaymentConn)
 	patientClient := patientpb.NewPatientServiceClient(userconn)
 
-	appointmentService := service.NewAppoinmentService(appointmentRepo, doctorClient, paymentClient, patientClient, logger)
+	if os.Getenv("DOCUMENT_SECRET") == "" {
+		log.Printf("WARNING: DOCUMENT_SECRET is not set, visit documents will not be issued")
+	}
+	if os.Getenv("AUDIT_ANCHOR_KEY") == "" {
+		log.Printf("WARNING: AUDIT_ANCHOR_KEY is not set, the audit chain head will be logged but not signed")
+	}
+
+	videoProvider := newVideoProvider()
+
+	payers := payer.NewRegistry()
+	if os.Getenv("PAYER_FAKE_ENABLED") == "true" {
+		log.Printf("WARNING: PAYER_FAKE_ENABLED=true, demo payers cover the listed member ids; never set this in production")
+		// Local runs only, set in .env.local: a demo insurer and sponsor that cover every member id listed in PAYER_FAKE_MEMBERS
+		var policies []payer.FakePolicy
+		for _, member := range strings.Split(os.Getenv("PAYER_FAKE_MEMBERS"), ",") {
+			if member = strings.TrimSpace(member); member != "" {
+				policies = append(policies, payer.FakePolicy{MemberId: member, CoveragePercent: 80})
+			}
+		}
+		payers.Register("demo-insurer", payer.NewFakeProvider(policies...))
+		payers.Register("demo-corporate", payer.NewFakeProvider(policies...))
+	}
+
+	appointmentService := service.NewAppoinmentService(appointmentRepo, doctorClient, paymentClient, patientClient, videoProvider, payers, service.Timeouts{
+		StatsCall: envDuration("STATS_CALL_TIMEOUT", 0),
+		Job:       envDuration("JOB_TIMEOUT", 0),
+	}, clinicZone, logger)
 
 	appointmentHandler := handler.NewAppoinmentClient(appointmentService)
 	go utils.StartCroneSheduler(appointmentService)
 
-	server := grpc.NewServer()
+	authorizer := newAuthorizer()
+	server := grpc.NewServer(
+		serverCredentials(),
+		grpc.ChainUnaryInterceptor(
+			audit.RequestIdInterceptor,
+			handler.ErrorInterceptor,
+			authorizer.UnaryInterceptor,
+			handler.ValidationInterceptor,
+			serverTimeoutInterceptor(envDuration("REQUEST_TIMEOUT", defaultRequestTimeout)),
+		),
+		grpc.ChainStreamInterceptor(handler.StreamErrorInterceptor, authorizer.StreamInterceptor),
+	)
 
 	appointmentpb.RegisterAppointmentServiceServer(server, appointmentHandler)
+	extpb.RegisterAppointmentExtServiceServer(server, appointmentHandler)
 
 	reflection.Register(server)
 
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"crypto/tls"
+	"log"
+	"os"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/certs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// serverCredentials enables TLS when TLS_CERT_FILE and TLS_KEY_FILE are set,
+// and mTLS when TLS_CLIENT_CA_FILE is set too. TLS_CLIENT_AUTH=optional
+// accepts callers without a client certificate, such as a gateway that
+// authenticates with tokens.
+func serverCredentials() grpc.ServerOption {
+	certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	if certFile == "" && keyFile == "" {
+		log.Printf("WARNING: TLS_CERT_FILE is not set, serving gRPC without TLS")
+		return grpc.Creds(insecure.NewCredentials())
+	}
+	reloader, err := certs.NewReloader(certFile, keyFile, os.Getenv("TLS_CLIENT_CA_FILE"), envDuration("TLS_RELOAD_INTERVAL", certs.DefaultReloadInterval))
+	if err != nil {
+		log.Fatalf("Failed to load server TLS files: %v", err)
+	}
+
+	clientAuth := tls.RequireAndVerifyClientCert
+	if os.Getenv("TLS_CLIENT_AUTH") == "optional" {
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+	return grpc.Creds(credentials.NewTLS(certs.ServerConfig(reloader, clientAuth)))
+}
+
+// clientCredentials dials the user and payment services with TLS when
+// GRPC_CLIENT_TLS=true, verifying them against GRPC_CLIENT_CA_FILE or the
+// system roots and presenting GRPC_CLIENT_CERT_FILE for mTLS. serverNameEnv
+// names the variable that overrides the expected server name.
+func clientCredentials(serverNameEnv string) grpc.DialOption {
+	if os.Getenv("GRPC_CLIENT_TLS") != "true" {
+		return grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+	reloader, err := certs.NewReloader(os.Getenv("GRPC_CLIENT_CERT_FILE"), os.Getenv("GRPC_CLIENT_KEY_FILE"), os.Getenv("GRPC_CLIENT_CA_FILE"), envDuration("TLS_RELOAD_INTERVAL", certs.DefaultReloadInterval))
+	if err != nil {
+		log.Fatalf("Failed to load client TLS files: %v", err)
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(certs.ClientConfig(reloader, os.Getenv(serverNameEnv))))
+}
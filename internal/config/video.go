@@ -0,0 +1,29 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/video"
+)
+
+// newVideoProvider picks the video provider named by VIDEO_PROVIDER. Jitsi,
+// the default, needs JITSI_APP_SECRET to sign links, so a missing secret stops
+// the server here instead of failing every room request. VIDEO_PROVIDER=fake
+// issues unsigned test links; it is for local runs only and belongs in
+// .env.local, never in .env, which ships in the image.
+func newVideoProvider() video.Provider {
+	switch provider := os.Getenv("VIDEO_PROVIDER"); provider {
+	case "fake":
+		log.Printf("WARNING: VIDEO_PROVIDER=fake, video links are not signed and do not open a real room")
+		return video.NewFakeProvider()
+	case "", "jitsi":
+		if os.Getenv("JITSI_APP_SECRET") == "" {
+			log.Fatalf("JITSI_APP_SECRET is required to sign Jitsi room links; set it, or set VIDEO_PROVIDER=fake in .env.local for local runs")
+		}
+		return video.NewJitsiProvider(os.Getenv("JITSI_DOMAIN"), os.Getenv("JITSI_APP_ID"), os.Getenv("JITSI_APP_SECRET"))
+	default:
+		log.Fatalf("Unknown VIDEO_PROVIDER %q, expected jitsi or fake", provider)
+		return nil
+	}
+}
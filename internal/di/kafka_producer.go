@@ -27,7 +27,7 @@ func NewKafkaProducer(broker string) (*KafkaProducer, error) {
 	return &KafkaProducer{writer: writer}, nil
 }
 
-func (kp *KafkaProducer) AppointmentEvent(topic string,partition int, event domain.AppointmentEvent) error {
+func (kp *KafkaProducer) AppointmentEvent(ctx context.Context, topic string,partition int, event domain.AppointmentEvent) error {
 	message, err := json.Marshal(event)
 	if err != nil {
 		log.Println("failed to marshal event:", err)
@@ -40,7 +40,7 @@ func (kp *KafkaProducer) AppointmentEvent(topic string,partition int, event doma
 		Partition: partition, 
 	}
 
-	err = kp.writer.WriteMessages(context.Background(), msg)
+	err = kp.writer.WriteMessages(ctx, msg)
 	if err != nil {
 		return fmt.Errorf("failed to produce message: %w", err)
 	}
@@ -49,7 +49,8 @@ func (kp *KafkaProducer) AppointmentEvent(topic string,partition int, event doma
 	return nil
 }
 
-func HandleAppointmentNotification(topic string, appevent domain.AppointmentEvent) error {
+// HandleAppointmentNotification publishes appevent, giving up when ctx is done
+func HandleAppointmentNotification(ctx context.Context, topic string, appevent domain.AppointmentEvent) error {
 	kafkaProducer, err := NewKafkaProducer(os.Getenv("KAFKA_BROKER")) 
 	if err != nil {
 		return fmt.Errorf("failed to create Kafka producer: %w", err)
@@ -63,20 +64,27 @@ func HandleAppointmentNotification(topic string, appevent domain.AppointmentEven
 		AppointmentDate: appevent.AppointmentDate,
 		Type:            appevent.Type,
 		VideoURL:        appevent.VideoURL,
+		Status:          appevent.Status,
 	}
-    if topic == "appointment_topic"{
-		err = kafkaProducer.AppointmentEvent("appointment_topic",0, event)
+    switch topic {
+	case "appointment_topic":
+		err = kafkaProducer.AppointmentEvent(ctx, "appointment_topic",0, event)
 		if err != nil {
 			return fmt.Errorf("failed to produce appointment event: %w", err)
 		}
-	}else{
-		err = kafkaProducer.AppointmentEvent("appointment_topic",1, event)
+	case "completion_topic":
+		err = kafkaProducer.AppointmentEvent(ctx, "appointment_topic",2, event)
+		if err != nil {
+			return fmt.Errorf("failed to produce appointment event: %w", err)
+		}
+	default:
+		err = kafkaProducer.AppointmentEvent(ctx, "appointment_topic",1, event)
 		if err != nil {
 			return fmt.Errorf("failed to produce appointment event: %w", err)
 		}
 	}
 
-	log.Println("Appointment alert event produced successfully to email:", appevent.Email)
+	log.Println("Appointment alert event produced successfully for appointment:", appevent.AppointmentId)
 	return nil
 }
 
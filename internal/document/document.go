@@ -0,0 +1,141 @@
+package document
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+const (
+	KindVisitSummary = "visit_summary"
+	KindPrescription = "prescription"
+
+	FormatPDF  = "pdf"
+	FormatHTML = "html"
+)
+
+// Render builds the requested document. Output only depends on the input so
+// the same visit always renders to the same bytes.
+func Render(doc domain.VisitDocument, kind, format string) ([]byte, string, error) {
+	if kind != KindVisitSummary && kind != KindPrescription {
+		return nil, "", fmt.Errorf("unknown document kind %q", kind)
+	}
+	switch format {
+	case FormatPDF:
+		return renderPDF(buildLines(doc, kind)), "application/pdf", nil
+	case FormatHTML:
+		body, err := renderHTML(doc, kind)
+		if err != nil {
+			return nil, "", err
+		}
+		return body, "text/html; charset=utf-8", nil
+	}
+	return nil, "", errors.New("format must be pdf or html")
+}
+
+// ErrNoSecret is returned when no signing secret is configured. Codes signed
+// with an empty key could be forged by anyone, so none are issued.
+var ErrNoSecret = errors.New("document signing secret is not configured")
+
+// VerificationCode derives a short code that lets the clinic confirm a printed
+// document was issued for this consultation.
+func VerificationCode(secret string, appointmentId int, consultationId uint) (string, error) {
+	if secret == "" {
+		return "", ErrNoSecret
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d:%d", appointmentId, consultationId)
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(mac.Sum(nil))[:12]
+	return code[:4] + "-" + code[4:8] + "-" + code[8:], nil
+}
+
+func title(kind string) string {
+	if kind == KindPrescription {
+		return "Prescription"
+	}
+	return "Visit Summary"
+}
+
+func medicationLine(p domain.Prescription) string {
+	line := fmt.Sprintf("%s %s, %s for %d days", p.Drug, p.Dose, p.Frequency, p.DurationDays)
+	if p.Instructions != "" {
+		line += " (" + p.Instructions + ")"
+	}
+	return line
+}
+
+func vitalsLines(v domain.Vitals) []string {
+	var lines []string
+	if v.TemperatureCelsius != 0 {
+		lines = append(lines, fmt.Sprintf("Temperature: %.1f C", v.TemperatureCelsius))
+	}
+	if v.PulseRate != 0 {
+		lines = append(lines, fmt.Sprintf("Pulse: %d bpm", v.PulseRate))
+	}
+	if v.BloodPressure != "" {
+		lines = append(lines, "Blood pressure: "+v.BloodPressure)
+	}
+	if v.RespiratoryRate != 0 {
+		lines = append(lines, fmt.Sprintf("Respiratory rate: %d /min", v.RespiratoryRate))
+	}
+	if v.OxygenSaturation != 0 {
+		lines = append(lines, fmt.Sprintf("SpO2: %d%%", v.OxygenSaturation))
+	}
+	if v.WeightKg != 0 {
+		lines = append(lines, fmt.Sprintf("Weight: %.1f kg", v.WeightKg))
+	}
+	return lines
+}
+
+type line struct {
+	text string
+	size int
+	bold bool
+}
+
+func buildLines(doc domain.VisitDocument, kind string) []line {
+	lines := []line{
+		{text: "HospConnect " + title(kind), size: 18, bold: true},
+		{text: "", size: 10},
+		{text: "Appointment: #" + fmt.Sprint(doc.AppointmentId), size: 11},
+		{text: "Date: " + doc.AppointmentTime.Format("02 Jan 2006 15:04"), size: 11},
+		{text: "Patient: " + doc.PatientName, size: 11},
+		{text: "Doctor: " + doc.DoctorName, size: 11},
+		{text: "Specialization: " + doc.SpecializationName, size: 11},
+		{text: "", size: 10},
+	}
+	if kind == KindVisitSummary {
+		lines = append(lines, line{text: "Diagnosis", size: 13, bold: true})
+		lines = append(lines, line{text: doc.Diagnosis, size: 11})
+		if doc.Notes != "" {
+			lines = append(lines, line{text: "", size: 10}, line{text: "Notes", size: 13, bold: true})
+			for _, n := range strings.Split(doc.Notes, "\n") {
+				lines = append(lines, line{text: n, size: 11})
+			}
+		}
+		if vitals := vitalsLines(doc.Vitals); len(vitals) > 0 {
+			lines = append(lines, line{text: "", size: 10}, line{text: "Vitals", size: 13, bold: true})
+			for _, v := range vitals {
+				lines = append(lines, line{text: v, size: 11})
+			}
+		}
+		lines = append(lines, line{text: "", size: 10})
+	}
+	lines = append(lines, line{text: "Medications", size: 13, bold: true})
+	if len(doc.Prescriptions) == 0 {
+		lines = append(lines, line{text: "No medications prescribed", size: 11})
+	}
+	for i, p := range doc.Prescriptions {
+		lines = append(lines, line{text: fmt.Sprintf("%d. %s", i+1, medicationLine(p)), size: 11})
+	}
+	lines = append(lines,
+		line{text: "", size: 10},
+		line{text: "Verification code: " + doc.VerificationCode, size: 10, bold: true},
+	)
+	return lines
+}
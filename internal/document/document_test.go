@@ -0,0 +1,96 @@
+package document
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func testDocument(t *testing.T) domain.VisitDocument {
+	code, err := VerificationCode("test-secret", 42, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain.VisitDocument{
+		AppointmentId:      42,
+		PatientName:        "Asha Menon",
+		DoctorName:         "Dr. Ravi Kumar",
+		SpecializationName: "General Medicine",
+		AppointmentTime:    time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC),
+		Type:               "video",
+		Diagnosis:          "Acute viral pharyngitis",
+		Notes:              "Sore throat for three days.\nNo fever at the time of the visit. Advised rest, warm fluids and a review if symptoms persist beyond a week or breathing becomes difficult.",
+		Vitals:             domain.Vitals{TemperatureCelsius: 37.2, PulseRate: 78, BloodPressure: "120/80", OxygenSaturation: 98},
+		Prescriptions: []domain.Prescription{
+			{Drug: "Paracetamol", Dose: "500 mg", Frequency: "three times a day", DurationDays: 3, Instructions: "after food"},
+			{Drug: "Cetirizine", Dose: "10 mg", Frequency: "once at night", DurationDays: 5},
+		},
+		VerificationCode: code,
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	for _, kind := range []string{KindVisitSummary, KindPrescription} {
+		for _, format := range []string{FormatPDF, FormatHTML} {
+			t.Run(kind+"."+format, func(t *testing.T) {
+				got, _, err := Render(testDocument(t), kind, format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				again, _, err := Render(testDocument(t), kind, format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, again) {
+					t.Fatal("rendering the same document twice gave different bytes")
+				}
+
+				golden := filepath.Join("testdata", kind+"."+format+".golden")
+				if *update {
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run go test -update to create it)", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("output differs from %s; run go test -update if the change is intended", golden)
+				}
+			})
+		}
+	}
+}
+
+func TestRenderRejectsUnknownKindAndFormat(t *testing.T) {
+	if _, _, err := Render(testDocument(t), "invoice", FormatPDF); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+	if _, _, err := Render(testDocument(t), KindPrescription, "docx"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestVerificationCode(t *testing.T) {
+	if _, err := VerificationCode("", 42, 7); err != ErrNoSecret {
+		t.Fatalf("empty secret: got %v, want ErrNoSecret", err)
+	}
+	a, _ := VerificationCode("secret-a", 42, 7)
+	b, _ := VerificationCode("secret-b", 42, 7)
+	c, _ := VerificationCode("secret-a", 42, 8)
+	if a == b || a == c {
+		t.Errorf("codes should depend on the secret and the consultation: %s %s %s", a, b, c)
+	}
+	if len(a) != 14 || strings.Count(a, "-") != 2 {
+		t.Errorf("unexpected code format %q", a)
+	}
+}
@@ -0,0 +1,81 @@
+package document
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+var htmlTemplate = template.Must(template.New("document").Funcs(template.FuncMap{
+	"medication": medicationLine,
+	"vitals":     vitalsLines,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} #{{.Doc.AppointmentId}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 40px; color: #222; }
+h1 { font-size: 22px; margin-bottom: 16px; }
+h2 { font-size: 16px; margin-top: 24px; }
+table.details td { padding: 2px 12px 2px 0; }
+.code { margin-top: 32px; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>HospConnect {{.Title}}</h1>
+<table class="details">
+<tr><td>Appointment</td><td>#{{.Doc.AppointmentId}}</td></tr>
+<tr><td>Date</td><td>{{.Doc.AppointmentTime.Format "02 Jan 2006 15:04"}}</td></tr>
+<tr><td>Patient</td><td>{{.Doc.PatientName}}</td></tr>
+<tr><td>Doctor</td><td>{{.Doc.DoctorName}}</td></tr>
+<tr><td>Specialization</td><td>{{.Doc.SpecializationName}}</td></tr>
+</table>
+{{- if .Summary}}
+<h2>Diagnosis</h2>
+<p>{{.Doc.Diagnosis}}</p>
+{{- if .Doc.Notes}}
+<h2>Notes</h2>
+<p>{{.Doc.Notes}}</p>
+{{- end}}
+{{- with vitals .Doc.Vitals}}
+<h2>Vitals</h2>
+<ul>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+<h2>Medications</h2>
+{{- if .Doc.Prescriptions}}
+<ol>
+{{- range .Doc.Prescriptions}}
+<li>{{medication .}}</li>
+{{- end}}
+</ol>
+{{- else}}
+<p>No medications prescribed</p>
+{{- end}}
+<p class="code">Verification code: {{.Doc.VerificationCode}}</p>
+</body>
+</html>
+`))
+
+func renderHTML(doc domain.VisitDocument, kind string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, struct {
+		Title   string
+		Summary bool
+		Doc     domain.VisitDocument
+	}{
+		Title:   title(kind),
+		Summary: kind == KindVisitSummary,
+		Doc:     doc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
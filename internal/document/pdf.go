@@ -0,0 +1,129 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 50
+	maxLineChars = 90
+)
+
+// renderPDF writes a minimal PDF 1.4 file using the standard Helvetica fonts.
+// No timestamps or ids are embedded so the output is reproducible.
+func renderPDF(lines []line) []byte {
+	pages := paginate(wrap(lines))
+
+	// 1 catalog, 2 page tree, 3-4 fonts, then a page and content object per page
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range pages {
+		content := pageContent(page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pageContent(lines []line) string {
+	var b strings.Builder
+	y := pageHeight - margin
+	for _, l := range lines {
+		y -= l.size + 6
+		if l.text == "" {
+			continue
+		}
+		font := "F1"
+		if l.bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&b, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, l.size, margin, y, escapePDF(l.text))
+	}
+	return b.String()
+}
+
+func paginate(lines []line) [][]line {
+	var pages [][]line
+	var current []line
+	y := pageHeight - margin
+	for _, l := range lines {
+		if y-(l.size+6) < margin && len(current) > 0 {
+			pages = append(pages, current)
+			current = nil
+			y = pageHeight - margin
+		}
+		current = append(current, l)
+		y -= l.size + 6
+	}
+	return append(pages, current)
+}
+
+// wrap splits long lines on word boundaries so they stay within the margins
+func wrap(lines []line) []line {
+	var out []line
+	for _, l := range lines {
+		words := strings.Fields(l.text)
+		if len(l.text) <= maxLineChars || len(words) == 0 {
+			out = append(out, l)
+			continue
+		}
+		current := words[0]
+		for _, w := range words[1:] {
+			if len(current)+1+len(w) > maxLineChars {
+				out = append(out, line{text: current, size: l.size, bold: l.bold})
+				current = "  " + w
+				continue
+			}
+			current += " " + w
+		}
+		out = append(out, line{text: current, size: l.size, bold: l.bold})
+	}
+	return out
+}
+
+// escapePDF escapes string delimiters and drops characters the standard fonts cannot show
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
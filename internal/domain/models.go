@@ -8,18 +8,84 @@ import (
 
 type Appointment struct {
 	gorm.Model
-	AppointmentId    int
+	AppointmentId       int
+	PatientId           string `gorm:"index:idx_appointments_patient_time,priority:1"`
+	DoctorId            string `gorm:"index:idx_appointments_doctor_time,priority:1"`
+	SpecializationId    int32  `gorm:"index:idx_appointments_specialization_time,priority:1"`
+	Specialization      Specialization
+	AppointmentTime     time.Time `gorm:"index:idx_appointments_patient_time,priority:2;index:idx_appointments_doctor_time,priority:2;index:idx_appointments_specialization_time,priority:2;index:idx_appointments_status_time,priority:2"`
+	Duration            time.Duration
+	Status              string `gorm:"index:idx_appointments_status_time,priority:1"`
+	PaymentId           string
+	Type                string
+	ParentAppointmentId int `gorm:"index"`
+	Amount              float64
+	Currency            string
+	PayerType           string
+	PayerId             string `gorm:"index"`
+	MemberId            string
+	CoveredAmount       float64
+	ClaimRef            string `gorm:"index"`
+	ClaimStatus         string
+	PaymentMode         string
+	PaymentStatus       string
+	CollectedAmount     float64
+	CollectionMethod    string
+	CollectedBy         string
+	CollectedAt         *time.Time
+	CancelledBy         string
+	CancelReason        string
+}
+
+// AppointmentFilter narrows an appointment search. Zero values are not
+// filtered on; From is inclusive and To exclusive.
+type AppointmentFilter struct {
 	PatientId        string
 	DoctorId         string
 	SpecializationId int32
-	Specialization   Specialization
-	AppointmentTime  time.Time
-	Duration         time.Duration
 	Status           string
-	PaymentId        string
 	Type             string
+	PaymentStatus    string
+	From             time.Time
+	To               time.Time
+	Descending       bool
+	Limit            int
+	Cursor           string
+	// PastOrCancelled keeps only visits already held or cancelled, for patient history
+	PastOrCancelled bool
+}
+
+// PatientVisit is an appointment in a patient's history together with what came out of it
+type PatientVisit struct {
+	Appointment       Appointment
+	DoctorName        string
+	ConsultationId    uint
+	PrescriptionCount int
+	VideoSession      *VideoTreatment
 }
 
+// Kinds of visit an appointment can be booked as
+const (
+	AppointmentTypeVideo    = "video"
+	AppointmentTypeInClinic = "in-clinic"
+)
+
+// How an appointment is paid for
+const (
+	PaymentModePrepaid     = "prepaid"
+	PaymentModePayAtClinic = "pay_at_clinic"
+	PaymentModeFree        = "free"
+)
+
+// Payment state of an appointment
+const (
+	PaymentStatusPending     = "pending"
+	PaymentStatusDue         = "due"
+	PaymentStatusPaid        = "paid"
+	PaymentStatusWaived      = "waived"
+	PaymentStatusNotRequired = "not_required"
+)
+
 type Availability struct {
 	Id         int
 	DoctorId   string
@@ -28,9 +94,26 @@ type Availability struct {
 }
 type VideoTreatment struct {
 	gorm.Model
-	VideoTreatmentId string
-	AppointmentId    int
-	Appointment      Appointment
+	VideoTreatmentId    string
+	AppointmentId       int
+	Appointment         Appointment
+	Status              string
+	StartedAt           *time.Time
+	EndedAt             *time.Time
+	ConsultationSeconds int
+	DoctorJoined        bool
+	PatientJoined       bool
+	DoctorNoShow        bool
+	PatientNoShow       bool
+	PatientNotifiedAt   *time.Time
+}
+type VideoParticipant struct {
+	gorm.Model
+	VideoTreatmentId string `gorm:"index"`
+	ParticipantId    string
+	Role             string
+	JoinedAt         time.Time
+	LeftAt           *time.Time
 }
 type AppointmentEvent struct {
 	AppointmentId   int
@@ -39,19 +122,329 @@ type AppointmentEvent struct {
 	DoctorId        string
 	AppointmentDate string
 	Type            string
+	Status          string
 }
 type Specialization struct {
 	gorm.Model
-	Name        string `gorm:"unique"`
-	Description string
+	Name         string `gorm:"unique"`
+	Description  string
+	Slug         string `gorm:"uniqueIndex:idx_specializations_slug,where:slug <> ''"`
+	Icon         string
+	DisplayOrder int
+	IsActive     bool `gorm:"default:true"`
+	ArchivedAt   *time.Time
 }
 type SpecializationStats struct {
-	Name  string
-	Count int
+	Name      string
+	Count     int
+	Cancelled int
+	NoShows   int
+	Revenue   float64
+}
+
+// Doctors take one-hour bookings within these hours every day
+const (
+	WorkdayStartHour = 8
+	WorkdayEndHour   = 19
+)
+
+// StatsBucket is one day or week of a statistics time series
+type StatsBucket struct {
+	Start        time.Time
+	Appointments int
+	Completed    int
+	Cancelled    int
+	NoShows      int
+	Revenue      float64
+}
+
+// DoctorUtilisation is how many of a doctor's bookable slots were taken
+type DoctorUtilisation struct {
+	DoctorId       string
+	BookedSlots    int
+	AvailableSlots int
+	Utilisation    float64
+}
+
+// StatisticsReport covers [From, To). Rates are fractions of all appointments in the window.
+type StatisticsReport struct {
+	From             time.Time
+	To               time.Time
+	Interval         string
+	Appointments     int
+	Completed        int
+	Cancelled        int
+	NoShows          int
+	CancellationRate float64
+	NoShowRate       float64
+	Revenue          float64
+	Utilisation      float64
+	Series           []StatsBucket
+	Specializations  []SpecializationStats
+	Doctors          []DoctorUtilisation
 }
 type StatisticsData struct {
 	TotalAppointments int
 	TotalRevenue      float64
 	TotalDoctors      int
 	TotalPatients     int
+	CollectedRevenue  float64
+	ExpectedRevenue   float64
+	ClinicCollected   float64
+	ClinicOutstanding float64
+	// Unavailable lists the sections that could not be fetched; their fields are left at zero
+	Unavailable []string
+}
+type Consultation struct {
+	gorm.Model
+	AppointmentId int `gorm:"uniqueIndex"`
+	DoctorId      string
+	PatientId     string
+	Diagnosis     string
+	Notes         string
+	Vitals        Vitals `gorm:"embedded;embeddedPrefix:vital_"`
+	Prescriptions []Prescription
+}
+type Vitals struct {
+	TemperatureCelsius float64
+	PulseRate          int
+	BloodPressure      string
+	RespiratoryRate    int
+	OxygenSaturation   int
+	WeightKg           float64
+}
+type Prescription struct {
+	gorm.Model
+	ConsultationId uint `gorm:"index"`
+	AppointmentId  int
+	PatientId      string `gorm:"index"`
+	DoctorId       string
+	Drug           string
+	Dose           string
+	Frequency      string
+	DurationDays   int
+	Instructions   string
+}
+type VisitDocument struct {
+	AppointmentId      int
+	PatientName        string
+	DoctorName         string
+	SpecializationName string
+	AppointmentTime    time.Time
+	Type               string
+	Diagnosis          string
+	Notes              string
+	Vitals             Vitals
+	Prescriptions      []Prescription
+	VerificationCode   string
+}
+type FollowUp struct {
+	gorm.Model
+	ParentAppointmentId int `gorm:"uniqueIndex"`
+	PatientId           string
+	DoctorId            string
+	SpecializationId    int32
+	WindowStart         time.Time
+	WindowEnd           time.Time
+	FeePercent          int
+	Notes               string
+	Status              string
+	BookedAppointmentId int
+}
+type FollowUpAdherence struct {
+	DoctorId    string
+	Recommended int
+	Booked      int
+	Completed   int
+	Missed      int
+}
+
+// AppointmentRollup counts one day of appointments, in the clinic time zone,
+// for a specialization, doctor and status. It is kept up to date as
+// appointments change and rebuilt every night.
+type AppointmentRollup struct {
+	Id               uint      `gorm:"primaryKey"`
+	Day              time.Time `gorm:"type:date;uniqueIndex:idx_rollup_key,priority:1"`
+	SpecializationId int32     `gorm:"uniqueIndex:idx_rollup_key,priority:2"`
+	DoctorId         string    `gorm:"uniqueIndex:idx_rollup_key,priority:3"`
+	Status           string    `gorm:"uniqueIndex:idx_rollup_key,priority:4"`
+	Appointments     int
+	Amount           float64
+	UpdatedAt        time.Time
+}
+type AppointmentRating struct {
+	gorm.Model
+	AppointmentId int    `gorm:"uniqueIndex"`
+	PatientId     string `gorm:"index"`
+	DoctorId      string `gorm:"index"`
+	Score         int
+	Comment       string
+}
+
+// DoctorPerformance is one doctor's activity over a period. AverageRating is
+// only meaningful when Ratings is above zero.
+type DoctorPerformance struct {
+	DoctorId                string
+	DoctorName              string
+	Booked                  int
+	Completed               int
+	CancelledByDoctor       int
+	CancelledByPatient      int
+	DoctorNoShows           int
+	PatientNoShows          int
+	VideoSessions           int
+	AverageConsultationSecs float64
+	Revenue                 float64
+	Ratings                 int
+	AverageRating           float64
+}
+type ConsultationPrice struct {
+	gorm.Model
+	SpecializationId int32  `gorm:"uniqueIndex:idx_consultation_price_scope"`
+	DoctorId         string `gorm:"uniqueIndex:idx_consultation_price_scope"`
+	VideoFee         float64
+	InClinicFee      float64
+	Currency         string
+}
+type PriceSurcharge struct {
+	gorm.Model
+	SpecializationId int32 `gorm:"index"`
+	Name             string
+	StartHour        int
+	EndHour          int
+	Percent          float64
+}
+type PriceQuote struct {
+	BaseFee   float64
+	Surcharge float64
+	Amount    float64
+	Currency  string
+}
+type BookingOptions struct {
+	PromoCode string
+	PayerType string
+	PayerId   string
+	MemberId  string
+	// PaymentMode defaults to prepaid. Free bookings are recorded as waived
+	// by the signed-in staff member.
+	PaymentMode string
+}
+
+// ClaimExport is the billing team's export of payer claims for a period
+type ClaimExport struct {
+	Claims   []Appointment
+	CSV      []byte
+	Total    float64
+	Currency string
+}
+type PromoCode struct {
+	gorm.Model
+	Code            string `gorm:"uniqueIndex"`
+	Description     string
+	DiscountType    string
+	DiscountValue   float64
+	MaxDiscount     float64
+	ValidFrom       time.Time
+	ValidUntil      time.Time
+	GlobalLimit     int
+	PerPatientLimit int
+	IsActive        bool             `gorm:"default:true"`
+	Specializations []Specialization `gorm:"many2many:promo_code_specializations"`
+}
+type PromoRedemption struct {
+	gorm.Model
+	PromoCodeId    uint   `gorm:"index"`
+	Code           string `gorm:"index"`
+	PatientId      string `gorm:"index"`
+	AppointmentId  int
+	OriginalAmount float64
+	DiscountAmount float64
+	FinalAmount    float64
+}
+
+// AuditEntry is one link of the append-only audit chain. Hash covers the
+// other fields and PrevHash, the hash of the entry before it, so editing or
+// removing an entry breaks every later link.
+type AuditEntry struct {
+	Id            uint      `gorm:"primaryKey"`
+	CreatedAt     time.Time `gorm:"index"`
+	RequestId     string
+	ActorId       string `gorm:"index"`
+	ActorRole     string
+	Action        string `gorm:"index"`
+	AppointmentId int    `gorm:"index"`
+	// PatientId is the patient a read concerns, so erasure can find entries
+	// that carry no appointment; the patient id is also in After
+	PatientId string `gorm:"index"`
+	Before    string
+	After     string
+	// PayloadDigest is the hash of Before and After and IdentityDigest the
+	// hash of RequestId and ActorId. Hash covers the digests rather than the
+	// fields, so erasure can redact the payload and pseudonymise the ids.
+	PayloadDigest  string
+	IdentityDigest string
+	Redacted       bool
+	PrevHash       string
+	Hash           string `gorm:"uniqueIndex"`
+}
+
+// AuditFilter narrows an audit log query; zero fields do not filter.
+// Entries come back in chain order starting after AfterId.
+type AuditFilter struct {
+	AppointmentId int
+	ActorId       string
+	Action        string
+	From          time.Time
+	To            time.Time
+	AfterId       uint
+	Limit         int
+}
+
+// AuditVerification is the result of walking the audit chain. BrokenAt is
+// the first entry whose hash or link does not match.
+type AuditVerification struct {
+	Checked  int
+	Valid    bool
+	BrokenAt uint
+	Reason   string
+	LastHash string
+	// Anchors counts the anchors the chain was checked against
+	Anchors int
+}
+
+// AuditAnchor records the head of the audit chain at a point in time.
+// Signature is an HMAC keyed outside the database, so rewriting the chain
+// and its anchors together is caught by a later verification.
+type AuditAnchor struct {
+	Id        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	EntryId   uint `gorm:"uniqueIndex"`
+	Hash      string
+	Signature string
+}
+
+// PatientData is everything this service holds about one patient
+type PatientData struct {
+	PatientId         string
+	GeneratedAt       time.Time
+	Appointments      []Appointment
+	Consultations     []Consultation
+	FollowUps         []FollowUp
+	VideoSessions     []VideoTreatment
+	VideoParticipants []VideoParticipant
+	Ratings           []AppointmentRating
+	PromoRedemptions  []PromoRedemption
+	AuditEntries      []AuditEntry
+}
+
+// PatientErasure counts the rows an erasure pseudonymised, cleared or deleted
+type PatientErasure struct {
+	Appointments      int
+	Consultations     int
+	Prescriptions     int
+	FollowUps         int
+	VideoParticipants int
+	Ratings           int
+	PromoRedemptions  int
+	AuditEntries      int
 }
@@ -4,16 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/service"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AppoinmentServiceClient struct {
 	pb.UnimplementedAppointmentServiceServer
+	extpb.UnimplementedAppointmentExtServiceServer
 	service service.AppointmentService
 }
 
@@ -27,7 +31,7 @@ func (a *AppoinmentServiceClient) CheckAvailability(ctx context.Context, req *pb
 
 	requestedTime := req.RequestedDateTime.AsTime()
 	fmt.Println("category", req.CategoryId, req.RequestedDateTime)
-	resp, err := a.service.CheckAvailability(req.CategoryId, requestedTime)
+	resp, err := a.service.CheckAvailability(ctx, req.CategoryId, requestedTime)
 	if err != nil {
 		return nil, err
 	}
@@ -47,11 +51,12 @@ func (a *AppoinmentServiceClient) CheckAvailability(ctx context.Context, req *pb
 }
 func (h *AppoinmentServiceClient) CheckAvailabilityByDoctorId(ctx context.Context, req *pb.CheckAvailabilityByDoctorIdRequest) (*pb.CheckAvailabilityByDoctorIdResponse, error) {
 
-	available, err := h.service.CheckAvailabilityByDoctorId(req.DoctorId)
+	available, err := h.service.CheckAvailabilityByDoctorId(ctx, req.DoctorId)
 	if err != nil {
 		return &pb.CheckAvailabilityByDoctorIdResponse{
-			Status: "error",
-		}, nil
+			Status:   "error",
+			DoctorId: req.DoctorId,
+		}, legacy(err)
 	}
 
 	return &pb.CheckAvailabilityByDoctorIdResponse{
@@ -73,13 +78,25 @@ func (h *AppoinmentServiceClient) ConfirmAppointment(ctx context.Context, req *p
 		SpecializationId: req.SpecializationId,
 		Type:             req.Type,
 	}
-	url, message, err := h.service.ConfirmAppointment(appointment)
+	if appointment.Type == "" {
+		appointment.Type = domain.AppointmentTypeInClinic
+	}
+	url, message, err := h.service.ConfirmAppointment(ctx, appointment)
+	if legacySuccess(ctx, err) {
+		return &pb.ConfirmAppointmentResponse{
+			Message:    err.Error(),
+			StatusCode: 200,
+			Status:     "success",
+			PaymentUrl: apperr.PaymentURL(err),
+		}, nil
+	}
 	if err != nil {
 		return &pb.ConfirmAppointmentResponse{
 			Status:     "fail",
 			Message:    err.Error(),
-			StatusCode: 400,
-		}, nil
+			StatusCode: legacyStatusCode(err),
+			PaymentUrl: apperr.PaymentURL(err),
+		}, legacy(err)
 	}
 
 	return &pb.ConfirmAppointmentResponse{
@@ -90,12 +107,12 @@ func (h *AppoinmentServiceClient) ConfirmAppointment(ctx context.Context, req *p
 	}, nil
 }
 func (h *AppoinmentServiceClient) GetUpcomingAppointments(ctx context.Context, req *pb.GetAppointmentsRequest) (*pb.GetAppointmentsResponse, error) {
-	appointments, err := h.service.GetUpcomingAppointments(req.PatientId)
+	appointments, err := h.service.GetUpcomingAppointments(ctx, req.PatientId)
 	if err != nil {
 		return &pb.GetAppointmentsResponse{
-			StatusCode: 400,
+			StatusCode: legacyStatusCode(err),
 			Status:     "fail",
-		}, nil
+		}, legacy(err)
 	}
 	currentTime := time.Now()
 	var upcomingAppointments []*pb.Appointment
@@ -118,13 +135,13 @@ func (h *AppoinmentServiceClient) GetUpcomingAppointments(ctx context.Context, r
 }
 func (d *AppoinmentServiceClient) CreateRoomForVideoTreatment(ctx context.Context, req *pb.VideoRoomRequest) (*pb.VideoRoomResponse, error) {
 
-	room, err := d.service.CreateRoomForVideoTreatment(req.PatientId, req.DoctorId, req.SpecializationId)
+	room, err := d.service.CreateRoomForVideoTreatment(ctx, req.PatientId, req.DoctorId, req.SpecializationId)
 	if err != nil {
 		return &pb.VideoRoomResponse{
-			StatusCode: "400",
+			StatusCode: legacyStatusText(err),
 			Status:     "fail",
 			Message:    err.Error(),
-		}, nil
+		}, legacy(err)
 	}
 
 	return &pb.VideoRoomResponse{
@@ -134,13 +151,13 @@ func (d *AppoinmentServiceClient) CreateRoomForVideoTreatment(ctx context.Contex
 	}, nil
 }
 func (d *AppoinmentServiceClient) GetAppointmentDetails(ctx context.Context, req *pb.GetAppointmentDetailsRequest) (*pb.GetAppointmentDetailsResponse, error) {
-	appointment, err := d.service.GetAppointmentDetails(req.OrderId)
+	appointment, err := d.service.GetAppointmentDetails(ctx, req.OrderId)
 	if err != nil {
 		return &pb.GetAppointmentDetailsResponse{
-			StatusCode: 400,
+			StatusCode: int64(legacyStatusCode(err)),
 			Status:     "fail",
 			Message:    err.Error(),
-		}, nil
+		}, legacy(err)
 	}
 
 	return &pb.GetAppointmentDetailsResponse{
@@ -156,13 +173,13 @@ func (d *AppoinmentServiceClient) GetAppointmentDetails(ctx context.Context, req
 func (a *AppoinmentServiceClient) AddSpecialization(ctx context.Context, req *pb.AddSpecializationRequest) (*pb.StandardResponse, error) {
 
 	log.Println("Adding specialization with name: ", req.Name)
-	resp, err := a.service.AddSpecialization(req.Name, req.Description)
+	resp, err := a.service.AddSpecialization(ctx, req.Name, req.Description)
 	if err != nil {
 		return &pb.StandardResponse{
 			Status:     "fail",
 			Error:      err.Error(),
-			StatusCode: 400,
-		}, nil
+			StatusCode: legacyStatusCode(err),
+		}, legacy(err)
 	}
 	return &pb.StandardResponse{
 		Status:     "success",
@@ -172,10 +189,14 @@ func (a *AppoinmentServiceClient) AddSpecialization(ctx context.Context, req *pb
 }
 func (a *AppoinmentServiceClient) FetchStatisticsDetails(ctx context.Context, req *pb.StatisticsRequest) (*pb.StatisticsResponse, error) {
 
-	special, statics, err := a.service.FetchStatisticsDetails(req.Param)
+	special, statics, err := a.service.FetchStatisticsDetails(ctx, req.Param)
 	if err != nil {
 		return &pb.StatisticsResponse{}, err
 	}
+	// The legacy response cannot say a section is missing, and a zero would read as a real count
+	if len(statics.Unavailable) > 0 {
+		return &pb.StatisticsResponse{}, apperr.Unavailable("statistics are unavailable right now: "+strings.Join(statics.Unavailable, ", "), nil)
+	}
 	fmt.Println("speccc", special)
 	var specializ []*pb.SpecializationStats
 	for _, s := range special {
@@ -195,13 +216,13 @@ func (a *AppoinmentServiceClient) FetchStatisticsDetails(ctx context.Context, re
 }
 func (a *AppoinmentServiceClient) CancelAppointment(ctx context.Context, req *pb.CancelAppointmentRequest) (*pb.CancelAppointmentResponse, error) {
 
-	resp, err := a.service.CancelAppointment(domain.Appointment{AppointmentId: int(req.AppointmentId), PatientId: req.PatientId}, req.Reason)
+	resp, err := a.service.CancelAppointment(ctx, domain.Appointment{AppointmentId: int(req.AppointmentId), PatientId: req.PatientId}, req.Reason)
 	if err != nil {
 		return &pb.CancelAppointmentResponse{
 			Status:     "fail",
-			StatusCode: "400",
-			Message:    resp,
-		}, nil
+			StatusCode: legacyStatusText(err),
+			Message:    err.Error(),
+		}, legacy(err)
 	}
 
 	return &pb.CancelAppointmentResponse{
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/service"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// confirmService fails every booking with err, recording what it was asked to book
+type confirmService struct {
+	service.AppointmentService
+	err    error
+	booked *domain.Appointment
+}
+
+func (s confirmService) ConfirmAppointment(ctx context.Context, appointment domain.Appointment) (string, string, error) {
+	if s.booked != nil {
+		*s.booked = appointment
+	}
+	return "", "", s.err
+}
+
+func confirm(ctx context.Context, err error) (*pb.ConfirmAppointmentResponse, error) {
+	h := NewAppoinmentClient(confirmService{err: err})
+	resp, err := ErrorInterceptor(ctx, &pb.ConfirmAppointmentRequest{}, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return h.ConfirmAppointment(ctx, req.(*pb.ConfirmAppointmentRequest))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ConfirmAppointmentResponse), nil
+}
+
+func TestConfirmAppointmentKeepsLegacySuccessBodies(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+		wantCode   int32
+		wantURL    string
+	}{
+		{"slot taken", apperr.Conflict("No slot available. Suggested next slot: 2:00 PM").WithReason("SLOT_UNAVAILABLE", nil), "success", 200, ""},
+		{"payment pending", apperr.FailedPrecondition("complete the payment").WithReason("PAYMENT_PENDING", nil).WithPaymentURL("https://pay/1"), "success", 200, "https://pay/1"},
+		{"already booked", apperr.Conflict("you have already booked").WithReason("ALREADY_BOOKED_TODAY", nil), "fail", 409, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := confirm(context.Background(), tt.err)
+			if err != nil {
+				t.Fatalf("legacy clients should get a body, got %v", err)
+			}
+			if resp.Status != tt.wantStatus || resp.StatusCode != tt.wantCode || resp.PaymentUrl != tt.wantURL || resp.Message != tt.err.Error() {
+				t.Errorf("unexpected body %+v", resp)
+			}
+		})
+	}
+}
+
+func TestConfirmAppointmentStatusModeReturnsErrors(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ErrorModeHeader, "status"))
+	_, err := confirm(ctx, apperr.Conflict("No slot available").WithReason("SLOT_UNAVAILABLE", nil))
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("got %v, want AlreadyExists", err)
+	}
+}
+
+func TestConfirmAppointmentBooksLegacyEmptyTypeInClinic(t *testing.T) {
+	for reqType, want := range map[string]string{"": domain.AppointmentTypeInClinic, "video": domain.AppointmentTypeVideo} {
+		var booked domain.Appointment
+		h := NewAppoinmentClient(confirmService{booked: &booked})
+		if _, err := h.ConfirmAppointment(context.Background(), &pb.ConfirmAppointmentRequest{Type: reqType}); err != nil {
+			t.Fatal(err)
+		}
+		if booked.Type != want {
+			t.Errorf("type %q was booked as %q, want %q", reqType, booked.Type, want)
+		}
+	}
+}
+
+// followUpService fails every follow-up booking with err
+type followUpService struct {
+	service.AppointmentService
+	err error
+}
+
+func (s followUpService) BookFollowUp(ctx context.Context, followUpId uint, patientId string, reqTime time.Time, appointmentType string) (string, string, error) {
+	return "", "", s.err
+}
+
+func TestExtRPCsReturnStatusErrors(t *testing.T) {
+	h := NewAppoinmentClient(followUpService{err: apperr.FailedPrecondition("follow-up is already booked")})
+	// No error mode header: only the original RPCs keep legacy bodies
+	resp, err := ErrorInterceptor(context.Background(), &extpb.BookFollowUpRequest{}, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return h.BookFollowUp(ctx, req.(*extpb.BookFollowUpRequest))
+	})
+	if status.Code(err) != codes.FailedPrecondition || resp != nil {
+		t.Fatalf("got %v, %v; want a FailedPrecondition status", resp, err)
+	}
+}
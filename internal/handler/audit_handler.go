@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// parseAuditCursor reads the id of the last entry of the previous page; an empty cursor starts from the beginning
+func parseAuditCursor(cursor string) (uint, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(cursor, 10, 64)
+	return uint(id), err
+}
+
+func toAuditEntryPb(e domain.AuditEntry) extpb.AuditEntry {
+	return extpb.AuditEntry{
+		Id:            uint64(e.Id),
+		CreatedAt:     e.CreatedAt,
+		RequestId:     e.RequestId,
+		ActorId:       e.ActorId,
+		ActorRole:     e.ActorRole,
+		Action:        e.Action,
+		AppointmentId: int64(e.AppointmentId),
+		Before:        e.Before,
+		After:         e.After,
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+	}
+}
+func (a *AppoinmentServiceClient) QueryAuditLog(ctx context.Context, req *extpb.AuditLogRequest) (*extpb.AuditLogResponse, error) {
+	afterId, _ := parseAuditCursor(req.Cursor)
+	entries, next, err := a.service.QueryAuditLog(ctx, domain.AuditFilter{
+		AppointmentId: int(req.AppointmentId),
+		ActorId:       req.ActorId,
+		Action:        req.Action,
+		From:          req.From,
+		To:            req.To,
+		AfterId:       afterId,
+		Limit:         int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.AuditLogResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	if next != 0 {
+		resp.NextCursor = strconv.FormatUint(uint64(next), 10)
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, toAuditEntryPb(entry))
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) VerifyAuditLog(ctx context.Context, req *extpb.VerifyAuditLogRequest) (*extpb.VerifyAuditLogResponse, error) {
+	result, err := a.service.VerifyAuditLog(ctx, domain.AuditAnchor{
+		EntryId: uint(req.AnchorEntryId),
+		Hash:    req.AnchorHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.VerifyAuditLogResponse{
+		Status:     "success",
+		StatusCode: 200,
+		Message:    "audit chain is intact",
+		Valid:      result.Valid,
+		Checked:    int64(result.Checked),
+		BrokenAt:   uint64(result.BrokenAt),
+		Reason:     result.Reason,
+		LastHash:   result.LastHash,
+		Anchors:    int64(result.Anchors),
+	}
+	if !result.Valid {
+		resp.Message = "audit chain is broken"
+	}
+	return resp, nil
+}
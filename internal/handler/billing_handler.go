@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (a *AppoinmentServiceClient) ExportClaims(ctx context.Context, req *extpb.ExportClaimsRequest) (*extpb.ExportClaimsResponse, error) {
+	export, err := a.service.ExportClaims(ctx, req.PayerId, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.ExportClaimsResponse{
+		Status:     "success",
+		StatusCode: 200,
+		Csv:        export.CSV,
+		Total:      export.Total,
+		Currency:   export.Currency,
+	}
+	for _, c := range export.Claims {
+		resp.Claims = append(resp.Claims, extpb.Claim{
+			ClaimRef:         c.ClaimRef,
+			PayerType:        c.PayerType,
+			PayerId:          c.PayerId,
+			MemberId:         c.MemberId,
+			AppointmentId:    int64(c.AppointmentId),
+			PatientId:        c.PatientId,
+			DoctorId:         c.DoctorId,
+			SpecializationId: c.SpecializationId,
+			AppointmentTime:  c.AppointmentTime,
+			CoveredAmount:    c.CoveredAmount,
+			PatientAmount:    c.Amount,
+			Currency:         c.Currency,
+			ClaimStatus:      c.ClaimStatus,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (h *AppoinmentServiceClient) CompleteAppointment(ctx context.Context, req *extpb.CompleteAppointmentRequest) (*extpb.StandardResponse, error) {
+	consultation := domain.Consultation{
+		AppointmentId: int(req.AppointmentId),
+		DoctorId:      req.DoctorId,
+		Diagnosis:     req.Diagnosis,
+		Notes:         req.Notes,
+		Vitals: domain.Vitals{
+			TemperatureCelsius: req.Vitals.TemperatureCelsius,
+			PulseRate:          int(req.Vitals.PulseRate),
+			BloodPressure:      req.Vitals.BloodPressure,
+			RespiratoryRate:    int(req.Vitals.RespiratoryRate),
+			OxygenSaturation:   int(req.Vitals.OxygenSaturation),
+			WeightKg:           req.Vitals.WeightKg,
+		},
+	}
+	for _, m := range req.Medications {
+		consultation.Prescriptions = append(consultation.Prescriptions, domain.Prescription{
+			Drug:         m.Drug,
+			Dose:         m.Dose,
+			Frequency:    m.Frequency,
+			DurationDays: int(m.DurationDays),
+			Instructions: m.Instructions,
+		})
+	}
+
+	resp, err := h.service.CompleteAppointment(ctx, consultation)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    resp,
+		StatusCode: 200,
+	}, nil
+}
+func (h *AppoinmentServiceClient) GetPrescriptionHistory(ctx context.Context, req *extpb.GetPrescriptionHistoryRequest) (*extpb.GetPrescriptionHistoryResponse, error) {
+	consultations, err := h.service.GetPrescriptionHistory(ctx, req.PatientId)
+	if err != nil {
+		return nil, err
+	}
+
+	var prescriptions []extpb.PrescriptionRecord
+	for _, c := range consultations {
+		record := extpb.PrescriptionRecord{
+			AppointmentId: int32(c.AppointmentId),
+			DoctorId:      c.DoctorId,
+			Diagnosis:     c.Diagnosis,
+			PrescribedAt:  c.CreatedAt,
+		}
+		for _, p := range c.Prescriptions {
+			record.Medications = append(record.Medications, extpb.Medication{
+				Drug:         p.Drug,
+				Dose:         p.Dose,
+				Frequency:    p.Frequency,
+				DurationDays: int32(p.DurationDays),
+				Instructions: p.Instructions,
+			})
+		}
+		prescriptions = append(prescriptions, record)
+	}
+	return &extpb.GetPrescriptionHistoryResponse{
+		Status:        "success",
+		StatusCode:    200,
+		Prescriptions: prescriptions,
+	}, nil
+}
+func (h *AppoinmentServiceClient) GetVisitDocument(ctx context.Context, req *extpb.GetVisitDocumentRequest) (*extpb.GetVisitDocumentResponse, error) {
+	content, contentType, err := h.service.GetVisitDocument(ctx, int(req.AppointmentId), req.PatientId, req.Kind, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.GetVisitDocumentResponse{
+		Status:      "success",
+		StatusCode:  200,
+		Content:     content,
+		ContentType: contentType,
+		FileName:    fmt.Sprintf("%s-%d.%s", req.Kind, req.AppointmentId, req.Format),
+	}, nil
+}
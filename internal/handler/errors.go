@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrorModeHeader is the metadata key a client sets to "status" to receive
+// failures as gRPC status errors with details instead of a response body
+// with Status "fail". Clients that do not set it keep the legacy bodies.
+const ErrorModeHeader = "x-error-mode"
+
+// legacyError marks a failure whose response body was filled in for clients
+// that read Status and StatusCode from the body
+type legacyError struct {
+	err error
+}
+
+func (e *legacyError) Error() string { return e.err.Error() }
+func (e *legacyError) Unwrap() error { return e.err }
+
+// legacy returns err so that ErrorInterceptor can send either the failure
+// body the handler built or a status error, depending on the client. Only the
+// original AppointmentService RPCs use it; the ext RPCs have no legacy
+// clients and always fail with a status error.
+func legacy(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &legacyError{err: err}
+}
+
+// legacyStatusCode is the StatusCode a failure body carries for err
+func legacyStatusCode(err error) int32 {
+	return int32(apperr.HTTPStatus(err))
+}
+
+// legacyStatusText is legacyStatusCode for bodies that carry it as a string
+func legacyStatusText(err error) string {
+	return strconv.Itoa(apperr.HTTPStatus(err))
+}
+
+// legacySuccess reports whether a legacy client was told about err in a
+// successful response, as a taken slot or an unpaid booking used to be
+func legacySuccess(ctx context.Context, err error) bool {
+	if wantsStatusErrors(ctx) {
+		return false
+	}
+	switch apperr.Reason(err) {
+	case "SLOT_UNAVAILABLE", "PAYMENT_PENDING":
+		return true
+	}
+	return false
+}
+
+// ErrorInterceptor maps handler errors to gRPC codes and status details
+func ErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	var le *legacyError
+	if errors.As(err, &le) {
+		if !wantsStatusErrors(ctx) {
+			return resp, nil
+		}
+		err = le.err
+	}
+	return nil, apperr.Status(err).Err()
+}
+
+// StreamErrorInterceptor maps errors returned by streaming handlers
+func StreamErrorInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, ss); err != nil {
+		return apperr.Status(err).Err()
+	}
+	return nil
+}
+
+func wantsStatusErrors(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, mode := range md.Get(ErrorModeHeader) {
+		if mode == "status" {
+			return true
+		}
+	}
+	return false
+}
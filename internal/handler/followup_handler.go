@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (h *AppoinmentServiceClient) CreateFollowUp(ctx context.Context, req *extpb.CreateFollowUpRequest) (*extpb.CreateFollowUpResponse, error) {
+	followUp, err := h.service.CreateFollowUp(ctx, domain.FollowUp{
+		ParentAppointmentId: int(req.ParentAppointmentId),
+		DoctorId:            req.DoctorId,
+		WindowStart:         req.WindowStart,
+		WindowEnd:           req.WindowEnd,
+		FeePercent:          int(req.FeePercent),
+		Notes:               req.Notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.CreateFollowUpResponse{
+		Status:     "success",
+		Message:    "Follow-up recommended successfully",
+		StatusCode: 200,
+		FollowUpId: uint64(followUp.ID),
+	}, nil
+}
+func (h *AppoinmentServiceClient) BookFollowUp(ctx context.Context, req *extpb.BookFollowUpRequest) (*extpb.BookFollowUpResponse, error) {
+	url, message, err := h.service.BookFollowUp(ctx, uint(req.FollowUpId), req.PatientId, req.ConfirmedDateTime, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.BookFollowUpResponse{
+		Status:     "success",
+		Message:    message,
+		StatusCode: 200,
+		PaymentUrl: url,
+	}, nil
+}
+func (h *AppoinmentServiceClient) GetFollowUpAdherence(ctx context.Context, req *extpb.FollowUpAdherenceRequest) (*extpb.FollowUpAdherenceResponse, error) {
+	adherence, err := h.service.GetFollowUpAdherence(ctx, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var doctors []extpb.DoctorFollowUpAdherence
+	for _, a := range adherence {
+		rate := 0.0
+		if a.Recommended > 0 {
+			rate = float64(a.Completed) / float64(a.Recommended)
+		}
+		doctors = append(doctors, extpb.DoctorFollowUpAdherence{
+			DoctorId:      a.DoctorId,
+			Recommended:   int32(a.Recommended),
+			Booked:        int32(a.Booked),
+			Completed:     int32(a.Completed),
+			Missed:        int32(a.Missed),
+			AdherenceRate: rate,
+		})
+	}
+	return &extpb.FollowUpAdherenceResponse{
+		Status:     "success",
+		StatusCode: 200,
+		Doctors:    doctors,
+	}, nil
+}
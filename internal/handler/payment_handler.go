@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (a *AppoinmentServiceClient) RecordClinicPayment(ctx context.Context, req *extpb.RecordClinicPaymentRequest) (*extpb.RecordClinicPaymentResponse, error) {
+	appointment, err := a.service.RecordClinicPayment(ctx, int(req.AppointmentId), req.Amount, req.Method, req.CollectedBy)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.RecordClinicPaymentResponse{
+		Status:        "success",
+		Message:       "Payment recorded successfully",
+		StatusCode:    200,
+		AppointmentId: int64(appointment.AppointmentId),
+		PaymentStatus: appointment.PaymentStatus,
+		Amount:        appointment.CollectedAmount,
+		Currency:      appointment.Currency,
+	}
+	if appointment.CollectedAt != nil {
+		resp.CollectedAt = *appointment.CollectedAt
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) FetchRevenueStatistics(ctx context.Context, req *extpb.RevenueStatisticsRequest) (*extpb.RevenueStatisticsResponse, error) {
+	stats, err := a.service.FetchRevenueStatistics(ctx, req.Param)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.RevenueStatisticsResponse{
+		Status:            "success",
+		StatusCode:        200,
+		CollectedRevenue:  stats.CollectedRevenue,
+		ExpectedRevenue:   stats.ExpectedRevenue,
+		ClinicCollected:   stats.ClinicCollected,
+		ClinicOutstanding: stats.ClinicOutstanding,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (a *AppoinmentServiceClient) DoctorCancelAppointment(ctx context.Context, req *extpb.DoctorCancelAppointmentRequest) (*extpb.StandardResponse, error) {
+	resp, err := a.service.CancelAppointmentByDoctor(ctx, int(req.AppointmentId), req.DoctorId, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    resp,
+		StatusCode: 200,
+	}, nil
+}
+func (a *AppoinmentServiceClient) RateAppointment(ctx context.Context, req *extpb.RateAppointmentRequest) (*extpb.StandardResponse, error) {
+	_, err := a.service.RateAppointment(ctx, domain.AppointmentRating{
+		AppointmentId: int(req.AppointmentId),
+		PatientId:     req.PatientId,
+		Score:         int(req.Score),
+		Comment:       req.Comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    "Thank you for rating your appointment",
+		StatusCode: 200,
+	}, nil
+}
+func (a *AppoinmentServiceClient) GetDoctorPerformance(ctx context.Context, req *extpb.DoctorPerformanceRequest) (*extpb.DoctorPerformanceResponse, error) {
+	performance, err := a.service.GetDoctorPerformance(ctx, req.DoctorId, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.DoctorPerformanceResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	for _, p := range performance {
+		resp.Doctors = append(resp.Doctors, extpb.DoctorPerformance{
+			DoctorId:                   p.DoctorId,
+			DoctorName:                 p.DoctorName,
+			Booked:                     int32(p.Booked),
+			Completed:                  int32(p.Completed),
+			CancelledByDoctor:          int32(p.CancelledByDoctor),
+			CancelledByPatient:         int32(p.CancelledByPatient),
+			DoctorNoShows:              int32(p.DoctorNoShows),
+			PatientNoShows:             int32(p.PatientNoShows),
+			VideoSessions:              int32(p.VideoSessions),
+			AverageConsultationSeconds: p.AverageConsultationSecs,
+			Revenue:                    p.Revenue,
+			Ratings:                    int32(p.Ratings),
+			AverageRating:              p.AverageRating,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// appointmentServiceName is the name the generated AppointmentService registers under
+const appointmentServiceName = "appointment.AppointmentService"
+
+// Role sets used by the policies below. Admins are allowed everywhere.
+var (
+	anyone     = []string{auth.RolePatient, auth.RoleDoctor, auth.RoleFrontDesk, auth.RoleService}
+	adminOnly  []string
+	frontDesk  = []string{auth.RoleFrontDesk}
+	patients   = []string{auth.RolePatient, auth.RoleFrontDesk}
+	doctors    = []string{auth.RoleDoctor}
+	clinicians = []string{auth.RolePatient, auth.RoleDoctor, auth.RoleFrontDesk}
+)
+
+// Policies returns the authorization policy of every RPC this server exposes
+func Policies() map[string]auth.Policy {
+	core := func(method string) string { return "/" + appointmentServiceName + "/" + method }
+	ext := func(method string) string { return "/" + extpb.ServiceName + "/" + method }
+
+	ownPatient := func(field func(req interface{}) string) map[string]func(interface{}) string {
+		return map[string]func(interface{}) string{auth.RolePatient: field}
+	}
+	ownDoctor := func(field func(req interface{}) string) map[string]func(interface{}) string {
+		return map[string]func(interface{}) string{auth.RoleDoctor: field}
+	}
+
+	return map[string]auth.Policy{
+		// AppointmentService
+		core("CheckAvailability"):           {Roles: anyone},
+		core("CheckAvailabilityByDoctorId"): {Roles: anyone},
+		core("ConfirmAppointment"): {Roles: patients, Owner: ownPatient(func(r interface{}) string {
+			return r.(*pb.ConfirmAppointmentRequest).PatientId
+		})},
+		core("CompletePayment"):       {Roles: []string{auth.RoleService, auth.RoleFrontDesk}},
+		core("GetAppointmentDetails"): {Roles: []string{auth.RoleService, auth.RoleFrontDesk}},
+		core("GetUpcomingAppointments"): {Roles: patients, Owner: ownPatient(func(r interface{}) string {
+			return r.(*pb.GetAppointmentsRequest).PatientId
+		})},
+		core("CreateRoomForVideoTreatment"): {Roles: []string{auth.RolePatient, auth.RoleDoctor}, Owner: map[string]func(interface{}) string{
+			auth.RolePatient: func(r interface{}) string { return r.(*pb.VideoRoomRequest).PatientId },
+			auth.RoleDoctor:  func(r interface{}) string { return r.(*pb.VideoRoomRequest).DoctorId },
+		}},
+		core("FetchStatisticsDetails"): {Roles: adminOnly},
+		core("AddSpecialization"):      {Roles: adminOnly},
+		core("GetTotalAppointment"):    {Roles: adminOnly},
+		core("CancelAppointment"): {Roles: patients, Owner: ownPatient(func(r interface{}) string {
+			return r.(*pb.CancelAppointmentRequest).PatientId
+		})},
+
+		// Consultations and follow-ups
+		ext("CompleteAppointment"): {Roles: doctors, Owner: ownDoctor(func(r interface{}) string {
+			return r.(*extpb.CompleteAppointmentRequest).DoctorId
+		})},
+		// Doctors only see their own patients; that needs the appointments, so the service checks it
+		ext("GetPrescriptionHistory"): {Roles: clinicians, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.GetPrescriptionHistoryRequest).PatientId
+		})},
+		ext("GetVisitDocument"): {Roles: clinicians, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.GetVisitDocumentRequest).PatientId
+		})},
+		ext("CreateFollowUp"): {Roles: doctors, Owner: ownDoctor(func(r interface{}) string {
+			return r.(*extpb.CreateFollowUpRequest).DoctorId
+		})},
+		ext("BookFollowUp"): {Roles: patients, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.BookFollowUpRequest).PatientId
+		})},
+		ext("GetFollowUpAdherence"): {Roles: adminOnly},
+
+		// Video
+		ext("CreateVideoRoom"): {Roles: doctors, Owner: ownDoctor(func(r interface{}) string {
+			return r.(*extpb.CreateVideoRoomRequest).DoctorId
+		})},
+		ext("GetVideoRoom"): {Roles: []string{auth.RolePatient}, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.GetVideoRoomRequest).PatientId
+		})},
+		ext("JoinVideoSession"):  videoSessionPolicy(),
+		ext("LeaveVideoSession"): videoSessionPolicy(),
+		ext("EndVideoSession"):   videoSessionPolicy(),
+
+		// Specializations
+		ext("ListSpecializations"):   {Roles: anyone},
+		ext("GetSpecialization"):     {Roles: anyone},
+		ext("UpdateSpecialization"):  {Roles: adminOnly},
+		ext("ArchiveSpecialization"): {Roles: adminOnly},
+		ext("RestoreSpecialization"): {Roles: adminOnly},
+
+		// Pricing
+		ext("GetPriceQuote"):           {Roles: anyone},
+		ext("SetConsultationPrice"):    {Roles: adminOnly},
+		ext("ListConsultationPrices"):  {Roles: frontDesk},
+		ext("DeleteConsultationPrice"): {Roles: adminOnly},
+		ext("AddPriceSurcharge"):       {Roles: adminOnly},
+		ext("ListPriceSurcharges"):     {Roles: frontDesk},
+		ext("DeletePriceSurcharge"):    {Roles: adminOnly},
+
+		// Booking, promos and payers
+		ext("BookAppointment"): {
+			Roles: patients,
+			Owner: ownPatient(func(r interface{}) string { return r.(*extpb.BookAppointmentRequest).PatientId }),
+			Check: func(id auth.Identity, r interface{}) error {
+				req := r.(*extpb.BookAppointmentRequest)
+				if req.PaymentMode != domain.PaymentModeFree {
+					return nil
+				}
+				if id.Role == auth.RolePatient {
+					return apperr.PermissionDenied("only staff can book a free appointment")
+				}
+				if req.WaivedBy != "" && req.WaivedBy != id.Subject {
+					return apperr.PermissionDenied("staff can only record actions under their own id")
+				}
+				return nil
+			},
+		},
+		ext("CreatePromoCode"):      {Roles: adminOnly},
+		ext("ListPromoCodes"):       {Roles: adminOnly},
+		ext("DeactivatePromoCode"):  {Roles: adminOnly},
+		ext("ListPromoRedemptions"): {Roles: adminOnly},
+		ext("ValidatePromoCode"): {Roles: patients, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.ValidatePromoCodeRequest).PatientId
+		})},
+		ext("ExportClaims"): {Roles: adminOnly},
+
+		// Payments, search and statistics
+		ext("RecordClinicPayment"): {Roles: frontDesk, Check: func(id auth.Identity, r interface{}) error {
+			return staffActsAsSelf(id, r.(*extpb.RecordClinicPaymentRequest).CollectedBy)
+		}},
+		ext("FetchRevenueStatistics"): {Roles: adminOnly},
+		ext("SearchAppointments"): {Roles: clinicians, Owner: map[string]func(interface{}) string{
+			auth.RolePatient: func(r interface{}) string { return r.(*extpb.SearchAppointmentsRequest).PatientId },
+			auth.RoleDoctor:  func(r interface{}) string { return r.(*extpb.SearchAppointmentsRequest).DoctorId },
+		}},
+		ext("GetPatientHistory"): {Roles: clinicians, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.PatientHistoryRequest).PatientId
+		})},
+		ext("GetStatisticsReport"):      {Roles: adminOnly},
+		ext("FetchStatisticsDashboard"): {Roles: adminOnly},
+		ext("ExportReport"):             {Roles: adminOnly},
+
+		// Doctor performance
+		ext("DoctorCancelAppointment"): {Roles: doctors, Owner: ownDoctor(func(r interface{}) string {
+			return r.(*extpb.DoctorCancelAppointmentRequest).DoctorId
+		})},
+		ext("RateAppointment"): {Roles: []string{auth.RolePatient}, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.RateAppointmentRequest).PatientId
+		})},
+		ext("GetDoctorPerformance"): {Roles: doctors, Owner: ownDoctor(func(r interface{}) string {
+			return r.(*extpb.DoctorPerformanceRequest).DoctorId
+		})},
+
+		// Audit log
+		ext("QueryAuditLog"):  {Roles: adminOnly},
+		ext("VerifyAuditLog"): {Roles: adminOnly},
+
+		// Patient data requests
+		ext("ExportPatientData"): {Roles: []string{auth.RolePatient}, Owner: ownPatient(func(r interface{}) string {
+			return r.(*extpb.PatientDataRequest).PatientId
+		})},
+		ext("ErasePatientData"): {Roles: adminOnly},
+
+		// Server reflection, for grpcurl and similar tools
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      {Roles: anyone},
+		"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": {Roles: anyone},
+	}
+}
+
+// videoSessionPolicy lets the patient and the doctor of a room act only as themselves
+func videoSessionPolicy() auth.Policy {
+	participant := func(r interface{}) string { return r.(*extpb.VideoSessionRequest).ParticipantId }
+	return auth.Policy{
+		Roles: []string{auth.RolePatient, auth.RoleDoctor},
+		Owner: map[string]func(interface{}) string{auth.RolePatient: participant, auth.RoleDoctor: participant},
+	}
+}
+
+// staffActsAsSelf requires a staff member to record their own id as the one
+// who waived or collected a payment
+func staffActsAsSelf(id auth.Identity, staffId string) error {
+	if id.Role != auth.RoleAdmin && staffId != id.Subject {
+		return apperr.PermissionDenied("staff can only record actions under their own id")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// authorize runs req through an enforcing authorizer with the real policies
+// as a caller holding subject and role
+func authorize(t *testing.T, method, subject, role string, req interface{}) error {
+	t.Helper()
+	token, err := auth.SignTestToken(subject, role, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := auth.NewAuthorizer(&auth.Verifier{Keys: auth.TestKeySet()}, Policies(), auth.ModeEnforce)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err = a.UnaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func TestPolicies(t *testing.T) {
+	core := func(method string) string { return "/" + appointmentServiceName + "/" + method }
+	ext := func(method string) string { return "/" + extpb.ServiceName + "/" + method }
+	free := func(waivedBy string) *extpb.BookAppointmentRequest {
+		return &extpb.BookAppointmentRequest{PatientId: "p1", PaymentMode: domain.PaymentModeFree, WaivedBy: waivedBy}
+	}
+	payment := func(collectedBy string) *extpb.RecordClinicPaymentRequest {
+		return &extpb.RecordClinicPaymentRequest{AppointmentId: 1, CollectedBy: collectedBy}
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		subject, role string
+		req           interface{}
+		allowed       bool
+	}{
+		// Owner rules
+		{"patient books for self", core("ConfirmAppointment"), "p1", auth.RolePatient, &pb.ConfirmAppointmentRequest{PatientId: "p1"}, true},
+		{"patient books for another", core("ConfirmAppointment"), "p2", auth.RolePatient, &pb.ConfirmAppointmentRequest{PatientId: "p1"}, false},
+		{"front desk books for a patient", core("ConfirmAppointment"), "fd1", auth.RoleFrontDesk, &pb.ConfirmAppointmentRequest{PatientId: "p1"}, true},
+		{"doctor cannot book", core("ConfirmAppointment"), "d1", auth.RoleDoctor, &pb.ConfirmAppointmentRequest{PatientId: "p1"}, false},
+		{"doctor opens own room", ext("CreateVideoRoom"), "d1", auth.RoleDoctor, &extpb.CreateVideoRoomRequest{AppointmentId: 1, DoctorId: "d1"}, true},
+		{"doctor opens another's room", ext("CreateVideoRoom"), "d2", auth.RoleDoctor, &extpb.CreateVideoRoomRequest{AppointmentId: 1, DoctorId: "d1"}, false},
+		{"patient searches own", ext("SearchAppointments"), "p1", auth.RolePatient, &extpb.SearchAppointmentsRequest{PatientId: "p1"}, true},
+		{"patient searches all", ext("SearchAppointments"), "p1", auth.RolePatient, &extpb.SearchAppointmentsRequest{}, false},
+		{"doctor searches own", ext("SearchAppointments"), "d1", auth.RoleDoctor, &extpb.SearchAppointmentsRequest{DoctorId: "d1"}, true},
+		{"participant joins as self", ext("JoinVideoSession"), "p1", auth.RolePatient, &extpb.VideoSessionRequest{RoomId: "r", ParticipantId: "p1"}, true},
+		{"participant joins as another", ext("JoinVideoSession"), "p1", auth.RolePatient, &extpb.VideoSessionRequest{RoomId: "r", ParticipantId: "d1"}, false},
+		{"patient exports own data", ext("ExportPatientData"), "p1", auth.RolePatient, &extpb.PatientDataRequest{PatientId: "p1"}, true},
+		{"front desk cannot export data", ext("ExportPatientData"), "fd1", auth.RoleFrontDesk, &extpb.PatientDataRequest{PatientId: "p1"}, false},
+		{"admin erases data", ext("ErasePatientData"), "a1", auth.RoleAdmin, &extpb.PatientDataRequest{PatientId: "p1"}, true},
+
+		// Free bookings
+		{"patient cannot book free", ext("BookAppointment"), "p1", auth.RolePatient, free(""), false},
+		{"front desk books free", ext("BookAppointment"), "fd1", auth.RoleFrontDesk, free(""), true},
+		{"front desk waives as self", ext("BookAppointment"), "fd1", auth.RoleFrontDesk, free("fd1"), true},
+		{"front desk waives as another", ext("BookAppointment"), "fd1", auth.RoleFrontDesk, free("fd2"), false},
+		{"admin waives as another", ext("BookAppointment"), "a1", auth.RoleAdmin, free("fd2"), false},
+		{"patient books prepaid", ext("BookAppointment"), "p1", auth.RolePatient, &extpb.BookAppointmentRequest{PatientId: "p1", PaymentMode: domain.PaymentModePrepaid}, true},
+
+		// Clinic payments
+		{"front desk collects as self", ext("RecordClinicPayment"), "fd1", auth.RoleFrontDesk, payment("fd1"), true},
+		{"front desk collects as another", ext("RecordClinicPayment"), "fd1", auth.RoleFrontDesk, payment("fd2"), false},
+		{"admin records for staff", ext("RecordClinicPayment"), "a1", auth.RoleAdmin, payment("fd2"), true},
+		{"patient cannot record payment", ext("RecordClinicPayment"), "p1", auth.RolePatient, payment("p1"), false},
+
+		// Deny by default
+		{"admin-only rpc", ext("QueryAuditLog"), "fd1", auth.RoleFrontDesk, &extpb.AuditLogRequest{}, false},
+		{"unknown rpc", ext("DropAllTables"), "a1", auth.RoleAdmin, nil, false},
+		{"unknown role", core("CheckAvailability"), "x1", "janitor", &pb.GetAvailabilityRequest{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorize(t, tt.method, tt.subject, tt.role, tt.req)
+			if tt.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tt.allowed && apperr.KindOf(err) != apperr.KindPermissionDenied {
+				t.Fatalf("got %v, want permission denied", err)
+			}
+		})
+	}
+}
+
+func TestEveryRPCHasAPolicy(t *testing.T) {
+	server := grpc.NewServer()
+	h := NewAppoinmentClient(nil)
+	pb.RegisterAppointmentServiceServer(server, h)
+	extpb.RegisterAppointmentExtServiceServer(server, h)
+
+	policies := Policies()
+	for service, info := range server.GetServiceInfo() {
+		for _, method := range info.Methods {
+			if _, ok := policies["/"+service+"/"+method.Name]; !ok {
+				t.Errorf("/%s/%s has no policy and is denied to everyone", service, method.Name)
+			}
+		}
+	}
+}
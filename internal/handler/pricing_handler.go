@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func toConsultationPricePb(p domain.ConsultationPrice) extpb.ConsultationPrice {
+	return extpb.ConsultationPrice{
+		Id:               uint64(p.ID),
+		SpecializationId: p.SpecializationId,
+		DoctorId:         p.DoctorId,
+		VideoFee:         p.VideoFee,
+		InClinicFee:      p.InClinicFee,
+		Currency:         p.Currency,
+	}
+}
+func toPriceSurchargePb(s domain.PriceSurcharge) extpb.PriceSurcharge {
+	return extpb.PriceSurcharge{
+		Id:               uint64(s.ID),
+		SpecializationId: s.SpecializationId,
+		Name:             s.Name,
+		StartHour:        int32(s.StartHour),
+		EndHour:          int32(s.EndHour),
+		Percent:          s.Percent,
+	}
+}
+func (a *AppoinmentServiceClient) GetPriceQuote(ctx context.Context, req *extpb.GetPriceQuoteRequest) (*extpb.GetPriceQuoteResponse, error) {
+	quote, err := a.service.GetPriceQuote(ctx, req.SpecializationId, req.DoctorId, req.Type, req.ConfirmedDateTime)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.GetPriceQuoteResponse{
+		Status:     "success",
+		StatusCode: 200,
+		BaseFee:    quote.BaseFee,
+		Surcharge:  quote.Surcharge,
+		Amount:     quote.Amount,
+		Currency:   quote.Currency,
+	}, nil
+}
+func (a *AppoinmentServiceClient) SetConsultationPrice(ctx context.Context, req *extpb.ConsultationPrice) (*extpb.ConsultationPriceResponse, error) {
+	price, err := a.service.SetConsultationPrice(ctx, domain.ConsultationPrice{
+		SpecializationId: req.SpecializationId,
+		DoctorId:         req.DoctorId,
+		VideoFee:         req.VideoFee,
+		InClinicFee:      req.InClinicFee,
+		Currency:         req.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := toConsultationPricePb(price)
+	return &extpb.ConsultationPriceResponse{
+		Status:     "success",
+		Message:    "Consultation price saved successfully",
+		StatusCode: 200,
+		Price:      &p,
+	}, nil
+}
+func (a *AppoinmentServiceClient) ListConsultationPrices(ctx context.Context, req *extpb.ListPricingRequest) (*extpb.ListConsultationPricesResponse, error) {
+	prices, err := a.service.ListConsultationPrices(ctx, req.SpecializationId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.ListConsultationPricesResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	for _, p := range prices {
+		resp.Prices = append(resp.Prices, toConsultationPricePb(p))
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) DeleteConsultationPrice(ctx context.Context, req *extpb.DeletePricingRequest) (*extpb.StandardResponse, error) {
+	if err := a.service.DeleteConsultationPrice(ctx, uint(req.Id)); err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    "Consultation price deleted successfully",
+		StatusCode: 200,
+	}, nil
+}
+func (a *AppoinmentServiceClient) AddPriceSurcharge(ctx context.Context, req *extpb.PriceSurcharge) (*extpb.PriceSurchargeResponse, error) {
+	surcharge, err := a.service.AddPriceSurcharge(ctx, domain.PriceSurcharge{
+		SpecializationId: req.SpecializationId,
+		Name:             req.Name,
+		StartHour:        int(req.StartHour),
+		EndHour:          int(req.EndHour),
+		Percent:          req.Percent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := toPriceSurchargePb(surcharge)
+	return &extpb.PriceSurchargeResponse{
+		Status:     "success",
+		Message:    "Price surcharge added successfully",
+		StatusCode: 200,
+		Surcharge:  &s,
+	}, nil
+}
+func (a *AppoinmentServiceClient) ListPriceSurcharges(ctx context.Context, req *extpb.ListPricingRequest) (*extpb.ListPriceSurchargesResponse, error) {
+	surcharges, err := a.service.ListPriceSurcharges(ctx, req.SpecializationId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.ListPriceSurchargesResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	for _, s := range surcharges {
+		resp.Surcharges = append(resp.Surcharges, toPriceSurchargePb(s))
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) DeletePriceSurcharge(ctx context.Context, req *extpb.DeletePricingRequest) (*extpb.StandardResponse, error) {
+	if err := a.service.DeletePriceSurcharge(ctx, uint(req.Id)); err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    "Price surcharge deleted successfully",
+		StatusCode: 200,
+	}, nil
+}
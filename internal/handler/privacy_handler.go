@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/privacy"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (h *AppoinmentServiceClient) ExportPatientData(ctx context.Context, req *extpb.PatientDataRequest) (*extpb.PatientDataResponse, error) {
+	content, contentType, err := h.service.ExportPatientData(ctx, req.PatientId)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.PatientDataResponse{
+		Status:      "success",
+		StatusCode:  200,
+		Content:     content,
+		ContentType: contentType,
+		FileName:    privacy.FileName(req.PatientId, time.Now()),
+	}, nil
+}
+func (h *AppoinmentServiceClient) ErasePatientData(ctx context.Context, req *extpb.PatientDataRequest) (*extpb.ErasePatientDataResponse, error) {
+	erasure, err := h.service.ErasePatientData(ctx, req.PatientId)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.ErasePatientDataResponse{
+		Status:            "success",
+		StatusCode:        200,
+		Message:           "patient data erased",
+		Appointments:      int32(erasure.Appointments),
+		Consultations:     int32(erasure.Consultations),
+		Prescriptions:     int32(erasure.Prescriptions),
+		FollowUps:         int32(erasure.FollowUps),
+		VideoParticipants: int32(erasure.VideoParticipants),
+		Ratings:           int32(erasure.Ratings),
+		PromoRedemptions:  int32(erasure.PromoRedemptions),
+		AuditEntries:      int32(erasure.AuditEntries),
+	}, nil
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+	"gorm.io/gorm"
+)
+
+func toPromoCodePb(p domain.PromoCode) extpb.PromoCode {
+	promo := extpb.PromoCode{
+		Id:              uint64(p.ID),
+		Code:            p.Code,
+		Description:     p.Description,
+		DiscountType:    p.DiscountType,
+		DiscountValue:   p.DiscountValue,
+		MaxDiscount:     p.MaxDiscount,
+		ValidFrom:       p.ValidFrom,
+		ValidUntil:      p.ValidUntil,
+		GlobalLimit:     int32(p.GlobalLimit),
+		PerPatientLimit: int32(p.PerPatientLimit),
+		IsActive:        p.IsActive,
+	}
+	for _, s := range p.Specializations {
+		promo.SpecializationIds = append(promo.SpecializationIds, int32(s.ID))
+	}
+	return promo
+}
+func (h *AppoinmentServiceClient) BookAppointment(ctx context.Context, req *extpb.BookAppointmentRequest) (*extpb.BookAppointmentResponse, error) {
+	appointment := domain.Appointment{
+		DoctorId:         req.DoctorId,
+		PatientId:        req.PatientId,
+		AppointmentTime:  req.ConfirmedDateTime,
+		SpecializationId: req.SpecializationId,
+		Type:             req.Type,
+	}
+	url, message, err := h.service.BookAppointment(ctx, appointment, domain.BookingOptions{
+		PromoCode:   req.PromoCode,
+		PayerType:   req.PayerType,
+		PayerId:     req.PayerId,
+		MemberId:    req.MemberId,
+		PaymentMode: req.PaymentMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.BookAppointmentResponse{
+		Status:     "success",
+		Message:    message,
+		StatusCode: 200,
+		PaymentUrl: url,
+	}, nil
+}
+func (a *AppoinmentServiceClient) CreatePromoCode(ctx context.Context, req *extpb.PromoCode) (*extpb.PromoCodeResponse, error) {
+	promo := domain.PromoCode{
+		Code:            req.Code,
+		Description:     req.Description,
+		DiscountType:    req.DiscountType,
+		DiscountValue:   req.DiscountValue,
+		MaxDiscount:     req.MaxDiscount,
+		ValidFrom:       req.ValidFrom,
+		ValidUntil:      req.ValidUntil,
+		GlobalLimit:     int(req.GlobalLimit),
+		PerPatientLimit: int(req.PerPatientLimit),
+	}
+	for _, id := range req.SpecializationIds {
+		promo.Specializations = append(promo.Specializations, domain.Specialization{Model: gorm.Model{ID: uint(id)}})
+	}
+	saved, err := a.service.CreatePromoCode(ctx, promo)
+	if err != nil {
+		return nil, err
+	}
+	p := toPromoCodePb(saved)
+	return &extpb.PromoCodeResponse{
+		Status:     "success",
+		Message:    "Promo code created successfully",
+		StatusCode: 200,
+		Promo:      &p,
+	}, nil
+}
+func (a *AppoinmentServiceClient) ListPromoCodes(ctx context.Context, req *extpb.ListPromoCodesRequest) (*extpb.ListPromoCodesResponse, error) {
+	promos, err := a.service.ListPromoCodes(ctx, req.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.ListPromoCodesResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	for _, p := range promos {
+		resp.Promos = append(resp.Promos, toPromoCodePb(p))
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) DeactivatePromoCode(ctx context.Context, req *extpb.PromoCodeRequest) (*extpb.StandardResponse, error) {
+	if err := a.service.DeactivatePromoCode(ctx, req.Code); err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    "Promo code deactivated successfully",
+		StatusCode: 200,
+	}, nil
+}
+func (a *AppoinmentServiceClient) ValidatePromoCode(ctx context.Context, req *extpb.ValidatePromoCodeRequest) (*extpb.ValidatePromoCodeResponse, error) {
+	redemption, err := a.service.PreviewPromoCode(ctx, req.Code, domain.Appointment{
+		DoctorId:         req.DoctorId,
+		PatientId:        req.PatientId,
+		AppointmentTime:  req.ConfirmedDateTime,
+		SpecializationId: req.SpecializationId,
+		Type:             req.Type,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.ValidatePromoCodeResponse{
+		Status:         "success",
+		StatusCode:     200,
+		OriginalAmount: redemption.OriginalAmount,
+		DiscountAmount: redemption.DiscountAmount,
+		FinalAmount:    redemption.FinalAmount,
+	}, nil
+}
+func (a *AppoinmentServiceClient) ListPromoRedemptions(ctx context.Context, req *extpb.ListPromoRedemptionsRequest) (*extpb.ListPromoRedemptionsResponse, error) {
+	redemptions, err := a.service.GetPromoRedemptions(ctx, req.Code, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.ListPromoRedemptionsResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	for _, r := range redemptions {
+		resp.Redemptions = append(resp.Redemptions, extpb.PromoRedemption{
+			Id:             uint64(r.ID),
+			Code:           r.Code,
+			PatientId:      r.PatientId,
+			AppointmentId:  int64(r.AppointmentId),
+			OriginalAmount: r.OriginalAmount,
+			DiscountAmount: r.DiscountAmount,
+			FinalAmount:    r.FinalAmount,
+			RedeemedAt:     r.CreatedAt,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/report"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/service"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// reportChunkSize is how much of the file each streamed message carries
+const reportChunkSize = 64 * 1024
+
+// chunkWriter buffers report output and sends it over the stream in fixed-size chunks
+type chunkWriter struct {
+	stream      extpb.AppointmentExtService_ExportReportServer
+	buf         []byte
+	fileName    string
+	contentType string
+	sent        bool
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	for len(c.buf) >= reportChunkSize {
+		if err := c.send(c.buf[:reportChunkSize]); err != nil {
+			return 0, err
+		}
+		c.buf = c.buf[reportChunkSize:]
+	}
+	return len(p), nil
+}
+
+func (c *chunkWriter) Flush() error {
+	if len(c.buf) == 0 && c.sent {
+		return nil
+	}
+	err := c.send(c.buf)
+	c.buf = nil
+	return err
+}
+
+func (c *chunkWriter) send(data []byte) error {
+	chunk := &extpb.ReportChunk{Data: append([]byte(nil), data...)}
+	if !c.sent {
+		chunk.FileName = c.fileName
+		chunk.ContentType = c.contentType
+		c.sent = true
+	}
+	return c.stream.Send(chunk)
+}
+
+func (a *AppoinmentServiceClient) ExportReport(req *extpb.ExportReportRequest, stream extpb.AppointmentExtService_ExportReportServer) error {
+	if err := validateRequest(req, time.Now()); err != nil {
+		return err
+	}
+	w := &chunkWriter{
+		stream:      stream,
+		fileName:    service.ReportFileName(req.Report, req.Format, req.From, req.To),
+		contentType: report.ContentType(req.Format),
+	}
+	if err := a.service.ExportReport(stream.Context(), req.Report, req.Format, req.From, req.To, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func toAppointmentSummaryPb(a domain.Appointment) extpb.AppointmentSummary {
+	return extpb.AppointmentSummary{
+		AppointmentId:    int64(a.AppointmentId),
+		PatientId:        a.PatientId,
+		DoctorId:         a.DoctorId,
+		SpecializationId: a.SpecializationId,
+		AppointmentTime:  a.AppointmentTime,
+		Type:             a.Type,
+		Status:           a.Status,
+		PaymentMode:      a.PaymentMode,
+		PaymentStatus:    a.PaymentStatus,
+		Amount:           a.Amount,
+		Currency:         a.Currency,
+	}
+}
+func (a *AppoinmentServiceClient) SearchAppointments(ctx context.Context, req *extpb.SearchAppointmentsRequest) (*extpb.SearchAppointmentsResponse, error) {
+	appointments, next, err := a.service.SearchAppointments(ctx, domain.AppointmentFilter{
+		PatientId:        req.PatientId,
+		DoctorId:         req.DoctorId,
+		SpecializationId: req.SpecializationId,
+		Status:           req.Status,
+		Type:             req.Type,
+		PaymentStatus:    req.PaymentStatus,
+		From:             req.From,
+		To:               req.To,
+		Descending:       strings.EqualFold(req.SortOrder, "desc"),
+		Limit:            int(req.Limit),
+		Cursor:           req.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.SearchAppointmentsResponse{
+		Status:     "success",
+		StatusCode: 200,
+		NextCursor: next,
+	}
+	for _, appointment := range appointments {
+		resp.Appointments = append(resp.Appointments, toAppointmentSummaryPb(appointment))
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) GetPatientHistory(ctx context.Context, req *extpb.PatientHistoryRequest) (*extpb.PatientHistoryResponse, error) {
+	visits, next, err := a.service.GetPatientHistory(ctx, domain.AppointmentFilter{
+		PatientId: req.PatientId,
+		From:      req.From,
+		To:        req.To,
+		Limit:     int(req.Limit),
+		Cursor:    req.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.PatientHistoryResponse{
+		Status:     "success",
+		StatusCode: 200,
+		NextCursor: next,
+	}
+	for _, v := range visits {
+		visit := extpb.PatientVisit{
+			Appointment:       toAppointmentSummaryPb(v.Appointment),
+			DoctorName:        v.DoctorName,
+			ConsultationId:    uint64(v.ConsultationId),
+			HasPrescription:   v.PrescriptionCount > 0,
+			PrescriptionCount: int32(v.PrescriptionCount),
+		}
+		if v.VideoSession != nil {
+			visit.VideoRoomId = v.VideoSession.VideoTreatmentId
+			visit.VideoStatus = v.VideoSession.Status
+			visit.ConsultationSeconds = int32(v.VideoSession.ConsultationSeconds)
+		}
+		resp.Visits = append(resp.Visits, visit)
+	}
+	return resp, nil
+}
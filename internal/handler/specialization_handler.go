@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+	"gorm.io/gorm"
+)
+
+func toSpecializationPb(s domain.Specialization) extpb.Specialization {
+	return extpb.Specialization{
+		Id:           uint64(s.ID),
+		Name:         s.Name,
+		Description:  s.Description,
+		Slug:         s.Slug,
+		Icon:         s.Icon,
+		DisplayOrder: int32(s.DisplayOrder),
+		IsActive:     s.IsActive,
+		ArchivedAt:   s.ArchivedAt,
+	}
+}
+func (a *AppoinmentServiceClient) ListSpecializations(ctx context.Context, req *extpb.ListSpecializationsRequest) (*extpb.ListSpecializationsResponse, error) {
+	specializations, err := a.service.ListSpecializations(ctx, req.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.ListSpecializationsResponse{
+		Status:     "success",
+		StatusCode: 200,
+	}
+	for _, s := range specializations {
+		resp.Specializations = append(resp.Specializations, toSpecializationPb(s))
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) GetSpecialization(ctx context.Context, req *extpb.GetSpecializationRequest) (*extpb.SpecializationResponse, error) {
+	specialization, err := a.service.GetSpecialization(ctx, uint(req.Id), req.Slug)
+	if err != nil {
+		return nil, err
+	}
+	s := toSpecializationPb(specialization)
+	return &extpb.SpecializationResponse{
+		Status:         "success",
+		StatusCode:     200,
+		Specialization: &s,
+	}, nil
+}
+func (a *AppoinmentServiceClient) UpdateSpecialization(ctx context.Context, req *extpb.UpdateSpecializationRequest) (*extpb.SpecializationResponse, error) {
+	specialization, err := a.service.UpdateSpecialization(ctx, domain.Specialization{
+		Model:        gorm.Model{ID: uint(req.Id)},
+		Name:         req.Name,
+		Description:  req.Description,
+		Slug:         req.Slug,
+		Icon:         req.Icon,
+		DisplayOrder: int(req.DisplayOrder),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := toSpecializationPb(specialization)
+	return &extpb.SpecializationResponse{
+		Status:         "success",
+		Message:        "Specialization updated successfully",
+		StatusCode:     200,
+		Specialization: &s,
+	}, nil
+}
+func (a *AppoinmentServiceClient) ArchiveSpecialization(ctx context.Context, req *extpb.SpecializationIdRequest) (*extpb.StandardResponse, error) {
+	if err := a.service.ArchiveSpecialization(ctx, uint(req.Id)); err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    "Specialization archived successfully",
+		StatusCode: 200,
+	}, nil
+}
+func (a *AppoinmentServiceClient) RestoreSpecialization(ctx context.Context, req *extpb.SpecializationIdRequest) (*extpb.StandardResponse, error) {
+	if err := a.service.RestoreSpecialization(ctx, uint(req.Id)); err != nil {
+		return nil, err
+	}
+	return &extpb.StandardResponse{
+		Status:     "success",
+		Message:    "Specialization restored successfully",
+		StatusCode: 200,
+	}, nil
+}
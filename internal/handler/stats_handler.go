@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func (a *AppoinmentServiceClient) GetStatisticsReport(ctx context.Context, req *extpb.StatisticsReportRequest) (*extpb.StatisticsReportResponse, error) {
+	report, err := a.service.GetStatisticsReport(ctx, req.From, req.To, req.Interval)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.StatisticsReportResponse{
+		Status:           "success",
+		StatusCode:       200,
+		From:             report.From,
+		To:               report.To,
+		Interval:         report.Interval,
+		Appointments:     int32(report.Appointments),
+		Completed:        int32(report.Completed),
+		Cancelled:        int32(report.Cancelled),
+		NoShows:          int32(report.NoShows),
+		CancellationRate: report.CancellationRate,
+		NoShowRate:       report.NoShowRate,
+		Revenue:          report.Revenue,
+		Utilisation:      report.Utilisation,
+	}
+	for _, b := range report.Series {
+		resp.Series = append(resp.Series, extpb.StatsBucket{
+			Start:        b.Start,
+			Appointments: int32(b.Appointments),
+			Completed:    int32(b.Completed),
+			Cancelled:    int32(b.Cancelled),
+			NoShows:      int32(b.NoShows),
+			Revenue:      b.Revenue,
+		})
+	}
+	for _, s := range report.Specializations {
+		resp.Specializations = append(resp.Specializations, extpb.SpecializationReport{
+			SpecializationName: s.Name,
+			Appointments:       int32(s.Count),
+			Cancelled:          int32(s.Cancelled),
+			NoShows:            int32(s.NoShows),
+			Revenue:            s.Revenue,
+		})
+	}
+	for _, d := range report.Doctors {
+		resp.Doctors = append(resp.Doctors, extpb.DoctorUtilisation{
+			DoctorId:       d.DoctorId,
+			BookedSlots:    int32(d.BookedSlots),
+			AvailableSlots: int32(d.AvailableSlots),
+			Utilisation:    d.Utilisation,
+		})
+	}
+	return resp, nil
+}
+func (a *AppoinmentServiceClient) FetchStatisticsDashboard(ctx context.Context, req *extpb.StatisticsDashboardRequest) (*extpb.StatisticsDashboardResponse, error) {
+	special, statics, err := a.service.FetchStatisticsDetails(ctx, req.Param)
+	if err != nil {
+		return nil, err
+	}
+	resp := &extpb.StatisticsDashboardResponse{
+		Status:            "success",
+		StatusCode:        200,
+		TotalPatients:     int32(statics.TotalPatients),
+		TotalDoctors:      int32(statics.TotalDoctors),
+		TotalAppointments: int32(statics.TotalAppointments),
+		TotalRevenue:      statics.TotalRevenue,
+		ExpectedRevenue:   statics.ExpectedRevenue,
+		Unavailable:       statics.Unavailable,
+	}
+	if len(statics.Unavailable) > 0 {
+		resp.Message = "some statistics are unavailable"
+	}
+	for _, s := range special {
+		resp.SpecializationStats = append(resp.SpecializationStats, extpb.SpecializationCount{
+			SpecializationName: s.Name,
+			AppointmentCount:   int32(s.Count),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/service"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// partialStatsService returns statistics with the revenue section missing
+type partialStatsService struct {
+	service.AppointmentService
+}
+
+func (partialStatsService) FetchStatisticsDetails(ctx context.Context, param string) ([]domain.SpecializationStats, domain.StatisticsData, error) {
+	return []domain.SpecializationStats{{Name: "General Medicine", Count: 4}},
+		domain.StatisticsData{TotalAppointments: 4, TotalPatients: 10, Unavailable: []string{service.SectionRevenue}}, nil
+}
+
+func TestLegacyStatisticsFailsOnMissingSection(t *testing.T) {
+	h := NewAppoinmentClient(partialStatsService{})
+	_, err := h.FetchStatisticsDetails(context.Background(), &pb.StatisticsRequest{Param: "month"})
+	if apperr.KindOf(err) != apperr.KindUnavailable {
+		t.Fatalf("got %v, want an unavailable error instead of a zero revenue", err)
+	}
+}
+
+func TestStatisticsDashboardKeepsPartialSections(t *testing.T) {
+	h := NewAppoinmentClient(partialStatsService{})
+	resp, err := h.FetchStatisticsDashboard(context.Background(), &extpb.StatisticsDashboardRequest{Param: "month"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TotalAppointments != 4 || len(resp.Unavailable) != 1 || resp.Unavailable[0] != service.SectionRevenue {
+		t.Errorf("unexpected dashboard %+v", resp)
+	}
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/document"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/payer"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/pricing"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/report"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/stats"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/validation"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+	"google.golang.org/grpc"
+)
+
+// Length limits of free text fields
+const (
+	maxNameLen   = 100
+	maxTextLen   = 2000
+	maxReasonLen = 500
+	maxPageLimit = 100
+)
+
+// appointmentTypes are the accepted values of every Type field
+var appointmentTypes = []string{domain.AppointmentTypeVideo, domain.AppointmentTypeInClinic}
+
+// statsParams are the legacy period params; empty and "all" mean no bound
+var statsParams = []string{"", "all", "day", "week", "month"}
+
+// ValidationInterceptor rejects malformed requests with InvalidArgument
+// before they reach a handler
+func ValidationInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := validateRequest(req, time.Now()); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// validateRequest checks req against the rules of its type. Requests
+// without fields to check pass.
+func validateRequest(req interface{}, now time.Time) error {
+	v := validation.New(now)
+	switch r := req.(type) {
+
+	// AppointmentService
+	case *pb.GetAvailabilityRequest:
+		v.Positive("category_id", int64(r.CategoryId))
+		v.Timestamp("requested_date_time", r.RequestedDateTime)
+	case *pb.CheckAvailabilityByDoctorIdRequest:
+		v.Required("doctor_id", r.DoctorId)
+	case *pb.ConfirmAppointmentRequest:
+		v.Required("patient_id", r.PatientId)
+		v.Required("doctor_id", r.DoctorId)
+		v.Positive("specialization_id", int64(r.SpecializationId))
+		v.FutureTimestamp("confirmed_date_time", r.ConfirmedDateTime)
+		// Legacy clients may leave type empty, which books an in-clinic visit
+		v.OneOf("type", r.Type, append([]string{""}, appointmentTypes...)...)
+	case *pb.GetAppointmentDetailsRequest:
+		v.Required("order_id", r.OrderId)
+	case *pb.GetAppointmentsRequest:
+		v.Required("patient_id", r.PatientId)
+	case *pb.VideoRoomRequest:
+		v.Required("patient_id", r.PatientId)
+		v.Required("doctor_id", r.DoctorId)
+		v.Positive("specialization_id", r.SpecializationId)
+	case *pb.StatisticsRequest:
+		v.OneOf("param", r.Param, statsParams...)
+	case *pb.GetTotalAppointmentRequest:
+		v.OneOf("param", r.Param, statsParams...)
+	case *pb.AddSpecializationRequest:
+		v.Required("name", r.Name)
+		v.MaxLen("name", r.Name, maxNameLen)
+		v.MaxLen("description", r.Description, maxTextLen)
+	case *pb.CancelAppointmentRequest:
+		v.Positive("appointment_id", int64(r.AppointmentId))
+		v.Required("patient_id", r.PatientId)
+		v.MaxLen("reason", r.Reason, maxReasonLen)
+
+	// AppointmentExtService: consultations and follow-ups
+	case *extpb.CompleteAppointmentRequest:
+		v.Positive("appointment_id", int64(r.AppointmentId))
+		v.Required("doctor_id", r.DoctorId)
+		v.MaxLen("diagnosis", r.Diagnosis, maxTextLen)
+		v.MaxLen("notes", r.Notes, maxTextLen)
+		for i, m := range r.Medications {
+			field := fmt.Sprintf("medications[%d]", i)
+			v.Required(field+".drug", m.Drug)
+			v.Required(field+".dose", m.Dose)
+			v.Required(field+".frequency", m.Frequency)
+			v.Positive(field+".duration_days", int64(m.DurationDays))
+		}
+	case *extpb.GetPrescriptionHistoryRequest:
+		v.Required("patient_id", r.PatientId)
+	case *extpb.GetVisitDocumentRequest:
+		v.Positive("appointment_id", int64(r.AppointmentId))
+		v.Required("patient_id", r.PatientId)
+		v.OneOf("kind", r.Kind, document.KindVisitSummary, document.KindPrescription)
+		v.OneOf("format", r.Format, document.FormatPDF, document.FormatHTML)
+	case *extpb.CreateFollowUpRequest:
+		v.Positive("parent_appointment_id", int64(r.ParentAppointmentId))
+		v.Required("doctor_id", r.DoctorId)
+		v.Window("window_start", "window_end", r.WindowStart, r.WindowEnd, true)
+		v.Range("fee_percent", int64(r.FeePercent), 0, 100)
+		v.MaxLen("notes", r.Notes, maxTextLen)
+	case *extpb.BookFollowUpRequest:
+		v.Positive("follow_up_id", int64(r.FollowUpId))
+		v.Required("patient_id", r.PatientId)
+		v.Future("confirmed_date_time", r.ConfirmedDateTime)
+		v.OneOf("type", r.Type, appointmentTypes...)
+	case *extpb.FollowUpAdherenceRequest:
+		v.Window("from", "to", r.From, r.To, true)
+
+	// Video
+	case *extpb.CreateVideoRoomRequest:
+		v.Positive("appointment_id", int64(r.AppointmentId))
+		v.Required("doctor_id", r.DoctorId)
+	case *extpb.GetVideoRoomRequest:
+		v.Positive("appointment_id", int64(r.AppointmentId))
+		v.Required("patient_id", r.PatientId)
+	case *extpb.VideoSessionRequest:
+		v.Required("room_id", r.RoomId)
+		v.Required("participant_id", r.ParticipantId)
+
+	// Specializations
+	case *extpb.GetSpecializationRequest:
+		if r.Id == 0 && r.Slug == "" {
+			v.Add("id", "or slug is required")
+		}
+	case *extpb.UpdateSpecializationRequest:
+		v.Positive("id", int64(r.Id))
+		v.Required("name", r.Name)
+		v.MaxLen("name", r.Name, maxNameLen)
+		v.MaxLen("description", r.Description, maxTextLen)
+		v.NonNegative("display_order", int64(r.DisplayOrder))
+	case *extpb.SpecializationIdRequest:
+		v.Positive("id", int64(r.Id))
+
+	// Pricing
+	case *extpb.GetPriceQuoteRequest:
+		v.Positive("specialization_id", int64(r.SpecializationId))
+		v.OneOf("type", r.Type, appointmentTypes...)
+		v.Time("confirmed_date_time", r.ConfirmedDateTime)
+	case *extpb.ConsultationPrice:
+		v.Positive("specialization_id", int64(r.SpecializationId))
+		v.NonNegativeAmount("video_fee", r.VideoFee)
+		v.NonNegativeAmount("in_clinic_fee", r.InClinicFee)
+		if r.Currency != "" && len(r.Currency) != 3 {
+			v.Add("currency", "must be a 3-letter ISO code")
+		}
+	case *extpb.ListPricingRequest:
+		v.NonNegative("specialization_id", int64(r.SpecializationId))
+	case *extpb.DeletePricingRequest:
+		v.Positive("id", int64(r.Id))
+	case *extpb.PriceSurcharge:
+		v.NonNegative("specialization_id", int64(r.SpecializationId))
+		v.Required("name", r.Name)
+		v.MaxLen("name", r.Name, maxNameLen)
+		v.Range("start_hour", int64(r.StartHour), 0, 24)
+		v.Range("end_hour", int64(r.EndHour), 0, 24)
+		if r.Percent <= 0 {
+			v.Add("percent", "must be greater than zero")
+		}
+
+	// Booking, promos and payers
+	case *extpb.BookAppointmentRequest:
+		v.Required("patient_id", r.PatientId)
+		v.Required("doctor_id", r.DoctorId)
+		v.Positive("specialization_id", int64(r.SpecializationId))
+		v.Future("confirmed_date_time", r.ConfirmedDateTime)
+		v.OneOf("type", r.Type, appointmentTypes...)
+		v.OneOf("payment_mode", r.PaymentMode, "", domain.PaymentModePrepaid, domain.PaymentModePayAtClinic, domain.PaymentModeFree)
+		if r.PayerId != "" {
+			v.OneOf("payer_type", r.PayerType, payer.KindInsurance, payer.KindCorporate)
+			v.Required("member_id", r.MemberId)
+		}
+	case *extpb.PromoCode:
+		v.Required("code", r.Code)
+		v.MaxLen("code", r.Code, maxNameLen)
+		v.OneOf("discount_type", r.DiscountType, pricing.DiscountPercent, pricing.DiscountFlat)
+		if r.DiscountValue <= 0 {
+			v.Add("discount_value", "must be greater than zero")
+		} else if r.DiscountType == pricing.DiscountPercent && r.DiscountValue > 100 {
+			v.Add("discount_value", "must be at most 100 for a percent discount")
+		}
+		v.NonNegativeAmount("max_discount", r.MaxDiscount)
+		v.NonNegative("global_limit", int64(r.GlobalLimit))
+		v.NonNegative("per_patient_limit", int64(r.PerPatientLimit))
+		v.Window("valid_from", "valid_until", r.ValidFrom, r.ValidUntil, false)
+		for i, id := range r.SpecializationIds {
+			v.Positive(fmt.Sprintf("specialization_ids[%d]", i), int64(id))
+		}
+	case *extpb.PromoCodeRequest:
+		v.Required("code", r.Code)
+	case *extpb.ValidatePromoCodeRequest:
+		v.Required("code", r.Code)
+		v.Required("patient_id", r.PatientId)
+		v.Positive("specialization_id", int64(r.SpecializationId))
+		v.OneOf("type", r.Type, appointmentTypes...)
+	case *extpb.ListPromoRedemptionsRequest:
+		v.Window("from", "to", r.From, r.To, false)
+	case *extpb.ExportClaimsRequest:
+		v.Window("from", "to", r.From, r.To, true)
+
+	// Payments and statistics
+	case *extpb.RecordClinicPaymentRequest:
+		v.Positive("appointment_id", r.AppointmentId)
+		v.NonNegativeAmount("amount", r.Amount)
+		v.OneOf("method", r.Method, "cash", "card")
+		v.Required("collected_by", r.CollectedBy)
+	case *extpb.RevenueStatisticsRequest:
+		v.OneOf("param", r.Param, statsParams...)
+	case *extpb.StatisticsDashboardRequest:
+		v.OneOf("param", r.Param, statsParams...)
+	case *extpb.StatisticsReportRequest:
+		v.Window("from", "to", r.From, r.To, true)
+		v.OneOf("interval", r.Interval, "", stats.IntervalDay, stats.IntervalWeek)
+
+	// Search and history
+	case *extpb.SearchAppointmentsRequest:
+		v.NonNegative("specialization_id", int64(r.SpecializationId))
+		v.OneOf("type", r.Type, append([]string{""}, appointmentTypes...)...)
+		v.OneOf("payment_status", r.PaymentStatus, "", domain.PaymentStatusPending, domain.PaymentStatusDue, domain.PaymentStatusPaid, domain.PaymentStatusWaived, domain.PaymentStatusNotRequired)
+		v.OneOf("sort_order", r.SortOrder, "", "asc", "desc")
+		v.Range("limit", int64(r.Limit), 0, maxPageLimit)
+		v.Window("from", "to", r.From, r.To, false)
+	case *extpb.PatientHistoryRequest:
+		v.Required("patient_id", r.PatientId)
+		v.Range("limit", int64(r.Limit), 0, maxPageLimit)
+		v.Window("from", "to", r.From, r.To, false)
+
+	// Doctor performance
+	case *extpb.DoctorCancelAppointmentRequest:
+		v.Positive("appointment_id", r.AppointmentId)
+		v.Required("doctor_id", r.DoctorId)
+		v.MaxLen("reason", r.Reason, maxReasonLen)
+	case *extpb.RateAppointmentRequest:
+		v.Positive("appointment_id", r.AppointmentId)
+		v.Required("patient_id", r.PatientId)
+		v.Range("score", int64(r.Score), 1, 5)
+		v.MaxLen("comment", r.Comment, maxTextLen)
+	case *extpb.DoctorPerformanceRequest:
+		v.Window("from", "to", r.From, r.To, false)
+	case *extpb.ExportReportRequest:
+		v.OneOf("report", r.Report, report.Appointments, report.Cancellations, report.RevenueBySpecialization, report.DoctorUtilisation)
+		v.OneOf("format", r.Format, report.FormatCSV, report.FormatXLSX)
+		v.Window("from", "to", r.From, r.To, true)
+
+	// Patient data requests
+	case *extpb.PatientDataRequest:
+		v.Required("patient_id", r.PatientId)
+
+	// Audit log
+	case *extpb.AuditLogRequest:
+		v.NonNegative("appointment_id", r.AppointmentId)
+		v.OneOf("action", r.Action, append([]string{""}, audit.Actions...)...)
+		v.Range("limit", int64(r.Limit), 0, maxPageLimit)
+		v.Window("from", "to", r.From, r.To, false)
+		if _, err := parseAuditCursor(r.Cursor); err != nil {
+			v.Add("cursor", "is not a cursor returned by this RPC")
+		}
+	case *extpb.VerifyAuditLogRequest:
+		// The anchor is optional, but an entry id without its hash checks nothing
+		if r.AnchorEntryId != 0 || r.AnchorHash != "" {
+			v.Positive("anchor_entry_id", int64(r.AnchorEntryId))
+			if _, err := hex.DecodeString(r.AnchorHash); err != nil || len(r.AnchorHash) != sha256.Size*2 {
+				v.Add("anchor_hash", "must be the hash of an audit entry")
+			}
+		}
+	}
+	return v.Err()
+}
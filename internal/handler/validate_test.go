@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+// violatedFields returns the fields err rejects, in the order they were reported
+func violatedFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var e *apperr.Error
+	if !errors.As(err, &e) || e.Kind != apperr.KindInvalidArgument {
+		t.Fatalf("got %v, want an invalid argument error", err)
+	}
+	fields := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		fields[i] = v.Field
+	}
+	return fields
+}
+
+func TestValidateRequest(t *testing.T) {
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(24*time.Hour)
+	from, to := now.AddDate(0, -1, 0), now
+	long := strings.Repeat("x", maxTextLen+1)
+
+	tests := []struct {
+		name string
+		req  interface{}
+		want []string
+	}{
+		// AppointmentService
+		{"availability", &pb.GetAvailabilityRequest{CategoryId: 1, RequestedDateTime: timestamppb.New(future)}, nil},
+		{"availability missing", &pb.GetAvailabilityRequest{}, []string{"category_id", "requested_date_time"}},
+		{"doctor availability", &pb.CheckAvailabilityByDoctorIdRequest{DoctorId: "d1"}, nil},
+		{"doctor availability missing", &pb.CheckAvailabilityByDoctorIdRequest{DoctorId: " "}, []string{"doctor_id"}},
+		{"confirm", &pb.ConfirmAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: timestamppb.New(future), Type: "video"}, nil},
+		{"confirm in clinic", &pb.ConfirmAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: timestamppb.New(future), Type: "in-clinic"}, nil},
+		{"confirm bogus type", &pb.ConfirmAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: timestamppb.New(future), Type: "offline"}, []string{"type"}},
+		{"confirm legacy empty type", &pb.ConfirmAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: timestamppb.New(future)}, nil},
+		{"confirm past", &pb.ConfirmAppointmentRequest{SpecializationId: -1, ConfirmedDateTime: timestamppb.New(past)}, []string{"patient_id", "doctor_id", "specialization_id", "confirmed_date_time"}},
+		{"appointment details", &pb.GetAppointmentDetailsRequest{OrderId: "order_1"}, nil},
+		{"appointment details missing", &pb.GetAppointmentDetailsRequest{}, []string{"order_id"}},
+		{"upcoming", &pb.GetAppointmentsRequest{PatientId: "p1"}, nil},
+		{"upcoming missing", &pb.GetAppointmentsRequest{}, []string{"patient_id"}},
+		{"legacy video room", &pb.VideoRoomRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 2}, nil},
+		{"legacy video room missing", &pb.VideoRoomRequest{}, []string{"patient_id", "doctor_id", "specialization_id"}},
+		{"statistics", &pb.StatisticsRequest{Param: "week"}, nil},
+		{"statistics bad param", &pb.StatisticsRequest{Param: "year"}, []string{"param"}},
+		{"total appointments", &pb.GetTotalAppointmentRequest{Param: ""}, nil},
+		{"total appointments bad param", &pb.GetTotalAppointmentRequest{Param: "decade"}, []string{"param"}},
+		{"add specialization", &pb.AddSpecializationRequest{Name: "Cardiology"}, nil},
+		{"add specialization long", &pb.AddSpecializationRequest{Description: long}, []string{"name", "description"}},
+		{"cancel", &pb.CancelAppointmentRequest{AppointmentId: 1, PatientId: "p1", Reason: "travel"}, nil},
+		{"cancel missing", &pb.CancelAppointmentRequest{Reason: long}, []string{"appointment_id", "patient_id", "reason"}},
+
+		// Consultations and follow-ups
+		{"complete", &extpb.CompleteAppointmentRequest{AppointmentId: 1, DoctorId: "d1", Medications: []extpb.Medication{{Drug: "Paracetamol", Dose: "500 mg", Frequency: "daily", DurationDays: 3}}}, nil},
+		{"complete bad medication", &extpb.CompleteAppointmentRequest{AppointmentId: 1, DoctorId: "d1", Medications: []extpb.Medication{{Drug: "Paracetamol"}}}, []string{"medications[0].dose", "medications[0].frequency", "medications[0].duration_days"}},
+		{"prescriptions", &extpb.GetPrescriptionHistoryRequest{PatientId: "p1"}, nil},
+		{"prescriptions missing", &extpb.GetPrescriptionHistoryRequest{}, []string{"patient_id"}},
+		{"visit document", &extpb.GetVisitDocumentRequest{AppointmentId: 1, PatientId: "p1", Kind: "prescription", Format: "pdf"}, nil},
+		{"visit document bad", &extpb.GetVisitDocumentRequest{AppointmentId: 1, PatientId: "p1", Kind: "invoice", Format: "docx"}, []string{"kind", "format"}},
+		{"create follow-up", &extpb.CreateFollowUpRequest{ParentAppointmentId: 1, DoctorId: "d1", WindowStart: future, WindowEnd: future.AddDate(0, 0, 7), FeePercent: 50}, nil},
+		{"create follow-up bad", &extpb.CreateFollowUpRequest{ParentAppointmentId: 1, DoctorId: "d1", WindowStart: future, WindowEnd: future, FeePercent: 120}, []string{"window_end", "fee_percent"}},
+		{"book follow-up", &extpb.BookFollowUpRequest{FollowUpId: 1, PatientId: "p1", ConfirmedDateTime: future, Type: "video"}, nil},
+		{"book follow-up bogus type", &extpb.BookFollowUpRequest{FollowUpId: 1, PatientId: "p1", ConfirmedDateTime: future, Type: "Video"}, []string{"type"}},
+		{"book follow-up missing", &extpb.BookFollowUpRequest{FollowUpId: 1, PatientId: "p1", ConfirmedDateTime: past}, []string{"confirmed_date_time", "type"}},
+		{"adherence", &extpb.FollowUpAdherenceRequest{From: from, To: to}, nil},
+		{"adherence missing", &extpb.FollowUpAdherenceRequest{From: from}, []string{"to"}},
+
+		// Video
+		{"create video room", &extpb.CreateVideoRoomRequest{AppointmentId: 1, DoctorId: "d1"}, nil},
+		{"create video room missing", &extpb.CreateVideoRoomRequest{}, []string{"appointment_id", "doctor_id"}},
+		{"get video room", &extpb.GetVideoRoomRequest{AppointmentId: 1, PatientId: "p1"}, nil},
+		{"get video room missing", &extpb.GetVideoRoomRequest{AppointmentId: -1}, []string{"appointment_id", "patient_id"}},
+		{"video session", &extpb.VideoSessionRequest{RoomId: "room", ParticipantId: "d1"}, nil},
+		{"video session missing", &extpb.VideoSessionRequest{}, []string{"room_id", "participant_id"}},
+
+		// Specializations
+		{"get specialization by slug", &extpb.GetSpecializationRequest{Slug: "cardiology"}, nil},
+		{"get specialization missing", &extpb.GetSpecializationRequest{}, []string{"id"}},
+		{"update specialization", &extpb.UpdateSpecializationRequest{Id: 1, Name: "Cardiology"}, nil},
+		{"update specialization bad", &extpb.UpdateSpecializationRequest{Id: 1, DisplayOrder: -1}, []string{"name", "display_order"}},
+		{"specialization id", &extpb.SpecializationIdRequest{Id: 1}, nil},
+		{"specialization id missing", &extpb.SpecializationIdRequest{}, []string{"id"}},
+
+		// Pricing
+		{"price quote", &extpb.GetPriceQuoteRequest{SpecializationId: 1, Type: "video", ConfirmedDateTime: future}, nil},
+		{"price quote bogus type", &extpb.GetPriceQuoteRequest{SpecializationId: 1, Type: "house-call", ConfirmedDateTime: future}, []string{"type"}},
+		{"price quote missing", &extpb.GetPriceQuoteRequest{}, []string{"specialization_id", "type", "confirmed_date_time"}},
+		{"set price", &extpb.ConsultationPrice{SpecializationId: 1, VideoFee: 300, InClinicFee: 500, Currency: "INR"}, nil},
+		{"set price bad", &extpb.ConsultationPrice{SpecializationId: 1, VideoFee: -1, Currency: "RUPEE"}, []string{"video_fee", "currency"}},
+		{"list pricing", &extpb.ListPricingRequest{}, nil},
+		{"list pricing negative", &extpb.ListPricingRequest{SpecializationId: -1}, []string{"specialization_id"}},
+		{"delete pricing", &extpb.DeletePricingRequest{Id: 1}, nil},
+		{"delete pricing missing", &extpb.DeletePricingRequest{}, []string{"id"}},
+		{"surcharge", &extpb.PriceSurcharge{Name: "Night", StartHour: 22, EndHour: 6, Percent: 20}, nil},
+		{"surcharge bad", &extpb.PriceSurcharge{Name: "Night", StartHour: 25, EndHour: 6}, []string{"start_hour", "percent"}},
+
+		// Booking, promos and payers
+		{"book", &extpb.BookAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: future, Type: "video", PaymentMode: "free"}, nil},
+		{"book clinic visit", &extpb.BookAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: future, Type: "in-clinic"}, nil},
+		{"book bogus type", &extpb.BookAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: future, Type: "walk-in"}, []string{"type"}},
+		{"book bad", &extpb.BookAppointmentRequest{PatientId: "p1", DoctorId: "d1", SpecializationId: 1, ConfirmedDateTime: future, PaymentMode: "cheque", PayerId: "acme"}, []string{"type", "payment_mode", "payer_type", "member_id"}},
+		{"promo code", &extpb.PromoCode{Code: "WELCOME", DiscountType: "percent", DiscountValue: 10, ValidFrom: from, ValidUntil: future}, nil},
+		{"promo code bad", &extpb.PromoCode{Code: "WELCOME", DiscountType: "percent", DiscountValue: 150, GlobalLimit: -1, SpecializationIds: []int32{0}}, []string{"discount_value", "global_limit", "specialization_ids[0]"}},
+		{"promo lookup", &extpb.PromoCodeRequest{Code: "WELCOME"}, nil},
+		{"promo lookup missing", &extpb.PromoCodeRequest{}, []string{"code"}},
+		{"validate promo", &extpb.ValidatePromoCodeRequest{Code: "WELCOME", PatientId: "p1", SpecializationId: 1, Type: "video"}, nil},
+		{"validate promo bogus type", &extpb.ValidatePromoCodeRequest{Code: "WELCOME", PatientId: "p1", SpecializationId: 1, Type: "phone"}, []string{"type"}},
+		{"validate promo missing", &extpb.ValidatePromoCodeRequest{Code: "WELCOME"}, []string{"patient_id", "specialization_id", "type"}},
+		{"redemptions", &extpb.ListPromoRedemptionsRequest{}, nil},
+		{"redemptions backwards", &extpb.ListPromoRedemptionsRequest{From: to, To: from}, []string{"to"}},
+		{"claims", &extpb.ExportClaimsRequest{PayerId: "acme", From: from, To: to}, nil},
+		{"claims missing", &extpb.ExportClaimsRequest{PayerId: "acme"}, []string{"from", "to"}},
+
+		// Payments and statistics
+		{"clinic payment", &extpb.RecordClinicPaymentRequest{AppointmentId: 1, Amount: 500, Method: "cash", CollectedBy: "fd1"}, nil},
+		{"clinic payment bad", &extpb.RecordClinicPaymentRequest{AppointmentId: 1, Amount: -5, Method: "upi"}, []string{"amount", "method", "collected_by"}},
+		{"revenue", &extpb.RevenueStatisticsRequest{Param: "month"}, nil},
+		{"revenue bad param", &extpb.RevenueStatisticsRequest{Param: "year"}, []string{"param"}},
+		{"dashboard", &extpb.StatisticsDashboardRequest{Param: "all"}, nil},
+		{"dashboard bad param", &extpb.StatisticsDashboardRequest{Param: "year"}, []string{"param"}},
+		{"statistics report", &extpb.StatisticsReportRequest{From: from, To: to, Interval: "week"}, nil},
+		{"statistics report bad", &extpb.StatisticsReportRequest{From: from, To: to, Interval: "hour"}, []string{"interval"}},
+
+		// Search and history
+		{"search", &extpb.SearchAppointmentsRequest{Type: "video", PaymentStatus: "paid", SortOrder: "desc", Limit: 20}, nil},
+		{"search any type", &extpb.SearchAppointmentsRequest{}, nil},
+		{"search bogus type", &extpb.SearchAppointmentsRequest{Type: "' OR 1=1 --"}, []string{"type"}},
+		{"search bad", &extpb.SearchAppointmentsRequest{SpecializationId: -1, PaymentStatus: "owed", SortOrder: "up", Limit: maxPageLimit + 1}, []string{"specialization_id", "payment_status", "sort_order", "limit"}},
+		{"history", &extpb.PatientHistoryRequest{PatientId: "p1", Limit: 10}, nil},
+		{"history bad", &extpb.PatientHistoryRequest{Limit: -1, From: to, To: from}, []string{"patient_id", "limit", "to"}},
+
+		// Doctor performance
+		{"doctor cancel", &extpb.DoctorCancelAppointmentRequest{AppointmentId: 1, DoctorId: "d1"}, nil},
+		{"doctor cancel missing", &extpb.DoctorCancelAppointmentRequest{}, []string{"appointment_id", "doctor_id"}},
+		{"rate", &extpb.RateAppointmentRequest{AppointmentId: 1, PatientId: "p1", Score: 5}, nil},
+		{"rate bad score", &extpb.RateAppointmentRequest{AppointmentId: 1, PatientId: "p1", Score: 6}, []string{"score"}},
+		{"performance", &extpb.DoctorPerformanceRequest{DoctorId: "d1"}, nil},
+		{"performance backwards", &extpb.DoctorPerformanceRequest{From: to, To: from}, []string{"to"}},
+		{"export report", &extpb.ExportReportRequest{Report: "appointments", Format: "xlsx", From: from, To: to}, nil},
+		{"export report bad", &extpb.ExportReportRequest{Report: "invoices", Format: "pdf", From: from, To: to}, []string{"report", "format"}},
+
+		// Patient data and audit
+		{"patient data", &extpb.PatientDataRequest{PatientId: "p1"}, nil},
+		{"patient data missing", &extpb.PatientDataRequest{}, []string{"patient_id"}},
+		{"audit log", &extpb.AuditLogRequest{AppointmentId: 1, Action: audit.ActionCreate, Limit: 50}, nil},
+		{"audit log bad", &extpb.AuditLogRequest{AppointmentId: -1, Action: "delete", Cursor: "not-a-cursor"}, []string{"appointment_id", "action", "cursor"}},
+		{"audit log erase", &extpb.AuditLogRequest{Action: audit.ActionPatientErase}, nil},
+		{"audit log read", &extpb.AuditLogRequest{Action: audit.ActionHistoryRead}, nil},
+		{"verify audit log", &extpb.VerifyAuditLogRequest{}, nil},
+		{"verify audit log anchor", &extpb.VerifyAuditLogRequest{AnchorEntryId: 12, AnchorHash: strings.Repeat("ab", 32)}, nil},
+		{"verify audit log bad anchor", &extpb.VerifyAuditLogRequest{AnchorHash: "abc"}, []string{"anchor_entry_id", "anchor_hash"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violatedFields(t, validateRequest(tt.req, now))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations on %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
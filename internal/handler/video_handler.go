@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	extpb "github.com/nuhmanudheent/hosp-connect-appointment-service/proto/ext"
+)
+
+func videoSessionResponse(session domain.VideoTreatment, message string) *extpb.VideoSessionResponse {
+	return &extpb.VideoSessionResponse{
+		Status:              "success",
+		StatusCode:          200,
+		Message:             message,
+		SessionStatus:       session.Status,
+		StartedAt:           session.StartedAt,
+		EndedAt:             session.EndedAt,
+		ConsultationSeconds: int32(session.ConsultationSeconds),
+		DoctorNoShow:        session.DoctorNoShow,
+		PatientNoShow:       session.PatientNoShow,
+	}
+}
+func (h *AppoinmentServiceClient) CreateVideoRoom(ctx context.Context, req *extpb.CreateVideoRoomRequest) (*extpb.VideoRoomResponse, error) {
+	room, err := h.service.CreateVideoRoom(ctx, int(req.AppointmentId), req.DoctorId)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.VideoRoomResponse{
+		Status:     "success",
+		StatusCode: 200,
+		RoomUrl:    room,
+	}, nil
+}
+func (h *AppoinmentServiceClient) GetVideoRoom(ctx context.Context, req *extpb.GetVideoRoomRequest) (*extpb.VideoRoomResponse, error) {
+	room, err := h.service.GetVideoRoom(ctx, int(req.AppointmentId), req.PatientId)
+	if err != nil {
+		return nil, err
+	}
+	return &extpb.VideoRoomResponse{
+		Status:     "success",
+		StatusCode: 200,
+		RoomUrl:    room,
+	}, nil
+}
+func (h *AppoinmentServiceClient) JoinVideoSession(ctx context.Context, req *extpb.VideoSessionRequest) (*extpb.VideoSessionResponse, error) {
+	session, err := h.service.JoinVideoSession(ctx, req.RoomId, req.ParticipantId)
+	if err != nil {
+		return nil, err
+	}
+	return videoSessionResponse(session, "Joined video session"), nil
+}
+func (h *AppoinmentServiceClient) LeaveVideoSession(ctx context.Context, req *extpb.VideoSessionRequest) (*extpb.VideoSessionResponse, error) {
+	if err := h.service.LeaveVideoSession(ctx, req.RoomId, req.ParticipantId); err != nil {
+		return nil, err
+	}
+	return &extpb.VideoSessionResponse{
+		Status:     "success",
+		StatusCode: 200,
+		Message:    "Left video session",
+	}, nil
+}
+func (h *AppoinmentServiceClient) EndVideoSession(ctx context.Context, req *extpb.VideoSessionRequest) (*extpb.VideoSessionResponse, error) {
+	session, err := h.service.EndVideoSession(ctx, req.RoomId, req.ParticipantId)
+	if err != nil {
+		return nil, err
+	}
+	return videoSessionResponse(session, "Video session ended"), nil
+}
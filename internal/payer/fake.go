@@ -0,0 +1,56 @@
+package payer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// FakePolicy is a member's cover with the fake payer. CoveragePercent of the
+// visit is covered, up to Limit when it is set.
+type FakePolicy struct {
+	MemberId        string
+	CoveragePercent float64
+	Limit           float64
+}
+
+// FakeProvider approves visits for the policies it knows about and records
+// every request, for tests and local runs without a payer integration.
+type FakeProvider struct {
+	mu       sync.Mutex
+	policies map[string]FakePolicy
+	Requests []EligibilityRequest
+	Err      error
+}
+
+func NewFakeProvider(policies ...FakePolicy) *FakeProvider {
+	f := &FakeProvider{policies: map[string]FakePolicy{}}
+	for _, p := range policies {
+		f.policies[p.MemberId] = p
+	}
+	return f
+}
+
+func (f *FakeProvider) CheckEligibility(ctx context.Context, req EligibilityRequest) (Eligibility, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return Eligibility{}, f.Err
+	}
+	f.Requests = append(f.Requests, req)
+
+	policy, ok := f.policies[req.MemberId]
+	if !ok {
+		return Eligibility{Reason: "member not found"}, nil
+	}
+	covered := req.Amount * policy.CoveragePercent / 100
+	if policy.Limit > 0 && covered > policy.Limit {
+		covered = policy.Limit
+	}
+	return Eligibility{
+		Eligible:      true,
+		CoveredAmount: math.Round(covered*100) / 100,
+		ClaimRef:      fmt.Sprintf("FAKE-%s-%d-%d", req.PayerId, req.AppointmentId, len(f.Requests)),
+	}, nil
+}
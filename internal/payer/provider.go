@@ -0,0 +1,70 @@
+package payer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Kinds of third party that can pay for a visit
+const (
+	KindInsurance = "insurance"
+	KindCorporate = "corporate"
+)
+
+// Provider checks whether an insurer or corporate sponsor covers a visit and
+// pre-authorises the covered part
+type Provider interface {
+	CheckEligibility(ctx context.Context, req EligibilityRequest) (Eligibility, error)
+}
+
+// EligibilityRequest describes the visit a payer is asked to cover
+type EligibilityRequest struct {
+	Kind             string
+	PayerId          string
+	MemberId         string
+	PatientId        string
+	DoctorId         string
+	SpecializationId int32
+	AppointmentId    int
+	Amount           float64
+	Currency         string
+}
+
+// Eligibility is the payer's answer. CoveredAmount is what the payer will
+// settle; the patient pays the rest upfront. ClaimRef identifies the
+// pre-authorisation in the payer's system.
+type Eligibility struct {
+	Eligible      bool
+	CoveredAmount float64
+	ClaimRef      string
+	Reason        string
+}
+
+var ErrUnknownPayer = errors.New("payer is not supported")
+
+// Registry routes eligibility checks to the provider registered for a payer id
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]Provider{}}
+}
+
+func (r *Registry) Register(payerId string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[payerId] = provider
+}
+
+func (r *Registry) CheckEligibility(ctx context.Context, req EligibilityRequest) (Eligibility, error) {
+	r.mu.RLock()
+	provider, ok := r.providers[req.PayerId]
+	r.mu.RUnlock()
+	if !ok {
+		return Eligibility{}, ErrUnknownPayer
+	}
+	return provider.CheckEligibility(ctx, req)
+}
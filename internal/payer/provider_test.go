@@ -0,0 +1,63 @@
+package payer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeProviderEligibility(t *testing.T) {
+	fake := NewFakeProvider(
+		FakePolicy{MemberId: "M-80", CoveragePercent: 80},
+		FakePolicy{MemberId: "M-CAP", CoveragePercent: 100, Limit: 150},
+	)
+	tests := []struct {
+		name     string
+		memberId string
+		amount   float64
+		want     Eligibility
+	}{
+		{"percent of the visit", "M-80", 333.33, Eligibility{Eligible: true, CoveredAmount: 266.66, ClaimRef: "FAKE-acme-7-1"}},
+		{"capped at the limit", "M-CAP", 400, Eligibility{Eligible: true, CoveredAmount: 150, ClaimRef: "FAKE-acme-7-2"}},
+		{"unknown member", "M-404", 400, Eligibility{Reason: "member not found"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fake.CheckEligibility(context.Background(), EligibilityRequest{Kind: KindInsurance, PayerId: "acme", MemberId: tt.memberId, AppointmentId: 7, Amount: tt.amount})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if len(fake.Requests) != len(tests) {
+		t.Errorf("recorded %d requests, want %d", len(fake.Requests), len(tests))
+	}
+}
+
+func TestFakeProviderError(t *testing.T) {
+	fake := NewFakeProvider()
+	fake.Err = errors.New("payer down")
+	if _, err := fake.CheckEligibility(context.Background(), EligibilityRequest{PayerId: "acme"}); !errors.Is(err, fake.Err) {
+		t.Errorf("err = %v, want %v", err, fake.Err)
+	}
+}
+
+func TestRegistryRoutesByPayerId(t *testing.T) {
+	acme := NewFakeProvider(FakePolicy{MemberId: "M-1", CoveragePercent: 50})
+	registry := NewRegistry()
+	registry.Register("acme", acme)
+
+	got, err := registry.CheckEligibility(context.Background(), EligibilityRequest{PayerId: "acme", MemberId: "M-1", Amount: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Eligible || got.CoveredAmount != 50 || len(acme.Requests) != 1 {
+		t.Errorf("got %+v after %d requests", got, len(acme.Requests))
+	}
+	if _, err := registry.CheckEligibility(context.Background(), EligibilityRequest{PayerId: "other"}); !errors.Is(err, ErrUnknownPayer) {
+		t.Errorf("err = %v, want ErrUnknownPayer", err)
+	}
+}
@@ -0,0 +1,73 @@
+package pricing
+
+import (
+	"math"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Fee and currency charged when no price is configured for a specialization
+const (
+	DefaultFee      = 200
+	DefaultCurrency = "INR"
+)
+
+// Quote works out the fee for an appointment. A doctor override takes
+// precedence over the specialization base price; a zero fee on either means
+// "not set" for that visit type. Surcharges whose hour range covers the
+// appointment time are added up as a percentage of the base fee.
+func Quote(prices []domain.ConsultationPrice, surcharges []domain.PriceSurcharge, doctorId, appointmentType string, at time.Time) domain.PriceQuote {
+	quote := domain.PriceQuote{BaseFee: DefaultFee, Currency: DefaultCurrency}
+
+	var base, override *domain.ConsultationPrice
+	for i := range prices {
+		switch prices[i].DoctorId {
+		case "":
+			base = &prices[i]
+		case doctorId:
+			override = &prices[i]
+		}
+	}
+	for _, p := range []*domain.ConsultationPrice{base, override} {
+		if p == nil {
+			continue
+		}
+		if fee := feeFor(*p, appointmentType); fee > 0 {
+			quote.BaseFee = fee
+			quote.Currency = p.Currency
+		}
+	}
+	if quote.Currency == "" {
+		quote.Currency = DefaultCurrency
+	}
+
+	percent := 0.0
+	for _, s := range surcharges {
+		if coversHour(s, at.Hour()) {
+			percent += s.Percent
+		}
+	}
+	quote.Surcharge = round(quote.BaseFee * percent / 100)
+	quote.Amount = round(quote.BaseFee + quote.Surcharge)
+	return quote
+}
+
+func feeFor(p domain.ConsultationPrice, appointmentType string) float64 {
+	if appointmentType == domain.AppointmentTypeVideo {
+		return p.VideoFee
+	}
+	return p.InClinicFee
+}
+
+// coversHour reports whether hour falls in [StartHour, EndHour), wrapping past midnight
+func coversHour(s domain.PriceSurcharge, hour int) bool {
+	if s.StartHour <= s.EndHour {
+		return hour >= s.StartHour && hour < s.EndHour
+	}
+	return hour >= s.StartHour || hour < s.EndHour
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,59 @@
+package pricing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Discount types a promo code can carry
+const (
+	DiscountPercent = "percent"
+	DiscountFlat    = "flat"
+)
+
+// CheckPromo reports why a promo code cannot be used for a booking in the given
+// specialization at the given time, or nil when it can
+func CheckPromo(promo domain.PromoCode, specializationId int32, at time.Time) error {
+	if !promo.IsActive {
+		return errors.New("promo code is no longer active")
+	}
+	if !promo.ValidFrom.IsZero() && at.Before(promo.ValidFrom) {
+		return errors.New("promo code is not valid yet")
+	}
+	if !promo.ValidUntil.IsZero() && !at.Before(promo.ValidUntil) {
+		return errors.New("promo code has expired")
+	}
+	if len(promo.Specializations) == 0 {
+		return nil
+	}
+	for _, s := range promo.Specializations {
+		if int32(s.ID) == specializationId {
+			return nil
+		}
+	}
+	return errors.New("promo code does not apply to this specialization")
+}
+
+// Discount works out how much a promo code takes off amount. Percent discounts
+// are capped by MaxDiscount when it is set, and no discount exceeds the amount.
+func Discount(promo domain.PromoCode, amount float64) float64 {
+	var discount float64
+	switch promo.DiscountType {
+	case DiscountPercent:
+		discount = amount * promo.DiscountValue / 100
+		if promo.MaxDiscount > 0 && discount > promo.MaxDiscount {
+			discount = promo.MaxDiscount
+		}
+	case DiscountFlat:
+		discount = promo.DiscountValue
+	}
+	if discount > amount {
+		discount = amount
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return round(discount)
+}
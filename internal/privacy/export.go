@@ -0,0 +1,296 @@
+// Package privacy renders the data export handed to a patient and names the
+// pseudonym that replaces their id on erasure.
+package privacy
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// ExportFormat identifies the layout of the bundle so readers can tell versions apart
+const ExportFormat = "hospconnect.appointment.patient-export.v1"
+
+// ContentType is the media type of a rendered bundle
+const ContentType = "application/json"
+
+// PseudonymPrefix marks ids that replaced an erased patient's id
+const PseudonymPrefix = "erased-"
+
+// NewPseudonym returns a random id that cannot be traced back to the patient
+func NewPseudonym() string {
+	return PseudonymPrefix + uuid.NewString()
+}
+
+// FileName is the suggested name of a patient's export
+func FileName(patientId string, at time.Time) string {
+	return "patient-data-" + patientId + "-" + at.UTC().Format("20060102") + ".json"
+}
+
+type bundle struct {
+	Format            string             `json:"format"`
+	PatientId         string             `json:"patient_id"`
+	GeneratedAt       time.Time          `json:"generated_at"`
+	Appointments      []appointment      `json:"appointments"`
+	Consultations     []consultation     `json:"consultations"`
+	FollowUps         []followUp         `json:"follow_ups"`
+	VideoSessions     []videoSession     `json:"video_sessions"`
+	VideoParticipants []videoParticipant `json:"video_participations"`
+	Ratings           []rating           `json:"ratings"`
+	PromoRedemptions  []promoRedemption  `json:"promo_redemptions"`
+	AuditEntries      []auditEntry       `json:"audit_entries"`
+}
+
+type appointment struct {
+	AppointmentId       int        `json:"appointment_id"`
+	DoctorId            string     `json:"doctor_id"`
+	SpecializationId    int32      `json:"specialization_id"`
+	AppointmentTime     time.Time  `json:"appointment_time"`
+	DurationMinutes     int        `json:"duration_minutes"`
+	Type                string     `json:"type"`
+	Status              string     `json:"status"`
+	ParentAppointmentId int        `json:"parent_appointment_id,omitempty"`
+	Amount              float64    `json:"amount"`
+	Currency            string     `json:"currency"`
+	PaymentMode         string     `json:"payment_mode"`
+	PaymentStatus       string     `json:"payment_status"`
+	PaymentId           string     `json:"payment_id,omitempty"`
+	PayerType           string     `json:"payer_type,omitempty"`
+	PayerId             string     `json:"payer_id,omitempty"`
+	MemberId            string     `json:"member_id,omitempty"`
+	CoveredAmount       float64    `json:"covered_amount,omitempty"`
+	ClaimRef            string     `json:"claim_ref,omitempty"`
+	ClaimStatus         string     `json:"claim_status,omitempty"`
+	CollectedAmount     float64    `json:"collected_amount,omitempty"`
+	CollectionMethod    string     `json:"collection_method,omitempty"`
+	CollectedAt         *time.Time `json:"collected_at,omitempty"`
+	CancelledBy         string     `json:"cancelled_by,omitempty"`
+	CancelReason        string     `json:"cancel_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	Deleted             bool       `json:"deleted,omitempty"`
+}
+
+type consultation struct {
+	AppointmentId int            `json:"appointment_id"`
+	DoctorId      string         `json:"doctor_id"`
+	Diagnosis     string         `json:"diagnosis"`
+	Notes         string         `json:"notes"`
+	Vitals        vitals         `json:"vitals"`
+	Prescriptions []prescription `json:"prescriptions"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type vitals struct {
+	TemperatureCelsius float64 `json:"temperature_celsius,omitempty"`
+	PulseRate          int     `json:"pulse_rate,omitempty"`
+	BloodPressure      string  `json:"blood_pressure,omitempty"`
+	RespiratoryRate    int     `json:"respiratory_rate,omitempty"`
+	OxygenSaturation   int     `json:"oxygen_saturation,omitempty"`
+	WeightKg           float64 `json:"weight_kg,omitempty"`
+}
+
+type prescription struct {
+	Drug         string `json:"drug"`
+	Dose         string `json:"dose"`
+	Frequency    string `json:"frequency"`
+	DurationDays int    `json:"duration_days"`
+	Instructions string `json:"instructions"`
+}
+
+type followUp struct {
+	ParentAppointmentId int       `json:"parent_appointment_id"`
+	DoctorId            string    `json:"doctor_id"`
+	SpecializationId    int32     `json:"specialization_id"`
+	WindowStart         time.Time `json:"window_start"`
+	WindowEnd           time.Time `json:"window_end"`
+	FeePercent          int       `json:"fee_percent"`
+	Notes               string    `json:"notes"`
+	Status              string    `json:"status"`
+	BookedAppointmentId int       `json:"booked_appointment_id,omitempty"`
+}
+
+type videoSession struct {
+	RoomId              string     `json:"room_id"`
+	AppointmentId       int        `json:"appointment_id"`
+	Status              string     `json:"status"`
+	StartedAt           *time.Time `json:"started_at,omitempty"`
+	EndedAt             *time.Time `json:"ended_at,omitempty"`
+	ConsultationSeconds int        `json:"consultation_seconds"`
+	PatientJoined       bool       `json:"patient_joined"`
+	PatientNoShow       bool       `json:"patient_no_show"`
+}
+
+type videoParticipant struct {
+	RoomId   string     `json:"room_id"`
+	JoinedAt time.Time  `json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at,omitempty"`
+}
+
+type rating struct {
+	AppointmentId int       `json:"appointment_id"`
+	DoctorId      string    `json:"doctor_id"`
+	Score         int       `json:"score"`
+	Comment       string    `json:"comment"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type promoRedemption struct {
+	Code           string    `json:"code"`
+	AppointmentId  int       `json:"appointment_id"`
+	OriginalAmount float64   `json:"original_amount"`
+	DiscountAmount float64   `json:"discount_amount"`
+	FinalAmount    float64   `json:"final_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type auditEntry struct {
+	CreatedAt     time.Time       `json:"created_at"`
+	ActorId       string          `json:"actor_id"`
+	ActorRole     string          `json:"actor_role"`
+	Action        string          `json:"action"`
+	AppointmentId int             `json:"appointment_id"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+}
+
+// Bundle renders everything held about the patient as one indented JSON
+// document. Empty sections are written as empty lists rather than left out.
+func Bundle(data domain.PatientData) ([]byte, error) {
+	b := bundle{
+		Format:            ExportFormat,
+		PatientId:         data.PatientId,
+		GeneratedAt:       data.GeneratedAt.UTC(),
+		Appointments:      []appointment{},
+		Consultations:     []consultation{},
+		FollowUps:         []followUp{},
+		VideoSessions:     []videoSession{},
+		VideoParticipants: []videoParticipant{},
+		Ratings:           []rating{},
+		PromoRedemptions:  []promoRedemption{},
+		AuditEntries:      []auditEntry{},
+	}
+	for _, a := range data.Appointments {
+		b.Appointments = append(b.Appointments, appointment{
+			AppointmentId:       a.AppointmentId,
+			DoctorId:            a.DoctorId,
+			SpecializationId:    a.SpecializationId,
+			AppointmentTime:     a.AppointmentTime,
+			DurationMinutes:     int(a.Duration / time.Minute),
+			Type:                a.Type,
+			Status:              a.Status,
+			ParentAppointmentId: a.ParentAppointmentId,
+			Amount:              a.Amount,
+			Currency:            a.Currency,
+			PaymentMode:         a.PaymentMode,
+			PaymentStatus:       a.PaymentStatus,
+			PaymentId:           a.PaymentId,
+			PayerType:           a.PayerType,
+			PayerId:             a.PayerId,
+			MemberId:            a.MemberId,
+			CoveredAmount:       a.CoveredAmount,
+			ClaimRef:            a.ClaimRef,
+			ClaimStatus:         a.ClaimStatus,
+			CollectedAmount:     a.CollectedAmount,
+			CollectionMethod:    a.CollectionMethod,
+			CollectedAt:         a.CollectedAt,
+			CancelledBy:         a.CancelledBy,
+			CancelReason:        a.CancelReason,
+			CreatedAt:           a.CreatedAt,
+			Deleted:             a.DeletedAt.Valid,
+		})
+	}
+	for _, c := range data.Consultations {
+		out := consultation{
+			AppointmentId: c.AppointmentId,
+			DoctorId:      c.DoctorId,
+			Diagnosis:     c.Diagnosis,
+			Notes:         c.Notes,
+			Vitals:        vitals(c.Vitals),
+			Prescriptions: []prescription{},
+			CreatedAt:     c.CreatedAt,
+		}
+		for _, p := range c.Prescriptions {
+			out.Prescriptions = append(out.Prescriptions, prescription{
+				Drug:         p.Drug,
+				Dose:         p.Dose,
+				Frequency:    p.Frequency,
+				DurationDays: p.DurationDays,
+				Instructions: p.Instructions,
+			})
+		}
+		b.Consultations = append(b.Consultations, out)
+	}
+	for _, f := range data.FollowUps {
+		b.FollowUps = append(b.FollowUps, followUp{
+			ParentAppointmentId: f.ParentAppointmentId,
+			DoctorId:            f.DoctorId,
+			SpecializationId:    f.SpecializationId,
+			WindowStart:         f.WindowStart,
+			WindowEnd:           f.WindowEnd,
+			FeePercent:          f.FeePercent,
+			Notes:               f.Notes,
+			Status:              f.Status,
+			BookedAppointmentId: f.BookedAppointmentId,
+		})
+	}
+	for _, v := range data.VideoSessions {
+		b.VideoSessions = append(b.VideoSessions, videoSession{
+			RoomId:              v.VideoTreatmentId,
+			AppointmentId:       v.AppointmentId,
+			Status:              v.Status,
+			StartedAt:           v.StartedAt,
+			EndedAt:             v.EndedAt,
+			ConsultationSeconds: v.ConsultationSeconds,
+			PatientJoined:       v.PatientJoined,
+			PatientNoShow:       v.PatientNoShow,
+		})
+	}
+	for _, p := range data.VideoParticipants {
+		b.VideoParticipants = append(b.VideoParticipants, videoParticipant{
+			RoomId:   p.VideoTreatmentId,
+			JoinedAt: p.JoinedAt,
+			LeftAt:   p.LeftAt,
+		})
+	}
+	for _, r := range data.Ratings {
+		b.Ratings = append(b.Ratings, rating{
+			AppointmentId: r.AppointmentId,
+			DoctorId:      r.DoctorId,
+			Score:         r.Score,
+			Comment:       r.Comment,
+			CreatedAt:     r.CreatedAt,
+		})
+	}
+	for _, p := range data.PromoRedemptions {
+		b.PromoRedemptions = append(b.PromoRedemptions, promoRedemption{
+			Code:           p.Code,
+			AppointmentId:  p.AppointmentId,
+			OriginalAmount: p.OriginalAmount,
+			DiscountAmount: p.DiscountAmount,
+			FinalAmount:    p.FinalAmount,
+			CreatedAt:      p.CreatedAt,
+		})
+	}
+	for _, e := range data.AuditEntries {
+		b.AuditEntries = append(b.AuditEntries, auditEntry{
+			CreatedAt:     e.CreatedAt,
+			ActorId:       e.ActorId,
+			ActorRole:     e.ActorRole,
+			Action:        e.Action,
+			AppointmentId: e.AppointmentId,
+			Before:        rawJSON(e.Before),
+			After:         rawJSON(e.After),
+		})
+	}
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// rawJSON embeds a stored snapshot as JSON, leaving out empty or unreadable ones
+func rawJSON(s string) json.RawMessage {
+	if s == "" || !json.Valid([]byte(s)) {
+		return nil
+	}
+	return json.RawMessage(s)
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Reports that can be exported
+const (
+	Appointments            = "appointments"
+	Cancellations           = "cancellations"
+	RevenueBySpecialization = "revenue_by_specialization"
+	DoctorUtilisation       = "doctor_utilisation"
+)
+
+// Columns of each report. Changing these changes the files finance and
+// operations import, so add columns at the end.
+var (
+	AppointmentColumns = []Column{
+		{Name: "appointment_id", Numeric: true},
+		{Name: "appointment_time"},
+		{Name: "patient_id"},
+		{Name: "doctor_id"},
+		{Name: "specialization_id", Numeric: true},
+		{Name: "type"},
+		{Name: "status"},
+		{Name: "payment_mode"},
+		{Name: "payment_status"},
+		{Name: "amount", Numeric: true},
+		{Name: "currency"},
+	}
+	CancellationColumns = []Column{
+		{Name: "appointment_id", Numeric: true},
+		{Name: "appointment_time"},
+		{Name: "cancelled_at"},
+		{Name: "patient_id"},
+		{Name: "doctor_id"},
+		{Name: "specialization_id", Numeric: true},
+		{Name: "cancelled_by"},
+		{Name: "reason"},
+		{Name: "amount", Numeric: true},
+		{Name: "currency"},
+	}
+	RevenueBySpecializationColumns = []Column{
+		{Name: "specialization"},
+		{Name: "appointments", Numeric: true},
+		{Name: "cancelled", Numeric: true},
+		{Name: "no_shows", Numeric: true},
+		{Name: "revenue", Numeric: true},
+	}
+	DoctorUtilisationColumns = []Column{
+		{Name: "doctor_id"},
+		{Name: "booked_slots", Numeric: true},
+		{Name: "available_slots", Numeric: true},
+		{Name: "utilisation", Numeric: true},
+	}
+)
+
+// ColumnsFor returns the columns of a report, or false for an unknown report
+func ColumnsFor(report string) ([]Column, bool) {
+	switch report {
+	case Appointments:
+		return AppointmentColumns, true
+	case Cancellations:
+		return CancellationColumns, true
+	case RevenueBySpecialization:
+		return RevenueBySpecializationColumns, true
+	case DoctorUtilisation:
+		return DoctorUtilisationColumns, true
+	}
+	return nil, false
+}
+
+func AppointmentRow(a domain.Appointment) []string {
+	return []string{
+		strconv.Itoa(a.AppointmentId),
+		formatTime(a.AppointmentTime),
+		a.PatientId,
+		a.DoctorId,
+		strconv.Itoa(int(a.SpecializationId)),
+		a.Type,
+		a.Status,
+		a.PaymentMode,
+		a.PaymentStatus,
+		formatAmount(a.Amount),
+		a.Currency,
+	}
+}
+
+func CancellationRow(a domain.Appointment) []string {
+	return []string{
+		strconv.Itoa(a.AppointmentId),
+		formatTime(a.AppointmentTime),
+		formatTime(a.UpdatedAt),
+		a.PatientId,
+		a.DoctorId,
+		strconv.Itoa(int(a.SpecializationId)),
+		a.CancelledBy,
+		a.CancelReason,
+		formatAmount(a.Amount),
+		a.Currency,
+	}
+}
+
+func RevenueBySpecializationRow(s domain.SpecializationStats) []string {
+	return []string{
+		s.Name,
+		strconv.Itoa(s.Count),
+		strconv.Itoa(s.Cancelled),
+		strconv.Itoa(s.NoShows),
+		formatAmount(s.Revenue),
+	}
+}
+
+func DoctorUtilisationRow(d domain.DoctorUtilisation) []string {
+	return []string{
+		d.DoctorId,
+		strconv.Itoa(d.BookedSlots),
+		strconv.Itoa(d.AvailableSlots),
+		strconv.FormatFloat(d.Utilisation, 'f', 4, 64),
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w), columns: columns}
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := c.w.Write(header); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvWriter) WriteRow(values []string) error {
+	if len(values) != len(c.columns) {
+		return errors.New("row does not match report columns")
+	}
+	row := make([]string, len(values))
+	for i, v := range values {
+		if c.columns[i].Numeric {
+			row[i] = v
+		} else {
+			row[i] = escapeFormula(v)
+		}
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula stops spreadsheet apps from evaluating free text such as a
+// cancellation reason as a formula
+func escapeFormula(v string) string {
+	if v == "" {
+		return v
+	}
+	switch v[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + v
+	}
+	return v
+}
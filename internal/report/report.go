@@ -0,0 +1,45 @@
+// Package report writes tabular exports as CSV or XLSX. Rows are written as
+// they are produced so a large report never has to fit in memory.
+package report
+
+import (
+	"errors"
+	"io"
+)
+
+// Supported output formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Column is one column of a report. Numeric columns are written as numbers in XLSX.
+type Column struct {
+	Name    string
+	Numeric bool
+}
+
+// Writer writes the rows of one report. Close must be called to finish the file.
+type Writer interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// NewWriter starts a report in the given format, writing the header row straight away
+func NewWriter(format string, w io.Writer, sheet string, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet, columns)
+	}
+	return nil, errors.New("format must be csv or xlsx")
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
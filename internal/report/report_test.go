@@ -0,0 +1,110 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testRows returns the rows of each report as the service would write them
+func testRows(kind string) [][]string {
+	at := time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC)
+	appointments := []domain.Appointment{
+		{AppointmentId: 101, AppointmentTime: at, PatientId: "P-1", DoctorId: "D-1", SpecializationId: 3, Type: "video", Status: "completed", PaymentMode: "online", PaymentStatus: "paid", Amount: 500, Currency: "INR"},
+		{AppointmentId: 102, AppointmentTime: at.Add(time.Hour), PatientId: "P-2", DoctorId: "D-2", SpecializationId: 4, Type: "in-person", Status: "cancelled", PaymentMode: "clinic", PaymentStatus: "waived", Currency: "INR", CancelledBy: "patient", CancelReason: `Travel, "urgent" <work>`},
+	}
+	appointments[1].UpdatedAt = at.Add(-24 * time.Hour)
+
+	var rows [][]string
+	switch kind {
+	case Appointments:
+		for _, a := range appointments {
+			rows = append(rows, AppointmentRow(a))
+		}
+	case Cancellations:
+		rows = append(rows, CancellationRow(appointments[1]))
+	case RevenueBySpecialization:
+		rows = append(rows,
+			RevenueBySpecializationRow(domain.SpecializationStats{Name: "General Medicine", Count: 12, Cancelled: 2, NoShows: 1, Revenue: 4500.5}),
+			RevenueBySpecializationRow(domain.SpecializationStats{Name: "Dermatology", Count: 3}),
+		)
+	case DoctorUtilisation:
+		rows = append(rows,
+			DoctorUtilisationRow(domain.DoctorUtilisation{DoctorId: "D-1", BookedSlots: 30, AvailableSlots: 77, Utilisation: 30.0 / 77}),
+			DoctorUtilisationRow(domain.DoctorUtilisation{DoctorId: "D-2"}),
+		)
+	}
+	return rows
+}
+
+func render(t *testing.T, kind, format string) []byte {
+	t.Helper()
+	columns, ok := ColumnsFor(kind)
+	if !ok {
+		t.Fatalf("no columns for %s", kind)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, kind, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range testRows(kind) {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReportGolden(t *testing.T) {
+	for _, kind := range []string{Appointments, Cancellations, RevenueBySpecialization, DoctorUtilisation} {
+		for _, format := range []string{FormatCSV, FormatXLSX} {
+			t.Run(kind+"."+format, func(t *testing.T) {
+				got := render(t, kind, format)
+				if !bytes.Equal(got, render(t, kind, format)) {
+					t.Fatal("writing the same report twice gave different bytes")
+				}
+
+				golden := filepath.Join("testdata", kind+"."+format+".golden")
+				if *update {
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run go test -update to create it)", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("output differs from %s; run go test -update if the change is intended", golden)
+				}
+			})
+		}
+	}
+}
+
+func TestWriterRejectsMismatchedRows(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		w, err := NewWriter(format, &buf, Appointments, AppointmentColumns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRow([]string{"1"}); err == nil {
+			t.Errorf("%s: expected an error for a short row", format)
+		}
+	}
+	if _, err := NewWriter("pdf", &bytes.Buffer{}, Appointments, AppointmentColumns); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
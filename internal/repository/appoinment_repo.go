@@ -1,78 +1,165 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AppointmentRepository interface {
-	IsDoctorAvailable(doctorId string, patientId string, reqTime time.Time, duration time.Duration) (bool, string, string, error)
-	ConfirmAppointment(appointment domain.Appointment) error
-	CancelAppointment(appointment domain.Appointment, reason string) (string, error)
-	GetLatestAppointmentId() (int, error)
-	FetchAppointmentsByPatient(patientId string) ([]domain.Appointment, error)
-	CheckVideoAppoitment(patientId string) (bool, domain.Appointment, error)
-	SaveVideoAppointment(roomid string, appointmentid, specializationId int) error
-	GetAppointmentDetails(orderid string) (domain.Appointment, error)
-	FetchTodayAppointments() ([]domain.Appointment, error)
-	CreateSpecialization(specialize domain.Specialization) (string, error)
-	GetSpecializationStats(param string) ([]domain.SpecializationStats, error)
-	GetTotalAppointment(param string) (int, error)
+	IsDoctorAvailable(ctx context.Context, doctorId string, patientId string, reqTime time.Time, duration time.Duration, parentAppointmentId int) error
+	ConfirmAppointment(ctx context.Context, appointment domain.Appointment) error
+	CancelAppointment(ctx context.Context, appointment domain.Appointment, reason string) (string, error)
+	ExpirePendingAppointments(ctx context.Context, createdBefore, now time.Time) (int, error)
+	GetLatestAppointmentId(ctx context.Context) (int, error)
+	FetchAppointmentsByPatient(ctx context.Context, patientId string, from time.Time) ([]domain.Appointment, error)
+	CheckVideoAppoitment(ctx context.Context, patientId, doctorId string, specializationId int32) (bool, domain.Appointment, error)
+	SaveVideoAppointment(ctx context.Context, roomid string, appointmentid, specializationId int) (domain.VideoTreatment, bool, error)
+	GetAppointmentDetails(ctx context.Context, orderid string) (domain.Appointment, error)
+	FetchTodayAppointments(ctx context.Context) ([]domain.Appointment, error)
+	CreateSpecialization(ctx context.Context, specialize domain.Specialization) (string, error)
+	GetSpecializationStats(ctx context.Context, from, to time.Time) ([]domain.SpecializationStats, error)
+	GetTotalAppointment(ctx context.Context, from, to time.Time) (int, error)
+	CompleteAppointment(ctx context.Context, consultation domain.Consultation) (domain.Appointment, error)
+	FetchConsultationsByPatient(ctx context.Context, patientId string) ([]domain.Consultation, error)
+	GetConsultationByAppointment(ctx context.Context, appointmentId int) (domain.Consultation, domain.Appointment, error)
+	DoctorTreatsPatient(ctx context.Context, doctorId, patientId string) (bool, error)
+	GetAppointmentById(ctx context.Context, appointmentId int) (domain.Appointment, error)
+	CreateFollowUp(ctx context.Context, followUp domain.FollowUp) (domain.FollowUp, error)
+	GetFollowUp(ctx context.Context, followUpId uint) (domain.FollowUp, error)
+	BookFollowUpAppointment(ctx context.Context, followUpId uint, appointment domain.Appointment, charge func(*domain.Appointment) error) error
+	GetFollowUpAdherence(ctx context.Context, from, to time.Time) ([]domain.FollowUpAdherence, error)
+	GetVideoTreatment(ctx context.Context, roomId string) (domain.VideoTreatment, error)
+	JoinVideoTreatment(ctx context.Context, roomId, role string, at time.Time) (domain.VideoTreatment, error)
+	EndVideoTreatment(ctx context.Context, roomId string, endedAt time.Time, consultationSeconds int) (domain.VideoTreatment, error)
+	MarkVideoPatientNotified(ctx context.Context, roomId string, at time.Time) error
+	AddVideoParticipant(ctx context.Context, participant domain.VideoParticipant) error
+	CloseVideoParticipant(ctx context.Context, roomId, participantId string, leftAt time.Time) error
+	FetchVideoParticipants(ctx context.Context, roomId string) ([]domain.VideoParticipant, error)
+	FetchStaleVideoSessions(ctx context.Context, now time.Time) ([]domain.VideoTreatment, error)
+	UpdateAppointmentStatus(ctx context.Context, appointmentId int, from, status string) error
+	GetVideoTreatmentByAppointment(ctx context.Context, appointmentId int) (domain.VideoTreatment, error)
+	ListSpecializations(ctx context.Context, includeArchived bool) ([]domain.Specialization, error)
+	GetSpecialization(ctx context.Context, id uint, slug string) (domain.Specialization, error)
+	UpdateSpecialization(ctx context.Context, specialize domain.Specialization) (domain.Specialization, error)
+	SetSpecializationActive(ctx context.Context, id uint, active bool) error
+	UpsertConsultationPrice(ctx context.Context, price domain.ConsultationPrice) (domain.ConsultationPrice, error)
+	ListConsultationPrices(ctx context.Context, specializationId int32) ([]domain.ConsultationPrice, error)
+	DeleteConsultationPrice(ctx context.Context, id uint) error
+	CreatePriceSurcharge(ctx context.Context, surcharge domain.PriceSurcharge) (domain.PriceSurcharge, error)
+	ListPriceSurcharges(ctx context.Context, specializationId int32) ([]domain.PriceSurcharge, error)
+	DeletePriceSurcharge(ctx context.Context, id uint) error
+	GetPricing(ctx context.Context, specializationId int32, doctorId string) ([]domain.ConsultationPrice, []domain.PriceSurcharge, error)
+	CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	GetPromoCode(ctx context.Context, code string) (domain.PromoCode, error)
+	ListPromoCodes(ctx context.Context, includeInactive bool) ([]domain.PromoCode, error)
+	DeactivatePromoCode(ctx context.Context, code string) error
+	CountPromoRedemptions(ctx context.Context, promoCodeId uint, patientId string) (int, int, error)
+	FetchPromoRedemptions(ctx context.Context, code string, from, to time.Time) ([]domain.PromoRedemption, error)
+	ReservePromoRedemption(ctx context.Context, redemption domain.PromoRedemption) (domain.PromoRedemption, error)
+	ReleasePromoRedemption(ctx context.Context, redemptionId uint) error
+	FetchClaims(ctx context.Context, payerId string, from, to time.Time) ([]domain.Appointment, error)
+	RecordClinicPayment(ctx context.Context, appointmentId int, amount float64, method, collectedBy string) (domain.Appointment, error)
+	GetRevenueStats(ctx context.Context, from, to time.Time) (domain.StatisticsData, error)
+	GetStatsSeries(ctx context.Context, from, to time.Time, interval string) ([]domain.StatsBucket, error)
+	GetDoctorBookedSlots(ctx context.Context, from, to time.Time) ([]domain.DoctorUtilisation, error)
+	GetDoctorPerformance(ctx context.Context, doctorId string, from, to time.Time) ([]domain.DoctorPerformance, error)
+	RebuildRollups(ctx context.Context) error
+	FetchAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	FetchAuditChain(ctx context.Context, afterId uint, limit int) ([]domain.AuditEntry, error)
+	RecordAudit(ctx context.Context, entry domain.AuditEntry) error
+	FetchAuditHead(ctx context.Context) (domain.AuditEntry, error)
+	SaveAuditAnchor(ctx context.Context, anchor domain.AuditAnchor) error
+	FetchAuditAnchors(ctx context.Context) ([]domain.AuditAnchor, error)
+	FetchPatientData(ctx context.Context, patientId string) (domain.PatientData, error)
+	ErasePatientData(ctx context.Context, patientId, pseudonym string) (domain.PatientErasure, error)
+	GetRollupSpecializationStats(ctx context.Context, from, to time.Time) ([]domain.SpecializationStats, error)
+	GetRollupTotal(ctx context.Context, from, to time.Time) (int, error)
+	CreateRating(ctx context.Context, rating domain.AppointmentRating) (domain.AppointmentRating, error)
+	SearchAppointments(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, string, error)
+	FetchVisitOutcomes(ctx context.Context, appointmentIds []int) ([]domain.Consultation, []domain.VideoTreatment, error)
 }
 type appointmentRepository struct {
 	db *gorm.DB
+	// clinicZone is the time zone the statistics rollups cut days in
+	clinicZone *time.Location
 }
 
-func NewAppoinmentRepository(db *gorm.DB) AppointmentRepository {
+func NewAppoinmentRepository(db *gorm.DB, clinicZone *time.Location) AppointmentRepository {
 	return &appointmentRepository{
-		db: db,
+		db:         db,
+		clinicZone: clinicZone,
 	}
 }
-func (r *appointmentRepository) IsDoctorAvailable(doctorId string, patientId string, reqTime time.Time, duration time.Duration) (bool, string, string, error) {
+
+// IsDoctorAvailable returns nil when the doctor is free for the slot and the
+// patient has no other booking with them that day, otherwise a typed error
+// carrying the pending payment link or a suggested slot
+func (r *appointmentRepository) IsDoctorAvailable(ctx context.Context, doctorId string, patientId string, reqTime time.Time, duration time.Duration, parentAppointmentId int) error {
 	var appointment domain.Appointment
 
-	// Check if the patient has already booked an appointment that day
-	err := r.db.Model(&domain.Appointment{}).
-		Where("doctor_id = ? AND patient_id = ? AND DATE(appointment_time) = DATE(?)", doctorId, patientId, reqTime).
-		First(&appointment).Error
+	// Check if the patient has already booked an appointment that day; a
+	// cancelled or expired booking no longer counts
+	dailyQuery := r.db.WithContext(ctx).Model(&domain.Appointment{}).
+		Where("doctor_id = ? AND patient_id = ? AND DATE(appointment_time) = DATE(?) AND status <> ?", doctorId, patientId, reqTime, "cancelled")
+	if parentAppointmentId != 0 {
+		// A follow-up may fall on the same day as the visit it follows up on
+		dailyQuery = dailyQuery.Where("appointment_id <> ?", parentAppointmentId)
+	}
+	err := dailyQuery.First(&appointment).Error
 	if err == nil {
+		reason := map[string]string{"appointment_id": strconv.Itoa(appointment.AppointmentId)}
 		if appointment.Status == "Pending" {
-			return false, fmt.Sprintf("https://%s/api/v1/payment?orderId=%s", os.Getenv("IP_ADDRESS"), appointment.PaymentId), fmt.Sprintf("you have already booked an appointment for this day (%v) but not completed the payment so Please complete payment using belove URL and confirm your shedule!", appointment.AppointmentTime), nil
+			return apperr.FailedPrecondition(fmt.Sprintf("you have already booked an appointment for this day (%v) but not completed the payment so Please complete payment using belove URL and confirm your shedule!", appointment.AppointmentTime)).
+				WithReason("PAYMENT_PENDING", reason).
+				WithPaymentURL(fmt.Sprintf("https://%s/api/v1/payment?orderId=%s", os.Getenv("IP_ADDRESS"), appointment.PaymentId))
 		}
-		return false, "", "", fmt.Errorf("you have already booked an appointment for this day (%v)", appointment.AppointmentTime)
+		return apperr.Conflict(fmt.Sprintf("you have already booked an appointment for this day (%v)", appointment.AppointmentTime)).
+			WithReason("ALREADY_BOOKED_TODAY", reason)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
 	}
 
 	// Check if there's an available slot for the requested time considering the duration
 	var overlappingCount int64
-	err = r.db.Model(&domain.Appointment{}).
-		Where("doctor_id = ? AND appointment_time <= ? AND appointment_time + interval '1 hour' >= ?", doctorId, reqTime, reqTime.Add(duration)).
+	err = r.db.WithContext(ctx).Model(&domain.Appointment{}).
+		Where("doctor_id = ? AND status <> ? AND appointment_time <= ? AND appointment_time + interval '1 hour' >= ?", doctorId, "cancelled", reqTime, reqTime.Add(duration)).
 		Count(&overlappingCount).Error
 	if err != nil {
-		return false, "", "", err
+		return err
 	}
 
 	// Return if there's a free slot
 	if overlappingCount == 0 && isWithinWorkingHours(reqTime) {
-		return true, "", "", nil
+		return nil
 	}
 
 	// If no available slot, suggest the closest available slot with a 4-hour gap
-	suggestedTime := suggestAlternativeSlot(r.db, doctorId, reqTime)
+	suggestedTime, err := suggestAlternativeSlot(r.db.WithContext(ctx), doctorId, reqTime)
+	if err != nil {
+		return err
+	}
 	if suggestedTime.IsZero() {
-		return false, "", "No available slots within working hours", nil
+		return apperr.Conflict("No available slots within working hours").WithReason("SLOT_UNAVAILABLE", nil)
 	}
-	return false, "", "No slot available. Suggested next slot: " + suggestedTime.Format("3:04 PM"), nil
+	return apperr.Conflict("No slot available. Suggested next slot: "+suggestedTime.Format("3:04 PM")).
+		WithReason("SLOT_UNAVAILABLE", nil).
+		WithSuggestedSlots(suggestedTime)
 }
 func isWithinWorkingHours(reqTime time.Time) bool {
 	hour := reqTime.Hour()
-	return hour >= 8 && hour < 19
+	return hour >= domain.WorkdayStartHour && hour < domain.WorkdayEndHour
 }
-func suggestAlternativeSlot(db *gorm.DB, doctorId string, reqTime time.Time) time.Time {
+func suggestAlternativeSlot(db *gorm.DB, doctorId string, reqTime time.Time) (time.Time, error) {
 	const gapHours = 4
 	for {
 		reqTime = reqTime.Add(time.Hour * gapHours)
@@ -84,39 +171,126 @@ func suggestAlternativeSlot(db *gorm.DB, doctorId string, reqTime time.Time) tim
 		// Check if the new time has an available slot
 		var overlappingCount int64
 		err := db.Model(&domain.Appointment{}).
-			Where("doctor_id = ? AND appointment_time <= ? AND appointment_time + interval '1 hour' >= ?", doctorId, reqTime, reqTime).
+			Where("doctor_id = ? AND status <> ? AND appointment_time <= ? AND appointment_time + interval '1 hour' >= ?", doctorId, "cancelled", reqTime, reqTime).
 			Count(&overlappingCount).Error
-
-		if err == nil && overlappingCount == 0 {
-			return reqTime
+		if err != nil {
+			return time.Time{}, err
+		}
+		if overlappingCount == 0 {
+			return reqTime, nil
 		}
 	}
 }
 
 // ConfirmAppointment saves the confirmed appointment in the database
-func (r *appointmentRepository) ConfirmAppointment(appointment domain.Appointment) error {
-	return r.db.Create(&appointment).Error
+func (r *appointmentRepository) ConfirmAppointment(ctx context.Context, appointment domain.Appointment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&appointment).Error; err != nil {
+			return err
+		}
+		if err := appendAudit(tx, audit.NewEntry(ctx, audit.ActionCreate, appointment.AppointmentId, nil, appointment)); err != nil {
+			return err
+		}
+		return r.refreshRollup(tx, appointment)
+	})
 }
-func (r *appointmentRepository) CancelAppointment(appointment domain.Appointment, reason string) (string, error) {
-	if err := r.db.Where("appointment_id=? AND patient_id=?", appointment.AppointmentId, appointment.PatientId).First(&appointment).Error; err != nil {
-		return "", errors.New("appointment not found")
+
+// CancelAppointment cancels as the doctor when DoctorId is set, otherwise as the patient
+func (r *appointmentRepository) CancelAppointment(ctx context.Context, appointment domain.Appointment, reason string) (string, error) {
+	query := r.db.WithContext(ctx).Where("appointment_id=? AND patient_id=?", appointment.AppointmentId, appointment.PatientId)
+	cancelledBy := "patient"
+	if appointment.DoctorId != "" {
+		query = r.db.WithContext(ctx).Where("appointment_id=? AND doctor_id=?", appointment.AppointmentId, appointment.DoctorId)
+		cancelledBy = "doctor"
 	}
-	if appointment.Status == "pending" {
-		return "", errors.New("this appointment payment not completed")
+	if err := query.First(&appointment).Error; err != nil {
+		return "", apperr.NotFound("appointment not found")
+	}
+	if appointment.Status == "cancelled" {
+		return "", apperr.FailedPrecondition("this appointment is already cancelled").WithReason("ALREADY_CANCELLED", nil)
+	}
+	if appointment.Status == "Pending" || appointment.Status == "pending" {
+		return "", apperr.FailedPrecondition("this appointment payment not completed").WithReason("PAYMENT_PENDING", nil)
 	} else if !appointment.AppointmentTime.After(time.Now()) {
-		return "", errors.New("this appointment is already started")
+		return "", apperr.FailedPrecondition("this appointment is already started").WithReason("APPOINTMENT_STARTED", nil)
 	}
-	if err := r.db.Model(&appointment).Update("status", "cancelled").Error; err !=
-		nil {
+	before := appointment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&appointment).Updates(map[string]interface{}{
+			"status":        "cancelled",
+			"cancelled_by":  cancelledBy,
+			"cancel_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := releasePromoRedemptions(tx, appointment.AppointmentId); err != nil {
+			return err
+		}
+		if err := reopenFollowUp(tx, appointment.AppointmentId); err != nil {
+			return err
+		}
+		if err := appendAudit(tx, audit.NewEntry(ctx, audit.ActionCancel, appointment.AppointmentId, before, appointment)); err != nil {
+			return err
+		}
+		return r.refreshRollup(tx, appointment)
+	})
+	if err != nil {
 		return "", err
 	}
 	return "Appointment cancelled successfully", nil
 }
-func (r *appointmentRepository) GetLatestAppointmentId() (int, error) {
+
+// ExpirePendingAppointments cancels bookings still waiting for online payment
+// that were made before createdBefore or whose slot has started by now, and
+// releases their promo redemptions and follow-ups. It returns how many were expired.
+func (r *appointmentRepository) ExpirePendingAppointments(ctx context.Context, createdBefore, now time.Time) (int, error) {
+	expired := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var appointments []domain.Appointment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND (created_at < ? OR appointment_time < ?)", []string{"Pending", "pending"}, createdBefore, now).
+			Find(&appointments).Error
+		if err != nil {
+			return err
+		}
+		for _, appointment := range appointments {
+			before := appointment
+			if err := tx.Model(&appointment).Updates(map[string]interface{}{
+				"status":        "cancelled",
+				"cancelled_by":  "system",
+				"cancel_reason": "payment not completed",
+			}).Error; err != nil {
+				return err
+			}
+			if err := releasePromoRedemptions(tx, appointment.AppointmentId); err != nil {
+				return err
+			}
+			if err := reopenFollowUp(tx, appointment.AppointmentId); err != nil {
+				return err
+			}
+			if err := appendAudit(tx, audit.NewEntry(ctx, audit.ActionCancel, appointment.AppointmentId, before, appointment)); err != nil {
+				return err
+			}
+			if err := r.refreshRollup(tx, appointment); err != nil {
+				return err
+			}
+		}
+		expired = len(appointments)
+
+		// Reservations left behind when the booking itself was never saved
+		return tx.Where("created_at < ? AND NOT EXISTS (SELECT 1 FROM appointments WHERE appointments.appointment_id = promo_redemptions.appointment_id)", createdBefore).
+			Delete(&domain.PromoRedemption{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
+func (r *appointmentRepository) GetLatestAppointmentId(ctx context.Context) (int, error) {
 	var latestAppointment domain.Appointment
 
 	// Query the database for the latest appointment by ID
-	if err := r.db.Order("appointment_id desc").First(&latestAppointment).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("appointment_id desc").First(&latestAppointment).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// If no appointments exist, start from 0
 			return 0, nil
@@ -126,78 +300,112 @@ func (r *appointmentRepository) GetLatestAppointmentId() (int, error) {
 
 	return latestAppointment.AppointmentId, nil
 }
-func (r *appointmentRepository) FetchAppointmentsByPatient(patientId string) ([]domain.Appointment, error) {
+
+// FetchAppointmentsByPatient returns the patient's appointments from the given time on
+func (r *appointmentRepository) FetchAppointmentsByPatient(ctx context.Context, patientId string, from time.Time) ([]domain.Appointment, error) {
 	var appointments []domain.Appointment
-	err := r.db.Where("patient_id = ?", patientId).Order("appointment_time ASC").Find(&appointments).Error
+	err := r.db.WithContext(ctx).Where("patient_id = ? AND appointment_time >= ?", patientId, from).Order("appointment_time ASC").Find(&appointments).Error
 	if err != nil {
 		return nil, err
 	}
 	return appointments, nil
 }
-func (r *appointmentRepository) CheckVideoAppoitment(patientId string) (bool, domain.Appointment, error) {
+
+// CheckVideoAppoitment finds the patient's nearest upcoming video appointment with the doctor
+func (r *appointmentRepository) CheckVideoAppoitment(ctx context.Context, patientId, doctorId string, specializationId int32) (bool, domain.Appointment, error) {
 	var appointment domain.Appointment
-	err := r.db.Where("patient_id =? AND type = ? AND appointment_time >?", patientId, "video", time.Now()).First(&appointment).Error
+	query := r.db.WithContext(ctx).Where("patient_id = ? AND doctor_id = ? AND type = ? AND status <> ? AND appointment_time + interval '1 hour' > ?", patientId, doctorId, domain.AppointmentTypeVideo, "cancelled", time.Now())
+	if specializationId != 0 {
+		query = query.Where("specialization_id = ?", specializationId)
+	}
+	err := query.Order("appointment_time ASC").First(&appointment).Error
 	if err != nil {
-		return false, domain.Appointment{}, errors.New("patient appointment not found")
+		return false, domain.Appointment{}, apperr.NotFound("patient appointment not found")
 	}
 	return true, appointment, nil
 }
-func (r *appointmentRepository) SaveVideoAppointment(roomid string, appointmentid, specializationId int) error {
-	appointment := domain.VideoTreatment{
-		VideoTreatmentId: roomid,
-		AppointmentId:    appointmentid,
-	}
-	if err := r.db.Create(&appointment).Error; err != nil {
-		return err
-	}
-	return nil
 
+// SaveVideoAppointment creates the room for an appointment once; later calls return the existing room and false
+func (r *appointmentRepository) SaveVideoAppointment(ctx context.Context, roomid string, appointmentid, specializationId int) (domain.VideoTreatment, bool, error) {
+	var videoTreatment domain.VideoTreatment
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the appointment so concurrent calls cannot both create a room
+		var appointment domain.Appointment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("appointment_id = ?", appointmentid).First(&appointment).Error; err != nil {
+			return apperr.NotFound("appointment not found")
+		}
+		err := tx.Where("appointment_id = ?", appointmentid).Order("id ASC").First(&videoTreatment).Error
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		videoTreatment = domain.VideoTreatment{
+			VideoTreatmentId: roomid,
+			AppointmentId:    appointmentid,
+			Status:           "created",
+		}
+		created = true
+		if err := tx.Create(&videoTreatment).Error; err != nil {
+			return err
+		}
+		return appendAudit(tx, audit.NewEntry(ctx, audit.ActionRoomCreate, appointmentid, nil, videoTreatment))
+	})
+	if err != nil {
+		return domain.VideoTreatment{}, false, err
+	}
+	return videoTreatment, created, nil
 }
-func (r *appointmentRepository) GetAppointmentDetails(orderid string) (domain.Appointment, error) {
+func (r *appointmentRepository) GetAppointmentDetails(ctx context.Context, orderid string) (domain.Appointment, error) {
 	var appointment domain.Appointment
-	err := r.db.Where("order_id = ?", orderid).First(&appointment).Error
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderid).First(&appointment).Error
 	if err != nil {
 		return domain.Appointment{}, err
 	}
 	return appointment, nil
 }
-func (r *appointmentRepository) FetchTodayAppointments() ([]domain.Appointment, error) {
+func (r *appointmentRepository) FetchTodayAppointments(ctx context.Context) ([]domain.Appointment, error) {
 	var appointments []domain.Appointment
-	err := r.db.Where("appointment_time >= ? AND appointment_time <= ?", time.Now(), time.Now().Add(12*time.Hour)).Find(&appointments).Error
+	err := r.db.WithContext(ctx).Where("appointment_time >= ? AND appointment_time <= ?", time.Now(), time.Now().Add(12*time.Hour)).Find(&appointments).Error
 	if err != nil {
 		return nil, err
 	}
 	return appointments, nil
 
 }
-func (r *appointmentRepository) CreateSpecialization(specialize domain.Specialization) (string, error) {
-	if err := r.db.Create(&specialize).Error; err != nil {
+func (r *appointmentRepository) CreateSpecialization(ctx context.Context, specialize domain.Specialization) (string, error) {
+	if err := r.checkSpecializationConflict(ctx, specialize); err != nil {
 		return "Category is already exist", err
 	}
+	if err := r.db.WithContext(ctx).Create(&specialize).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return "Category is already exist", apperr.Conflict("specialization with this name already exists")
+		}
+		return "Failed to create category", err
+	}
 	return "Category created successfully", nil
 }
-func (r *appointmentRepository) GetSpecializationStats(param string) ([]domain.SpecializationStats, error) {
+func (r *appointmentRepository) GetSpecializationStats(ctx context.Context, from, to time.Time) ([]domain.SpecializationStats, error) {
 	var results []struct {
 		SpecializationName string
 		AppointmentCount   int32
+		Cancelled          int32
+		NoShows            int32
+		Revenue            float64
 	}
 
 	// Start building the base query
-	query := r.db.Table("appointments").
-		Select("specializations.name as specialization_name, COUNT(appointments.id) as appointment_count").
+	query := r.db.WithContext(ctx).Table("appointments").
+		Select(`specializations.name as specialization_name, COUNT(appointments.id) as appointment_count,
+			COUNT(*) FILTER (WHERE appointments.status = 'cancelled') as cancelled,
+			COUNT(*) FILTER (WHERE appointments.status IN ?) as no_shows,
+			COALESCE(SUM(appointments.amount) FILTER (WHERE appointments.status NOT IN ?), 0) as revenue`, noShowStatuses, unbilledStatuses).
 		Joins("JOIN specializations ON appointments.specialization_id = specializations.id").
-		Group("specializations.name")
-
-	// Add conditional filters based on the `param` value
-	switch param {
-	case "day":
-		query = query.Where("DATE(appointments.appointment_time) = CURRENT_DATE")
-	case "week":
-		query = query.Where("appointment_time >= CURRENT_DATE - INTERVAL '7 days'")
-	case "month":
-		query = query.Where("appointment_time >= CURRENT_DATE - INTERVAL '1 month'")
-		// "all" and "default" cases don’t need a `WHERE` clause, as they include all data
-	}
+		Where("appointments.deleted_at IS NULL").
+		Group("specializations.name").
+		Order("appointment_count DESC")
+	query = inWindow(query, "appointments.appointment_time", from, to)
 
 	// Execute the query and handle any errors
 	if err := query.Scan(&results).Error; err != nil {
@@ -208,32 +416,24 @@ func (r *appointmentRepository) GetSpecializationStats(param string) ([]domain.S
 	var specializationStats []domain.SpecializationStats
 	for _, r := range results {
 		specializationStats = append(specializationStats, domain.SpecializationStats{
-			Name:  r.SpecializationName,
-			Count: int(r.AppointmentCount),
+			Name:      r.SpecializationName,
+			Count:     int(r.AppointmentCount),
+			Cancelled: int(r.Cancelled),
+			NoShows:   int(r.NoShows),
+			Revenue:   r.Revenue,
 		})
 	}
 	return specializationStats, nil
 }
-func (r *appointmentRepository) GetTotalAppointment(param string) (int, error) {
+func (r *appointmentRepository) GetTotalAppointment(ctx context.Context, from, to time.Time) (int, error) {
 	var totalAppointment int64 // int64 for GORM Count compatibility
 
-	query := r.db.Model(&domain.Appointment{})
-
-	// Apply filter based on the 'param' argument
-	switch param {
-	case "day":
-		query = query.Where("DATE_TRUNC('day', appointment_time) = DATE_TRUNC('day', CURRENT_TIMESTAMP)")
-	case "week":
-		query = query.Where("DATE_TRUNC('week', appointment_time) = DATE_TRUNC('week', CURRENT_TIMESTAMP)")
-	case "month":
-		query = query.Where("DATE_TRUNC('month', appointment_time) = DATE_TRUNC('month', CURRENT_TIMESTAMP)")
-	}
+	query := inWindow(r.db.WithContext(ctx).Model(&domain.Appointment{}), "appointment_time", from, to)
 
 	// Count the total number of appointments
 	if err := query.Count(&totalAppointment).Error; err != nil {
 		return 0, err
 	}
 
-	fmt.Printf("Fetching total appointments filtered by: %v, Value: %v\n", param, totalAppointment)
 	return int(totalAppointment), nil
 }
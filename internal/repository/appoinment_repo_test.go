@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// An expired booking is cancelled by ExpirePendingAppointments; rebooking its
+// slot must not see it, neither as the patient's booking that day nor as an
+// overlapping appointment of the doctor
+func TestExpiredBookingFreesItsSlot(t *testing.T) {
+	r, statements := dryRunRepo(t)
+	// Dry runs find nothing, as if the only booking of the slot were cancelled
+	notFound := func(tx *gorm.DB) {
+		if tx.Statement.RaiseErrorOnNotFound {
+			tx.AddError(gorm.ErrRecordNotFound)
+		}
+	}
+	if err := r.db.Callback().Query().After("test:capture").Register("test:not_found", notFound); err != nil {
+		t.Fatal(err)
+	}
+
+	slot := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	if err := r.IsDoctorAvailable(context.Background(), "d1", "p1", slot, time.Hour, 0); err != nil {
+		t.Fatalf("rebooking the slot: %v", err)
+	}
+	if _, err := suggestAlternativeSlot(r.db, "d1", slot); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*statements) != 3 {
+		t.Fatalf("ran %d statements, want the same-day, overlap and suggestion queries", len(*statements))
+	}
+	for _, sql := range *statements {
+		if !strings.Contains(sql, "status <> 'cancelled'") {
+			t.Errorf("cancelled bookings still block the slot:\n%s", sql)
+		}
+	}
+}
+
+// Cancelling or expiring a follow-up booking hands the recommendation back
+func TestReopenFollowUp(t *testing.T) {
+	r, statements := dryRunRepo(t)
+	if err := reopenFollowUp(r.db, 7); err != nil {
+		t.Fatal(err)
+	}
+	if len(*statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(*statements))
+	}
+	sql := (*statements)[0]
+	for _, want := range []string{`"booked_appointment_id"=0`, `"status"='recommended'`, "booked_appointment_id = 7 AND status = 'booked'"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("missing %q:\n%s", want, sql)
+		}
+	}
+}
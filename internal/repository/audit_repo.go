@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// appendAudit links the entry to the end of the chain and saves it. It runs
+// inside the caller's transaction so the entry is recorded with the change,
+// and holds the chain lock until that transaction ends.
+//
+// The lock is global: every audited write, bookings and cancellations
+// included, and every recorded read waits for the transaction ahead of it to
+// commit. Audited transactions must stay short and make no outside calls
+// after appending.
+func appendAudit(tx *gorm.DB, entry domain.AuditEntry) error {
+	// Serialise appends so every entry links to the one saved before it
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "audit:chain").Error; err != nil {
+		return err
+	}
+	var last domain.AuditEntry
+	err := tx.Select("hash").Order("id DESC").First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	entry = audit.Seal(entry, last.Hash, time.Now())
+	return tx.Create(&entry).Error
+}
+
+// FetchAuditEntries returns the entries matching the filter, oldest first, starting after filter.AfterId
+func (r *appointmentRepository) FetchAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := r.db.WithContext(ctx).Where("id > ?", filter.AfterId)
+	if filter.AppointmentId != 0 {
+		query = query.Where("appointment_id = ?", filter.AppointmentId)
+	}
+	if filter.ActorId != "" {
+		query = query.Where("actor_id = ?", filter.ActorId)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	query = inWindow(query, "created_at", filter.From, filter.To)
+
+	var entries []domain.AuditEntry
+	if err := query.Order("id ASC").Limit(filter.Limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FetchAuditChain returns up to limit entries of the whole chain after afterId, in chain order
+func (r *appointmentRepository) FetchAuditChain(ctx context.Context, afterId uint, limit int) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	if err := r.db.WithContext(ctx).Where("id > ?", afterId).Order("id ASC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RecordAudit appends an entry that has no change of its own to go with, such as a read
+func (r *appointmentRepository) RecordAudit(ctx context.Context, entry domain.AuditEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return appendAudit(tx, entry)
+	})
+}
+
+// FetchAuditHead returns the last entry of the chain
+func (r *appointmentRepository) FetchAuditHead(ctx context.Context) (domain.AuditEntry, error) {
+	var head domain.AuditEntry
+	if err := r.db.WithContext(ctx).Order("id DESC").First(&head).Error; err != nil {
+		return domain.AuditEntry{}, err
+	}
+	return head, nil
+}
+
+// SaveAuditAnchor stores an anchor; anchoring the same entry again is a no-op
+func (r *appointmentRepository) SaveAuditAnchor(ctx context.Context, anchor domain.AuditAnchor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entry_id"}},
+		DoNothing: true,
+	}).Create(&anchor).Error
+}
+
+// FetchAuditAnchors returns every anchor in chain order
+func (r *appointmentRepository) FetchAuditAnchors(ctx context.Context) ([]domain.AuditAnchor, error) {
+	var anchors []domain.AuditAnchor
+	if err := r.db.WithContext(ctx).Order("entry_id ASC").Find(&anchors).Error; err != nil {
+		return nil, err
+	}
+	return anchors, nil
+}
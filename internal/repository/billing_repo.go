@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// FetchClaims returns the appointments billed to a payer, oldest first
+func (r *appointmentRepository) FetchClaims(ctx context.Context, payerId string, from, to time.Time) ([]domain.Appointment, error) {
+	var claims []domain.Appointment
+	query := r.db.WithContext(ctx).Where("claim_ref <> ''").Order("appointment_time ASC")
+	if payerId != "" {
+		query = query.Where("payer_id = ?", payerId)
+	}
+	if !from.IsZero() {
+		query = query.Where("appointment_time >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("appointment_time < ?", to)
+	}
+	if err := query.Find(&claims).Error; err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// checkCompletable allows completing only a confirmed appointment whose slot has started
+func checkCompletable(appointment domain.Appointment, now time.Time) error {
+	switch appointment.Status {
+	case "confirmed":
+	case "Pending", "pending":
+		return apperr.FailedPrecondition("this appointment payment not completed").WithReason("PAYMENT_PENDING", nil)
+	case "completed":
+		return apperr.FailedPrecondition("this appointment is already completed")
+	default:
+		return apperr.FailedPrecondition("this appointment is "+appointment.Status).WithReason("STATUS_CHANGED", map[string]string{"status": appointment.Status})
+	}
+	if appointment.AppointmentTime.After(now) {
+		return apperr.FailedPrecondition("this appointment has not started yet").WithReason("APPOINTMENT_NOT_STARTED", nil)
+	}
+	return nil
+}
+
+// CompleteAppointment stores the consultation notes and prescriptions and marks the appointment completed
+func (r *appointmentRepository) CompleteAppointment(ctx context.Context, consultation domain.Consultation) (domain.Appointment, error) {
+	var appointment domain.Appointment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the row so a cancellation cannot slip in before the status changes
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("appointment_id = ? AND doctor_id = ?", consultation.AppointmentId, consultation.DoctorId).
+			First(&appointment).Error
+		if err != nil {
+			return apperr.NotFound("appointment not found")
+		}
+		if err := checkCompletable(appointment, time.Now()); err != nil {
+			return err
+		}
+
+		consultation.PatientId = appointment.PatientId
+		for i := range consultation.Prescriptions {
+			consultation.Prescriptions[i].AppointmentId = appointment.AppointmentId
+			consultation.Prescriptions[i].PatientId = appointment.PatientId
+			consultation.Prescriptions[i].DoctorId = appointment.DoctorId
+		}
+		if err := tx.Create(&consultation).Error; err != nil {
+			return err
+		}
+		before := appointment
+		if err := tx.Model(&appointment).Update("status", "completed").Error; err != nil {
+			return err
+		}
+		if err := appendAudit(tx, audit.NewEntry(ctx, audit.ActionComplete, appointment.AppointmentId, before, appointment)); err != nil {
+			return err
+		}
+		return r.refreshRollup(tx, appointment)
+	})
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	return appointment, nil
+}
+
+// FetchConsultationsByPatient returns the patient's consultations with their prescriptions, newest first
+func (r *appointmentRepository) FetchConsultationsByPatient(ctx context.Context, patientId string) ([]domain.Consultation, error) {
+	var consultations []domain.Consultation
+	err := r.db.WithContext(ctx).Preload("Prescriptions").Where("patient_id = ?", patientId).Order("created_at DESC").Find(&consultations).Error
+	if err != nil {
+		return nil, err
+	}
+	return consultations, nil
+}
+
+// DoctorTreatsPatient reports whether the doctor has an appointment with the patient that was not cancelled
+func (r *appointmentRepository) DoctorTreatsPatient(ctx context.Context, doctorId, patientId string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Appointment{}).
+		Where("doctor_id = ? AND patient_id = ? AND status <> ?", doctorId, patientId, "cancelled").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetConsultationByAppointment loads a completed consultation together with its appointment and specialization
+func (r *appointmentRepository) GetConsultationByAppointment(ctx context.Context, appointmentId int) (domain.Consultation, domain.Appointment, error) {
+	var consultation domain.Consultation
+	if err := r.db.WithContext(ctx).Preload("Prescriptions").Where("appointment_id = ?", appointmentId).First(&consultation).Error; err != nil {
+		return domain.Consultation{}, domain.Appointment{}, apperr.NotFound("consultation not found for this appointment")
+	}
+	var appointment domain.Appointment
+	if err := r.db.WithContext(ctx).Preload("Specialization").Where("appointment_id = ?", appointmentId).First(&appointment).Error; err != nil {
+		return domain.Consultation{}, domain.Appointment{}, apperr.NotFound("appointment not found")
+	}
+	return consultation, appointment, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+func TestCheckCompletable(t *testing.T) {
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status string
+		at     time.Time
+		reason string
+		ok     bool
+	}{
+		{"confirmed and started", "confirmed", now.Add(-30 * time.Minute), "", true},
+		{"confirmed at its start", "confirmed", now, "", true},
+		{"confirmed but in the future", "confirmed", now.Add(time.Hour), "APPOINTMENT_NOT_STARTED", false},
+		{"unpaid", "Pending", now.Add(-30 * time.Minute), "PAYMENT_PENDING", false},
+		{"cancelled", "cancelled", now.Add(-30 * time.Minute), "STATUS_CHANGED", false},
+		{"doctor no-show", "doctor_no_show", now.Add(-30 * time.Minute), "STATUS_CHANGED", false},
+		{"already completed", "completed", now.Add(-30 * time.Minute), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCompletable(domain.Appointment{Status: tt.status, AppointmentTime: tt.at}, now)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			if apperr.KindOf(err) != apperr.KindFailedPrecondition || apperr.Reason(err) != tt.reason {
+				t.Fatalf("got %v (reason %q), want failed precondition %q", err, apperr.Reason(err), tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+func (r *appointmentRepository) GetAppointmentById(ctx context.Context, appointmentId int) (domain.Appointment, error) {
+	var appointment domain.Appointment
+	if err := r.db.WithContext(ctx).Where("appointment_id = ?", appointmentId).First(&appointment).Error; err != nil {
+		return domain.Appointment{}, apperr.NotFound("appointment not found")
+	}
+	return appointment, nil
+}
+func (r *appointmentRepository) CreateFollowUp(ctx context.Context, followUp domain.FollowUp) (domain.FollowUp, error) {
+	var existing domain.FollowUp
+	err := r.db.WithContext(ctx).Where("parent_appointment_id = ?", followUp.ParentAppointmentId).First(&existing).Error
+	if err == nil {
+		return domain.FollowUp{}, apperr.Conflict("a follow-up is already recommended for this appointment")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.FollowUp{}, err
+	}
+	if err := r.db.WithContext(ctx).Create(&followUp).Error; err != nil {
+		return domain.FollowUp{}, err
+	}
+	return followUp, nil
+}
+func (r *appointmentRepository) GetFollowUp(ctx context.Context, followUpId uint) (domain.FollowUp, error) {
+	var followUp domain.FollowUp
+	if err := r.db.WithContext(ctx).First(&followUp, followUpId).Error; err != nil {
+		return domain.FollowUp{}, apperr.NotFound("follow-up not found")
+	}
+	return followUp, nil
+}
+
+// BookFollowUpAppointment saves the follow-up appointment and links it, guarding against the recommendation being used twice.
+// charge, when set, runs once the follow-up is claimed and before the appointment is saved, so a booking that
+// loses the race never creates a payment order.
+func (r *appointmentRepository) BookFollowUpAppointment(ctx context.Context, followUpId uint, appointment domain.Appointment, charge func(*domain.Appointment) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.FollowUp{}).
+			Where("id = ? AND status = ?", followUpId, "recommended").
+			Updates(map[string]interface{}{"status": "booked", "booked_appointment_id": appointment.AppointmentId})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperr.FailedPrecondition("follow-up is already booked")
+		}
+		if charge != nil {
+			if err := charge(&appointment); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&appointment).Error; err != nil {
+			return err
+		}
+		if err := appendAudit(tx, audit.NewEntry(ctx, audit.ActionCreate, appointment.AppointmentId, nil, appointment)); err != nil {
+			return err
+		}
+		return r.refreshRollup(tx, appointment)
+	})
+}
+
+// reopenFollowUp puts the follow-up an appointment was booked from back to
+// recommended when that appointment is cancelled or expires, so the patient
+// can book it again within its window
+func reopenFollowUp(tx *gorm.DB, appointmentId int) error {
+	return tx.Model(&domain.FollowUp{}).
+		Where("booked_appointment_id = ? AND status = ?", appointmentId, "booked").
+		Updates(map[string]interface{}{"status": "recommended", "booked_appointment_id": 0}).Error
+}
+
+// GetFollowUpAdherence reports per doctor how many follow-ups recommended in the period were booked, attended or missed
+func (r *appointmentRepository) GetFollowUpAdherence(ctx context.Context, from, to time.Time) ([]domain.FollowUpAdherence, error) {
+	var adherence []domain.FollowUpAdherence
+	err := r.db.WithContext(ctx).Table("follow_ups").
+		Select(`follow_ups.doctor_id,
+			COUNT(*) AS recommended,
+			COUNT(*) FILTER (WHERE follow_ups.status = 'booked') AS booked,
+			COUNT(*) FILTER (WHERE appointments.status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE follow_ups.status = 'recommended' AND follow_ups.window_end < NOW()) AS missed`).
+		Joins("LEFT JOIN appointments ON appointments.appointment_id = follow_ups.booked_appointment_id AND follow_ups.booked_appointment_id <> 0").
+		Where("follow_ups.deleted_at IS NULL AND follow_ups.created_at >= ? AND follow_ups.created_at < ?", from, to).
+		Group("follow_ups.doctor_id").
+		Order("follow_ups.doctor_id").
+		Scan(&adherence).Error
+	if err != nil {
+		return nil, err
+	}
+	return adherence, nil
+}
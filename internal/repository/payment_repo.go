@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordClinicPayment marks a pay-at-clinic appointment as paid
+func (r *appointmentRepository) RecordClinicPayment(ctx context.Context, appointmentId int, amount float64, method, collectedBy string) (domain.Appointment, error) {
+	var appointment domain.Appointment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("appointment_id = ?", appointmentId).
+			First(&appointment).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.NotFound("appointment not found")
+			}
+			return err
+		}
+		if appointment.PaymentMode != domain.PaymentModePayAtClinic {
+			return errors.New("appointment is not a pay at clinic booking")
+		}
+		if appointment.Status == "cancelled" {
+			return errors.New("appointment is cancelled")
+		}
+		if appointment.PaymentStatus != domain.PaymentStatusDue {
+			return apperr.FailedPrecondition("payment has already been recorded")
+		}
+		if amount < appointment.Amount {
+			return fmt.Errorf("collected amount is less than the %.2f %s due", appointment.Amount, appointment.Currency)
+		}
+		before := appointment
+		now := time.Now()
+		appointment.PaymentStatus = domain.PaymentStatusPaid
+		appointment.CollectedAmount = amount
+		appointment.CollectionMethod = method
+		appointment.CollectedBy = collectedBy
+		appointment.CollectedAt = &now
+		if err := tx.Model(&appointment).Select("PaymentStatus", "CollectedAmount", "CollectionMethod", "CollectedBy", "CollectedAt").Updates(&appointment).Error; err != nil {
+			return err
+		}
+		return appendAudit(tx, audit.NewEntry(ctx, audit.ActionPaymentCollected, appointment.AppointmentId, before, appointment))
+	})
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	return appointment, nil
+}
+
+// GetRevenueStats sums what bookings in the period are expected to bring in
+// and what the front desk has collected and still has to collect
+func (r *appointmentRepository) GetRevenueStats(ctx context.Context, from, to time.Time) (domain.StatisticsData, error) {
+	var stats domain.StatisticsData
+	query := r.db.WithContext(ctx).Model(&domain.Appointment{}).
+		Select(`COALESCE(SUM(amount) FILTER (WHERE status NOT IN ?), 0) AS expected_revenue,
+			COALESCE(SUM(collected_amount) FILTER (WHERE payment_status = ?), 0) AS clinic_collected,
+			COALESCE(SUM(amount) FILTER (WHERE payment_mode = ? AND payment_status = ? AND status <> 'cancelled'), 0) AS clinic_outstanding`,
+			unbilledStatuses, domain.PaymentStatusPaid, domain.PaymentModePayAtClinic, domain.PaymentStatusDue)
+
+	if err := inWindow(query, "appointment_time", from, to).Scan(&stats).Error; err != nil {
+		return domain.StatisticsData{}, err
+	}
+	return stats, nil
+}
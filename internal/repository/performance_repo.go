@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+// GetDoctorPerformance aggregates appointments, video sessions and ratings per doctor in [from, to).
+// Sessions and ratings are summed per appointment before the join, so an
+// appointment with several of either is still counted, and paid for, once.
+// Cancellations without a recorded side predate cancelled_by and were made by patients.
+func (r *appointmentRepository) GetDoctorPerformance(ctx context.Context, doctorId string, from, to time.Time) ([]domain.DoctorPerformance, error) {
+	var performance []domain.DoctorPerformance
+	query := r.db.WithContext(ctx).Table("appointments").
+		Select(`appointments.doctor_id,
+			COUNT(*) AS booked,
+			COUNT(*) FILTER (WHERE appointments.status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE appointments.status = 'cancelled' AND appointments.cancelled_by = 'doctor') AS cancelled_by_doctor,
+			COUNT(*) FILTER (WHERE appointments.status = 'cancelled' AND COALESCE(appointments.cancelled_by, 'patient') <> 'doctor') AS cancelled_by_patient,
+			COUNT(*) FILTER (WHERE appointments.status = 'doctor_no_show') AS doctor_no_shows,
+			COUNT(*) FILTER (WHERE appointments.status = 'patient_no_show') AS patient_no_shows,
+			COALESCE(SUM(video.sessions), 0)::bigint AS video_sessions,
+			COALESCE(SUM(video.seconds)::float / NULLIF(SUM(video.timed), 0), 0) AS average_consultation_secs,
+			COALESCE(SUM(appointments.amount) FILTER (WHERE appointments.status NOT IN ?), 0) AS revenue,
+			COALESCE(SUM(rating.ratings), 0)::bigint AS ratings,
+			COALESCE(SUM(rating.score)::float / NULLIF(SUM(rating.ratings), 0), 0) AS average_rating`, unbilledStatuses).
+		Joins(`LEFT JOIN (SELECT appointment_id, COUNT(*) AS sessions,
+				SUM(consultation_seconds) FILTER (WHERE consultation_seconds > 0) AS seconds,
+				COUNT(*) FILTER (WHERE consultation_seconds > 0) AS timed
+			FROM video_treatments WHERE deleted_at IS NULL GROUP BY appointment_id) AS video
+			ON video.appointment_id = appointments.appointment_id`).
+		Joins(`LEFT JOIN (SELECT appointment_id, COUNT(*) AS ratings, SUM(score) AS score
+			FROM appointment_ratings WHERE deleted_at IS NULL GROUP BY appointment_id) AS rating
+			ON rating.appointment_id = appointments.appointment_id`).
+		Where("appointments.deleted_at IS NULL").
+		Group("appointments.doctor_id").
+		Order("booked DESC")
+	if doctorId != "" {
+		query = query.Where("appointments.doctor_id = ?", doctorId)
+	}
+	if err := inWindow(query, "appointments.appointment_time", from, to).Scan(&performance).Error; err != nil {
+		return nil, err
+	}
+	return performance, nil
+}
+
+func (r *appointmentRepository) CreateRating(ctx context.Context, rating domain.AppointmentRating) (domain.AppointmentRating, error) {
+	if err := r.db.WithContext(ctx).Create(&rating).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.AppointmentRating{}, apperr.Conflict("this appointment has already been rated")
+		}
+		return domain.AppointmentRating{}, err
+	}
+	return rating, nil
+}
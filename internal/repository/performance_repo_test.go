@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoctorPerformanceQuery(t *testing.T) {
+	r, statements := dryRunRepo(t)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	r.GetDoctorPerformance(context.Background(), "d1", from, from.AddDate(0, 1, 0))
+	if len(*statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(*statements))
+	}
+	sql := strings.Join(strings.Fields((*statements)[0]), " ")
+
+	// Joining the raw session and rating rows repeats each appointment once per
+	// row, inflating booked, revenue and the cancellation counts
+	for _, table := range []string{"video_treatments", "appointment_ratings"} {
+		if regexp.MustCompile(`JOIN "?` + table + `"? ON`).MatchString(sql) {
+			t.Errorf("%s is joined row by row instead of per appointment:\n%s", table, sql)
+		}
+		if !strings.Contains(sql, "FROM "+table+" WHERE deleted_at IS NULL GROUP BY appointment_id") {
+			t.Errorf("%s is not aggregated per appointment before the join:\n%s", table, sql)
+		}
+	}
+	if !strings.Contains(sql, "COALESCE(appointments.cancelled_by, 'patient') <> 'doctor'") {
+		t.Errorf("cancellations without cancelled_by are not counted as the patient's:\n%s", sql)
+	}
+	if !strings.Contains(sql, "appointments.doctor_id = 'd1'") {
+		t.Errorf("doctor filter missing:\n%s", sql)
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertConsultationPrice sets the price for a specialization, or for one doctor in it when DoctorId is set
+func (r *appointmentRepository) UpsertConsultationPrice(ctx context.Context, price domain.ConsultationPrice) (domain.ConsultationPrice, error) {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "specialization_id"}, {Name: "doctor_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"video_fee", "in_clinic_fee", "currency", "updated_at"}),
+	}).Create(&price).Error
+	if err != nil {
+		return domain.ConsultationPrice{}, err
+	}
+	var saved domain.ConsultationPrice
+	if err := r.db.WithContext(ctx).Where("specialization_id = ? AND doctor_id = ?", price.SpecializationId, price.DoctorId).First(&saved).Error; err != nil {
+		return domain.ConsultationPrice{}, err
+	}
+	return saved, nil
+}
+func (r *appointmentRepository) ListConsultationPrices(ctx context.Context, specializationId int32) ([]domain.ConsultationPrice, error) {
+	var prices []domain.ConsultationPrice
+	query := r.db.WithContext(ctx).Order("specialization_id ASC, doctor_id ASC")
+	if specializationId != 0 {
+		query = query.Where("specialization_id = ?", specializationId)
+	}
+	if err := query.Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+func (r *appointmentRepository) DeleteConsultationPrice(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Delete(&domain.ConsultationPrice{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("price not found")
+	}
+	return nil
+}
+func (r *appointmentRepository) CreatePriceSurcharge(ctx context.Context, surcharge domain.PriceSurcharge) (domain.PriceSurcharge, error) {
+	if err := r.db.WithContext(ctx).Create(&surcharge).Error; err != nil {
+		return domain.PriceSurcharge{}, err
+	}
+	return surcharge, nil
+}
+func (r *appointmentRepository) ListPriceSurcharges(ctx context.Context, specializationId int32) ([]domain.PriceSurcharge, error) {
+	var surcharges []domain.PriceSurcharge
+	query := r.db.WithContext(ctx).Order("specialization_id ASC, start_hour ASC")
+	if specializationId != 0 {
+		query = query.Where("specialization_id = ?", specializationId)
+	}
+	if err := query.Find(&surcharges).Error; err != nil {
+		return nil, err
+	}
+	return surcharges, nil
+}
+func (r *appointmentRepository) DeletePriceSurcharge(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&domain.PriceSurcharge{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("surcharge not found")
+	}
+	return nil
+}
+
+// GetPricing loads the base and doctor prices plus the surcharges that can apply to a booking
+func (r *appointmentRepository) GetPricing(ctx context.Context, specializationId int32, doctorId string) ([]domain.ConsultationPrice, []domain.PriceSurcharge, error) {
+	var prices []domain.ConsultationPrice
+	if err := r.db.WithContext(ctx).Where("specialization_id = ? AND doctor_id IN ?", specializationId, []string{"", doctorId}).Find(&prices).Error; err != nil {
+		return nil, nil, err
+	}
+	var surcharges []domain.PriceSurcharge
+	if err := r.db.WithContext(ctx).Where("specialization_id IN ?", []int32{0, specializationId}).Find(&surcharges).Error; err != nil {
+		return nil, nil, err
+	}
+	return prices, surcharges, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FetchPatientData collects every row that belongs to the patient, including soft-deleted ones
+func (r *appointmentRepository) FetchPatientData(ctx context.Context, patientId string) (domain.PatientData, error) {
+	data := domain.PatientData{PatientId: patientId, GeneratedAt: time.Now()}
+	db := r.db.WithContext(ctx).Unscoped()
+
+	if err := db.Where("patient_id = ?", patientId).Order("appointment_time ASC").Find(&data.Appointments).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	appointmentIds := make([]int, 0, len(data.Appointments))
+	for _, appointment := range data.Appointments {
+		appointmentIds = append(appointmentIds, appointment.AppointmentId)
+	}
+
+	if err := db.Preload("Prescriptions", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("patient_id = ?", patientId).Order("created_at ASC").Find(&data.Consultations).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	if err := db.Where("patient_id = ?", patientId).Order("created_at ASC").Find(&data.FollowUps).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	if err := db.Where("appointment_id IN ?", appointmentIds).Order("created_at ASC").Find(&data.VideoSessions).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	if err := db.Where("participant_id = ?", patientId).Order("joined_at ASC").Find(&data.VideoParticipants).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	if err := db.Where("patient_id = ?", patientId).Order("created_at ASC").Find(&data.Ratings).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	if err := db.Where("patient_id = ?", patientId).Order("created_at ASC").Find(&data.PromoRedemptions).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	if err := db.Where("appointment_id IN ? OR actor_id = ? OR patient_id = ?", appointmentIds, patientId, patientId).Order("id ASC").Find(&data.AuditEntries).Error; err != nil {
+		return domain.PatientData{}, err
+	}
+	return data, nil
+}
+
+// ErasePatientData replaces the patient's id with pseudonym wherever rows are
+// kept for statistics, clears free text the patient or staff wrote about
+// them and the payment order ids of their appointments, deletes
+// consultations and prescriptions, and redacts their audit entries. It
+// refuses while the patient still has upcoming appointments.
+func (r *appointmentRepository) ErasePatientData(ctx context.Context, patientId, pseudonym string) (domain.PatientErasure, error) {
+	var erasure domain.PatientErasure
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var appointments []domain.Appointment
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("patient_id = ?", patientId).
+			Find(&appointments).Error
+		if err != nil {
+			return err
+		}
+		appointmentIds := make([]int, 0, len(appointments))
+		for _, appointment := range appointments {
+			if !appointment.DeletedAt.Valid && appointment.AppointmentTime.After(time.Now()) &&
+				appointment.Status != "cancelled" && appointment.Status != "completed" {
+				return apperr.FailedPrecondition("patient has upcoming appointments, cancel them first").WithReason("UPCOMING_APPOINTMENTS", nil)
+			}
+			appointmentIds = append(appointmentIds, appointment.AppointmentId)
+		}
+
+		result := tx.Unscoped().Model(&domain.Appointment{}).Where("patient_id = ?", patientId).
+			Updates(map[string]interface{}{"patient_id": pseudonym, "member_id": "", "cancel_reason": "", "payment_id": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.Appointments = int(result.RowsAffected)
+
+		result = tx.Unscoped().Where("patient_id = ?", patientId).Delete(&domain.Prescription{})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.Prescriptions = int(result.RowsAffected)
+		result = tx.Unscoped().Where("patient_id = ?", patientId).Delete(&domain.Consultation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.Consultations = int(result.RowsAffected)
+
+		result = tx.Unscoped().Model(&domain.FollowUp{}).Where("patient_id = ?", patientId).
+			Updates(map[string]interface{}{"patient_id": pseudonym, "notes": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.FollowUps = int(result.RowsAffected)
+
+		result = tx.Unscoped().Model(&domain.VideoParticipant{}).Where("participant_id = ?", patientId).
+			Update("participant_id", pseudonym)
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.VideoParticipants = int(result.RowsAffected)
+
+		result = tx.Unscoped().Model(&domain.AppointmentRating{}).Where("patient_id = ?", patientId).
+			Updates(map[string]interface{}{"patient_id": pseudonym, "comment": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.Ratings = int(result.RowsAffected)
+
+		result = tx.Unscoped().Model(&domain.PromoRedemption{}).Where("patient_id = ?", patientId).
+			Update("patient_id", pseudonym)
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.PromoRedemptions = int(result.RowsAffected)
+
+		// The hash covers digests of the payload and ids, not the values, so
+		// the chain still verifies once they are cleared or pseudonymised.
+		// Request ids are cleared as they lead to the request's log lines.
+		result = tx.Model(&domain.AuditEntry{}).Where("(appointment_id IN ? OR actor_id = ? OR patient_id = ?) AND NOT redacted", appointmentIds, patientId, patientId).
+			Updates(map[string]interface{}{
+				"before":     "",
+				"after":      "",
+				"request_id": "",
+				"actor_id":   gorm.Expr("CASE WHEN actor_id = ? THEN ? ELSE actor_id END", patientId, pseudonym),
+				"patient_id": gorm.Expr("CASE WHEN patient_id = ? THEN ? ELSE patient_id END", patientId, pseudonym),
+				"redacted":   true,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		erasure.AuditEntries = int(result.RowsAffected)
+
+		return appendAudit(tx, audit.NewEntry(ctx, audit.ActionPatientErase, 0, nil, erasure))
+	})
+	if err != nil {
+		return domain.PatientErasure{}, err
+	}
+	return erasure, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *appointmentRepository) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	if err := r.db.WithContext(ctx).Create(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.PromoCode{}, apperr.Conflict("promo code already exists")
+		}
+		return domain.PromoCode{}, err
+	}
+	return promo, nil
+}
+func (r *appointmentRepository) GetPromoCode(ctx context.Context, code string) (domain.PromoCode, error) {
+	var promo domain.PromoCode
+	if err := r.db.WithContext(ctx).Preload("Specializations").Where("code = ?", code).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.PromoCode{}, apperr.NotFound("promo code not found")
+		}
+		return domain.PromoCode{}, err
+	}
+	return promo, nil
+}
+func (r *appointmentRepository) ListPromoCodes(ctx context.Context, includeInactive bool) ([]domain.PromoCode, error) {
+	var promos []domain.PromoCode
+	query := r.db.WithContext(ctx).Preload("Specializations").Order("created_at DESC")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&promos).Error; err != nil {
+		return nil, err
+	}
+	return promos, nil
+}
+func (r *appointmentRepository) DeactivatePromoCode(ctx context.Context, code string) error {
+	result := r.db.WithContext(ctx).Model(&domain.PromoCode{}).Where("code = ?", code).Update("is_active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("promo code not found")
+	}
+	return nil
+}
+
+// CountPromoRedemptions returns the total redemptions of a promo code and those made by one patient
+func (r *appointmentRepository) CountPromoRedemptions(ctx context.Context, promoCodeId uint, patientId string) (int, int, error) {
+	return countPromoRedemptions(r.db.WithContext(ctx), promoCodeId, patientId)
+}
+func (r *appointmentRepository) FetchPromoRedemptions(ctx context.Context, code string, from, to time.Time) ([]domain.PromoRedemption, error) {
+	var redemptions []domain.PromoRedemption
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if code != "" {
+		query = query.Where("code = ?", code)
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+	if err := query.Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+func countPromoRedemptions(db *gorm.DB, promoCodeId uint, patientId string) (int, int, error) {
+	var counts struct {
+		Total   int
+		Patient int
+	}
+	err := db.Model(&domain.PromoRedemption{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE patient_id = ?) AS patient", patientId).
+		Where("promo_code_id = ?", promoCodeId).
+		Scan(&counts).Error
+	return counts.Total, counts.Patient, err
+}
+
+// ReservePromoRedemption records the redemption on its own, re-checking the
+// limits, so a booking can hold its discount before the payment order exists
+func (r *appointmentRepository) ReservePromoRedemption(ctx context.Context, redemption domain.PromoRedemption) (domain.PromoRedemption, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		saved, err := redeemPromoCode(tx, redemption)
+		redemption = saved
+		return err
+	})
+	if err != nil {
+		return domain.PromoRedemption{}, err
+	}
+	return redemption, nil
+}
+
+// ReleasePromoRedemption gives back a reserved redemption whose booking was never saved
+func (r *appointmentRepository) ReleasePromoRedemption(ctx context.Context, redemptionId uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.PromoRedemption{}, redemptionId).Error
+}
+
+// releasePromoRedemptions gives back the redemptions of a booking that will not take place
+func releasePromoRedemptions(tx *gorm.DB, appointmentId int) error {
+	return tx.Where("appointment_id = ?", appointmentId).Delete(&domain.PromoRedemption{}).Error
+}
+
+// redeemPromoCode locks the promo code row and re-checks its limits before recording the redemption,
+// so concurrent bookings cannot push a code past its global or per-patient limit
+func redeemPromoCode(tx *gorm.DB, redemption domain.PromoRedemption) (domain.PromoRedemption, error) {
+	var promo domain.PromoCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promo, redemption.PromoCodeId).Error; err != nil {
+		return domain.PromoRedemption{}, err
+	}
+	if !promo.IsActive {
+		return domain.PromoRedemption{}, errors.New("promo code is no longer active")
+	}
+	total, byPatient, err := countPromoRedemptions(tx, promo.ID, redemption.PatientId)
+	if err != nil {
+		return domain.PromoRedemption{}, err
+	}
+	if promo.GlobalLimit > 0 && total >= promo.GlobalLimit {
+		return domain.PromoRedemption{}, errors.New("promo code usage limit reached")
+	}
+	if promo.PerPatientLimit > 0 && byPatient >= promo.PerPatientLimit {
+		return domain.PromoRedemption{}, apperr.FailedPrecondition("promo code already used the maximum number of times")
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return domain.PromoRedemption{}, err
+	}
+	return redemption, nil
+}
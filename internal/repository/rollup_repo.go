@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+// rollupSelect aggregates appointments into daily rollup rows. Days are dates
+// in the clinic time zone, passed as the first argument.
+const rollupSelect = `SELECT (appointment_time AT TIME ZONE ?)::date, specialization_id, doctor_id, status, COUNT(*), COALESCE(SUM(amount), 0), NOW()
+	FROM appointments WHERE deleted_at IS NULL`
+
+const rollupGroup = ` GROUP BY 1, 2, 3, 4`
+
+// refreshRollup recomputes the rollup rows of the appointment's day, specialization and doctor.
+// It runs inside the caller's transaction so the rollup moves together with the appointment.
+func (r *appointmentRepository) refreshRollup(tx *gorm.DB, appointment domain.Appointment) error {
+	day := rollupDay(appointment.AppointmentTime, r.zone())
+	// Serialise refreshes of the same key so concurrent bookings cannot insert duplicate rows
+	key := fmt.Sprintf("rollup:%s:%d:%s", day.Format(time.DateOnly), appointment.SpecializationId, appointment.DoctorId)
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+		return err
+	}
+	err := tx.Where("day = ? AND specialization_id = ? AND doctor_id = ?", day.Format(time.DateOnly), appointment.SpecializationId, appointment.DoctorId).
+		Delete(&domain.AppointmentRollup{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Exec(`INSERT INTO appointment_rollups (day, specialization_id, doctor_id, status, appointments, amount, updated_at) `+
+		rollupSelect+` AND doctor_id = ? AND specialization_id = ? AND appointment_time >= ? AND appointment_time < ?`+rollupGroup,
+		r.zone().String(), appointment.DoctorId, appointment.SpecializationId, day.UTC(), day.AddDate(0, 0, 1).UTC()).Error
+}
+
+// refreshRollupById reloads the appointment and refreshes its rollup
+func (r *appointmentRepository) refreshRollupById(tx *gorm.DB, appointmentId int) error {
+	var appointment domain.Appointment
+	if err := tx.Where("appointment_id = ?", appointmentId).First(&appointment).Error; err != nil {
+		return err
+	}
+	return r.refreshRollup(tx, appointment)
+}
+
+// zone is the clinic time zone, UTC when none was configured
+func (r *appointmentRepository) zone() *time.Location {
+	if r.clinicZone == nil {
+		return time.UTC
+	}
+	return r.clinicZone
+}
+
+// rollupDay is midnight in zone of the day t falls on
+func rollupDay(t time.Time, zone *time.Location) time.Time {
+	t = t.In(zone)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, zone)
+}
+
+// rollupDays turns [from, to) into the clinic dates of the rollup rows it
+// covers; a zero bound stays open
+func (r *appointmentRepository) rollupDays(from, to time.Time) (string, string) {
+	var fromDay, toDay string
+	if !from.IsZero() {
+		fromDay = rollupDay(from, r.zone()).Format(time.DateOnly)
+	}
+	if !to.IsZero() {
+		day := rollupDay(to, r.zone())
+		if day.Before(to) {
+			// A window ending mid-day still covers that day
+			day = day.AddDate(0, 0, 1)
+		}
+		toDay = day.Format(time.DateOnly)
+	}
+	return fromDay, toDay
+}
+
+// inDays limits query to rollup rows whose day falls in [from, to); an empty bound is left open
+func inDays(query *gorm.DB, from, to string) *gorm.DB {
+	if from != "" {
+		query = query.Where("day >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("day < ?", to)
+	}
+	return query
+}
+
+// RebuildRollups recomputes every rollup row from the appointments table,
+// catching up with changes made outside this service
+func (r *appointmentRepository) RebuildRollups(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE appointment_rollups IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM appointment_rollups").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO appointment_rollups (day, specialization_id, doctor_id, status, appointments, amount, updated_at) `+rollupSelect+rollupGroup, r.zone().String()).Error
+	})
+}
+
+// GetRollupSpecializationStats reads per-specialization counts for the clinic days in [from, to)
+func (r *appointmentRepository) GetRollupSpecializationStats(ctx context.Context, from, to time.Time) ([]domain.SpecializationStats, error) {
+	var stats []domain.SpecializationStats
+	query := r.db.WithContext(ctx).Table("appointment_rollups").
+		Select(`specializations.name AS name,
+			SUM(appointment_rollups.appointments) AS count,
+			COALESCE(SUM(appointment_rollups.appointments) FILTER (WHERE appointment_rollups.status = 'cancelled'), 0) AS cancelled,
+			COALESCE(SUM(appointment_rollups.appointments) FILTER (WHERE appointment_rollups.status IN ?), 0) AS no_shows,
+			COALESCE(SUM(appointment_rollups.amount) FILTER (WHERE appointment_rollups.status NOT IN ?), 0) AS revenue`, noShowStatuses, unbilledStatuses).
+		Joins("JOIN specializations ON appointment_rollups.specialization_id = specializations.id").
+		Group("specializations.name").
+		Order("count DESC")
+	fromDay, toDay := r.rollupDays(from, to)
+	if err := inDays(query, fromDay, toDay).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetRollupTotal reads the number of appointments for the clinic days in [from, to)
+func (r *appointmentRepository) GetRollupTotal(ctx context.Context, from, to time.Time) (int, error) {
+	var total int
+	query := r.db.WithContext(ctx).Model(&domain.AppointmentRollup{}).Select("COALESCE(SUM(appointments), 0)")
+	fromDay, toDay := r.rollupDays(from, to)
+	if err := inDays(query, fromDay, toDay).Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/stats"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRollupDays(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	tests := []struct {
+		name             string
+		zone             *time.Location
+		from, to         time.Time
+		wantFrom, wantTo string
+	}{
+		{"utc day", time.UTC, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), "2026-03-02", "2026-03-03"},
+		// Midnight in the clinic is still the previous evening in UTC
+		{"clinic day", ist, time.Date(2026, 3, 2, 0, 0, 0, 0, ist), time.Date(2026, 3, 3, 0, 0, 0, 0, ist), "2026-03-02", "2026-03-03"},
+		{"utc bounds of a clinic day", ist, time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC), time.Date(2026, 3, 2, 18, 30, 0, 0, time.UTC), "2026-03-02", "2026-03-03"},
+		{"ends mid-day", time.UTC, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), "2026-03-02", "2026-03-03"},
+		{"open", time.UTC, time.Time{}, time.Time{}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &appointmentRepository{clinicZone: tt.zone}
+			from, to := r.rollupDays(tt.from, tt.to)
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("rollupDays = %q, %q; want %q, %q", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestRollupQueriesUseClinicZone(t *testing.T) {
+	r, statements := dryRunRepo(t)
+	zone, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.clinicZone = zone
+
+	// Early morning in the clinic, still the previous day in UTC
+	r.refreshRollup(r.db, domain.Appointment{DoctorId: "d1", SpecializationId: 3, AppointmentTime: time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)})
+	from, to := stats.Window("day", time.Date(2026, 3, 2, 1, 0, 0, 0, zone))
+	r.GetRollupSpecializationStats(context.Background(), from, to)
+
+	all := strings.Join(*statements, "\n")
+	if !strings.Contains(all, "AT TIME ZONE 'Asia/Kolkata'") {
+		t.Errorf("rollup days are not cut in the clinic zone:\n%s", all)
+	}
+	if !strings.Contains(all, "day = '2026-03-02'") || !strings.Contains(all, "appointment_time >= '2026-03-01 18:30:00' AND appointment_time < '2026-03-02 18:30:00'") {
+		t.Errorf("the refreshed rollup is not the appointment's clinic day:\n%s", all)
+	}
+	if !strings.Contains(all, "day >= '2026-03-02' AND day < '2026-03-03'") {
+		t.Errorf("dashboard window does not read the clinic day:\n%s", all)
+	}
+	if !strings.Contains(all, "appointment_rollups.status NOT IN ('cancelled','pending','Pending')") {
+		t.Errorf("rollup revenue counts unpaid bookings:\n%s", all)
+	}
+}
+
+// BenchmarkDashboardStats compares the dashboard counts read from the rollups
+// with the same counts aggregated from a million appointments. It needs a
+// scratch Postgres database: ROLLUP_BENCH_DSN="postgres://..." go test -bench Dashboard ./internal/repository
+func BenchmarkDashboardStats(b *testing.B) {
+	dsn := os.Getenv("ROLLUP_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("ROLLUP_BENCH_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		b.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		b.Fatal(err)
+	}
+	// One connection, so the scratch schema on the search path applies to every query
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+	for _, stmt := range []string{"DROP SCHEMA IF EXISTS rollup_bench CASCADE", "CREATE SCHEMA rollup_bench", "SET search_path TO rollup_bench"} {
+		if err := db.Exec(stmt).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+	defer db.Exec("DROP SCHEMA IF EXISTS rollup_bench CASCADE")
+	if err := db.AutoMigrate(&domain.Specialization{}, &domain.Appointment{}, &domain.AppointmentRollup{}); err != nil {
+		b.Fatal(err)
+	}
+	seed := []string{
+		`INSERT INTO specializations (id, name, created_at, updated_at)
+			SELECT s, 'specialization ' || s, NOW(), NOW() FROM generate_series(1, 20) AS s`,
+		// A year of appointments across 200 doctors, spread over every status
+		`INSERT INTO appointments (appointment_id, patient_id, doctor_id, specialization_id, appointment_time, status, amount, created_at, updated_at)
+			SELECT n, 'patient-' || (n % 50000), 'doctor-' || (n % 200), 1 + n % 20,
+				TIMESTAMPTZ '2026-01-01' + (n % 525600) * INTERVAL '1 minute',
+				(ARRAY['confirmed','completed','cancelled','pending','doctor_no_show','patient_no_show'])[1 + n % 6],
+				200 + n % 300, NOW(), NOW()
+			FROM generate_series(1, 1000000) AS n`,
+		"ANALYZE appointments",
+	}
+	for _, stmt := range seed {
+		if err := db.Exec(stmt).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+	r := &appointmentRepository{db: db, clinicZone: time.UTC}
+	if err := r.RebuildRollups(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	from, to := stats.Window("month", time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC))
+
+	b.Run("rollups", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := r.GetRollupSpecializationStats(ctx, from, to); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := r.GetRollupTotal(ctx, from, to); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("appointments", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := r.GetSpecializationStats(ctx, from, to); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := r.GetTotalAppointment(ctx, from, to); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// SearchAppointments returns one page of appointments matching the filter,
+// ordered by appointment time then id, and the cursor of the next page (empty
+// on the last page). Paging is keyset based so deep pages stay cheap.
+func (r *appointmentRepository) SearchAppointments(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, string, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Appointment{})
+	if filter.PatientId != "" {
+		query = query.Where("patient_id = ?", filter.PatientId)
+	}
+	if filter.DoctorId != "" {
+		query = query.Where("doctor_id = ?", filter.DoctorId)
+	}
+	if filter.SpecializationId != 0 {
+		query = query.Where("specialization_id = ?", filter.SpecializationId)
+	}
+	if filter.Status != "" {
+		query = query.Where("status IN ?", statusSpellings(filter.Status))
+	}
+	if filter.Type != "" {
+		query = query.Where("type IN ?", typeSpellings(filter.Type))
+	}
+	if filter.PaymentStatus != "" {
+		query = query.Where("payment_status = ?", filter.PaymentStatus)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("appointment_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("appointment_time < ?", filter.To)
+	}
+	if filter.PastOrCancelled {
+		query = query.Where("(appointment_time < ? OR status = ?)", time.Now(), "cancelled")
+	}
+
+	if filter.Cursor != "" {
+		at, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if filter.Descending {
+			query = query.Where("(appointment_time, id) < (?, ?)", at, id)
+		} else {
+			query = query.Where("(appointment_time, id) > (?, ?)", at, id)
+		}
+	}
+	if filter.Descending {
+		query = query.Order("appointment_time DESC, id DESC")
+	} else {
+		query = query.Order("appointment_time ASC, id ASC")
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	var appointments []domain.Appointment
+	if err := query.Limit(filter.Limit + 1).Find(&appointments).Error; err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(appointments) > filter.Limit {
+		appointments = appointments[:filter.Limit]
+		last := appointments[len(appointments)-1]
+		next = encodeCursor(last.AppointmentTime, last.ID)
+	}
+	return appointments, next, nil
+}
+
+// statusSpellings lists every way a status is stored. Statuses are written
+// in lower case, except pending bookings made before that, which read
+// "Pending". Matching the stored values keeps the status index usable.
+func statusSpellings(status string) []string {
+	status = strings.ToLower(status)
+	if status == "pending" {
+		return []string{"pending", "Pending"}
+	}
+	return []string{status}
+}
+
+// typeSpellings lists every way a type is stored. Legacy bookings made
+// before an empty type was read as in-clinic were stored with no type.
+func typeSpellings(appointmentType string) []string {
+	if appointmentType == domain.AppointmentTypeInClinic {
+		return []string{domain.AppointmentTypeInClinic, ""}
+	}
+	return []string{appointmentType}
+}
+
+func encodeCursor(at time.Time, id uint) string {
+	raw := strconv.FormatInt(at.UnixNano(), 10) + ":" + strconv.FormatUint(uint64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, uint, error) {
+	invalid := errors.New("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+	nanos, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, invalid
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+	return time.Unix(0, n), uint(id), nil
+}
+
+// FetchVisitOutcomes loads the consultations, with prescriptions, and video sessions of the given appointments
+func (r *appointmentRepository) FetchVisitOutcomes(ctx context.Context, appointmentIds []int) ([]domain.Consultation, []domain.VideoTreatment, error) {
+	if len(appointmentIds) == 0 {
+		return nil, nil, nil
+	}
+	var consultations []domain.Consultation
+	if err := r.db.WithContext(ctx).Preload("Prescriptions").Where("appointment_id IN ?", appointmentIds).Find(&consultations).Error; err != nil {
+		return nil, nil, err
+	}
+	var sessions []domain.VideoTreatment
+	if err := r.db.WithContext(ctx).Where("appointment_id IN ?", appointmentIds).Find(&sessions).Error; err != nil {
+		return nil, nil, err
+	}
+	return consultations, sessions, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatusSpellings(t *testing.T) {
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{"pending", []string{"pending", "Pending"}},
+		{"Pending", []string{"pending", "Pending"}},
+		{"confirmed", []string{"confirmed"}},
+		{"Cancelled", []string{"cancelled"}},
+	}
+	for _, tt := range tests {
+		if got := statusSpellings(tt.status); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("statusSpellings(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestTypeSpellings(t *testing.T) {
+	if got := typeSpellings("in-clinic"); !reflect.DeepEqual(got, []string{"in-clinic", ""}) {
+		t.Errorf("typeSpellings(in-clinic) = %v", got)
+	}
+	if got := typeSpellings("video"); !reflect.DeepEqual(got, []string{"video"}) {
+		t.Errorf("typeSpellings(video) = %v", got)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 123456000, time.UTC)
+	gotAt, gotId, err := decodeCursor(encodeCursor(at, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotAt.Equal(at) || gotId != 42 {
+		t.Errorf("decoded %v %d, want %v 42", gotAt, gotId, at)
+	}
+	if _, _, err := decodeCursor("not a cursor"); err == nil {
+		t.Error("a malformed cursor should be rejected")
+	}
+}
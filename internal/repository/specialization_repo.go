@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+// checkSpecializationConflict reports another specialization, archived or not, already using the name or slug
+func (r *appointmentRepository) checkSpecializationConflict(ctx context.Context, specialize domain.Specialization) error {
+	var existing domain.Specialization
+	query := r.db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", specialize.Name)
+	if specialize.Slug != "" {
+		query = query.Or("slug = ?", specialize.Slug)
+	}
+	err := r.db.WithContext(ctx).Where(query).Where("id <> ?", specialize.ID).First(&existing).Error
+	if err == nil {
+		if existing.Slug == specialize.Slug && specialize.Slug != "" {
+			return apperr.Conflict("specialization with this slug already exists")
+		}
+		return apperr.Conflict("specialization with this name already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+func (r *appointmentRepository) ListSpecializations(ctx context.Context, includeArchived bool) ([]domain.Specialization, error) {
+	var specializations []domain.Specialization
+	query := r.db.WithContext(ctx).Order("display_order ASC, name ASC")
+	if !includeArchived {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&specializations).Error; err != nil {
+		return nil, err
+	}
+	return specializations, nil
+}
+
+// GetSpecialization looks a specialization up by id, or by slug when id is zero
+func (r *appointmentRepository) GetSpecialization(ctx context.Context, id uint, slug string) (domain.Specialization, error) {
+	var specialization domain.Specialization
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	if id == 0 {
+		query = r.db.WithContext(ctx).Where("slug = ?", slug)
+	}
+	if err := query.First(&specialization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Specialization{}, apperr.NotFound("specialization not found")
+		}
+		return domain.Specialization{}, err
+	}
+	return specialization, nil
+}
+func (r *appointmentRepository) UpdateSpecialization(ctx context.Context, specialize domain.Specialization) (domain.Specialization, error) {
+	if err := r.checkSpecializationConflict(ctx, specialize); err != nil {
+		return domain.Specialization{}, err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.Specialization{}).Where("id = ?", specialize.ID).
+		Select("name", "description", "slug", "icon", "display_order").
+		Updates(&specialize)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return domain.Specialization{}, apperr.Conflict("specialization with this name already exists")
+		}
+		return domain.Specialization{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.Specialization{}, apperr.NotFound("specialization not found")
+	}
+	return r.GetSpecialization(ctx, specialize.ID, "")
+}
+
+// SetSpecializationActive archives or restores a specialization
+func (r *appointmentRepository) SetSpecializationActive(ctx context.Context, id uint, active bool) error {
+	updates := map[string]interface{}{"is_active": active, "archived_at": nil}
+	if !active {
+		updates["archived_at"] = time.Now()
+	}
+	result := r.db.WithContext(ctx).Model(&domain.Specialization{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("specialization not found")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Statuses a video session leaves on an appointment when someone did not join
+var noShowStatuses = []string{"doctor_no_show", "patient_no_show"}
+
+// Statuses whose amount is not revenue: cancelled bookings and those still
+// waiting for their online payment. Everything else was paid online, is due
+// or collected at the clinic, or needs no payment.
+var unbilledStatuses = []string{"cancelled", "pending", "Pending"}
+
+// inWindow limits query to rows whose column falls in [from, to); a zero bound is left open
+func inWindow(query *gorm.DB, column string, from, to time.Time) *gorm.DB {
+	if !from.IsZero() {
+		query = query.Where(column+" >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where(column+" < ?", to)
+	}
+	return query
+}
+
+// GetStatsSeries counts appointments by outcome for each day or week in [from, to).
+// Buckets are cut in UTC so they line up with stats.Buckets over UTC bounds.
+func (r *appointmentRepository) GetStatsSeries(ctx context.Context, from, to time.Time, interval string) ([]domain.StatsBucket, error) {
+	var buckets []domain.StatsBucket
+	query := r.db.WithContext(ctx).Model(&domain.Appointment{}).
+		Select(`DATE_TRUNC(?, appointment_time AT TIME ZONE 'UTC') AS start,
+			COUNT(*) AS appointments,
+			COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled,
+			COUNT(*) FILTER (WHERE status IN ?) AS no_shows,
+			COALESCE(SUM(amount) FILTER (WHERE status NOT IN ?), 0) AS revenue`, interval, noShowStatuses, unbilledStatuses).
+		Group("start").
+		Order("start ASC")
+	if err := inWindow(query, "appointment_time", from, to).Scan(&buckets).Error; err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// GetDoctorBookedSlots counts the slots each doctor had booked in [from, to), cancelled bookings excluded
+func (r *appointmentRepository) GetDoctorBookedSlots(ctx context.Context, from, to time.Time) ([]domain.DoctorUtilisation, error) {
+	var doctors []domain.DoctorUtilisation
+	query := r.db.WithContext(ctx).Model(&domain.Appointment{}).
+		Select("doctor_id, COUNT(*) AS booked_slots").
+		Where("status <> ?", "cancelled").
+		Group("doctor_id").
+		Order("booked_slots DESC")
+	if err := inWindow(query, "appointment_time", from, to).Scan(&doctors).Error; err != nil {
+		return nil, err
+	}
+	return doctors, nil
+}
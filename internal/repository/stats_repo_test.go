@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRevenueLeavesOutUnpaidBookings(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	queries := map[string]func(r *appointmentRepository){
+		"GetStatsSeries": func(r *appointmentRepository) {
+			r.GetStatsSeries(context.Background(), from, to, "day")
+		},
+		"GetSpecializationStats": func(r *appointmentRepository) {
+			r.GetSpecializationStats(context.Background(), from, to)
+		},
+		"GetRevenueStats": func(r *appointmentRepository) {
+			r.GetRevenueStats(context.Background(), from, to)
+		},
+	}
+	for name, query := range queries {
+		t.Run(name, func(t *testing.T) {
+			r, statements := dryRunRepo(t)
+			query(r)
+			if len(*statements) != 1 {
+				t.Fatalf("ran %d statements, want 1", len(*statements))
+			}
+			sql := (*statements)[0]
+			if !strings.Contains(sql, "status NOT IN ('cancelled','pending','Pending'))") {
+				t.Errorf("revenue should leave out cancelled and unpaid bookings:\n%s", sql)
+			}
+			if strings.Contains(sql, "status <> 'cancelled'), 0) AS revenue") || strings.Contains(sql, "status <> 'cancelled'), 0) AS expected_revenue") {
+				t.Errorf("revenue still counts unpaid bookings:\n%s", sql)
+			}
+		})
+	}
+}
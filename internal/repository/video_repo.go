@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *appointmentRepository) GetVideoTreatment(ctx context.Context, roomId string) (domain.VideoTreatment, error) {
+	var videoTreatment domain.VideoTreatment
+	if err := r.db.WithContext(ctx).Where("video_treatment_id = ?", roomId).First(&videoTreatment).Error; err != nil {
+		return domain.VideoTreatment{}, apperr.NotFound("video room not found")
+	}
+	return videoTreatment, nil
+}
+
+// JoinVideoTreatment marks the doctor or patient as joined and the session as
+// started, returning the updated session. Each join sets only its own flag in
+// one statement, so a doctor and patient joining together both count.
+func (r *appointmentRepository) JoinVideoTreatment(ctx context.Context, roomId, role string, at time.Time) (domain.VideoTreatment, error) {
+	var session domain.VideoTreatment
+	result := r.db.WithContext(ctx).Model(&session).Clauses(clause.Returning{}).
+		Where("video_treatment_id = ? AND status <> ?", roomId, "ended").
+		Updates(map[string]interface{}{
+			"status":         "active",
+			role + "_joined": true,
+			"started_at":     gorm.Expr("COALESCE(started_at, ?)", at),
+		})
+	if result.Error != nil {
+		return domain.VideoTreatment{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.VideoTreatment{}, apperr.FailedPrecondition("this video session has already ended")
+	}
+	return session, nil
+}
+
+// EndVideoTreatment ends the session and flags no-shows from the joined flags
+// as they are at that moment, returning the ended session
+func (r *appointmentRepository) EndVideoTreatment(ctx context.Context, roomId string, endedAt time.Time, consultationSeconds int) (domain.VideoTreatment, error) {
+	var session domain.VideoTreatment
+	result := r.db.WithContext(ctx).Model(&session).Clauses(clause.Returning{}).
+		Where("video_treatment_id = ? AND status <> ?", roomId, "ended").
+		Updates(map[string]interface{}{
+			"status":               "ended",
+			"ended_at":             endedAt,
+			"consultation_seconds": consultationSeconds,
+			"doctor_no_show":       gorm.Expr("NOT doctor_joined"),
+			"patient_no_show":      gorm.Expr("doctor_joined AND NOT patient_joined"),
+		})
+	if result.Error != nil {
+		return domain.VideoTreatment{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.VideoTreatment{}, apperr.FailedPrecondition("this video session has already ended")
+	}
+	return session, nil
+}
+
+// MarkVideoPatientNotified records that the patient was sent the room link
+func (r *appointmentRepository) MarkVideoPatientNotified(ctx context.Context, roomId string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.VideoTreatment{}).Where("video_treatment_id = ?", roomId).Update("patient_notified_at", at).Error
+}
+func (r *appointmentRepository) AddVideoParticipant(ctx context.Context, participant domain.VideoParticipant) error {
+	return r.db.WithContext(ctx).Create(&participant).Error
+}
+
+// CloseVideoParticipant sets the leave time on every open stay of the participant in the room
+func (r *appointmentRepository) CloseVideoParticipant(ctx context.Context, roomId, participantId string, leftAt time.Time) error {
+	query := r.db.WithContext(ctx).Model(&domain.VideoParticipant{}).Where("video_treatment_id = ? AND left_at IS NULL", roomId)
+	if participantId != "" {
+		query = query.Where("participant_id = ?", participantId)
+	}
+	return query.Update("left_at", leftAt).Error
+}
+func (r *appointmentRepository) FetchVideoParticipants(ctx context.Context, roomId string) ([]domain.VideoParticipant, error) {
+	var participants []domain.VideoParticipant
+	if err := r.db.WithContext(ctx).Where("video_treatment_id = ?", roomId).Order("joined_at ASC").Find(&participants).Error; err != nil {
+		return nil, err
+	}
+	return participants, nil
+}
+
+// FetchStaleVideoSessions returns sessions that were never ended although the
+// slot of their appointment is over, whatever the appointment's status, so
+// completed visits still get their consultation time recorded
+func (r *appointmentRepository) FetchStaleVideoSessions(ctx context.Context, now time.Time) ([]domain.VideoTreatment, error) {
+	var sessions []domain.VideoTreatment
+	err := r.db.WithContext(ctx).Joins("JOIN appointments ON appointments.appointment_id = video_treatments.appointment_id").
+		Where("video_treatments.status <> ? AND appointments.appointment_time + interval '1 hour' < ?", "ended", now).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// UpdateAppointmentStatus moves the appointment from status from to status,
+// failing with a precondition error when it is no longer in from
+func (r *appointmentRepository) UpdateAppointmentStatus(ctx context.Context, appointmentId int, from, status string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var appointment domain.Appointment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("appointment_id = ?", appointmentId).First(&appointment).Error; err != nil {
+			return err
+		}
+		if appointment.Status != from {
+			return apperr.FailedPrecondition("appointment is no longer "+from).WithReason("STATUS_CHANGED", map[string]string{"status": appointment.Status})
+		}
+		before := appointment
+		if err := tx.Model(&appointment).Where("status = ?", from).Update("status", status).Error; err != nil {
+			return err
+		}
+		if err := appendAudit(tx, audit.NewEntry(ctx, audit.ActionStatusChange, appointmentId, before, appointment)); err != nil {
+			return err
+		}
+		return r.refreshRollupById(tx, appointmentId)
+	})
+}
+func (r *appointmentRepository) GetVideoTreatmentByAppointment(ctx context.Context, appointmentId int) (domain.VideoTreatment, error) {
+	var videoTreatment domain.VideoTreatment
+	err := r.db.WithContext(ctx).Where("appointment_id = ?", appointmentId).Order("id ASC").First(&videoTreatment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The appointment exists; its doctor has not opened the room yet
+		return domain.VideoTreatment{}, apperr.FailedPrecondition("video room is not created yet for this appointment").WithReason("ROOM_NOT_CREATED", nil)
+	} else if err != nil {
+		return domain.VideoTreatment{}, err
+	}
+	return videoTreatment, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVideoTreatmentUpdatesSetOnlyTheirColumns(t *testing.T) {
+	at := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   func(r *appointmentRepository)
+		want    []string
+		notWant []string
+	}{
+		{
+			"join",
+			func(r *appointmentRepository) { r.JoinVideoTreatment(context.Background(), "room-7", "patient", at) },
+			[]string{`"patient_joined"=true`, `"started_at"=COALESCE(started_at, '2026-03-05 10:00:00')`, "status <> 'ended'", "RETURNING *"},
+			[]string{"doctor_joined", "ended_at"},
+		},
+		{
+			"end",
+			func(r *appointmentRepository) { r.EndVideoTreatment(context.Background(), "room-7", at, 600) },
+			[]string{`"doctor_no_show"=NOT doctor_joined`, `"patient_no_show"=doctor_joined AND NOT patient_joined`, "status <> 'ended'", "RETURNING *"},
+			[]string{`"doctor_joined"=`, `"patient_joined"=`, "started_at"},
+		},
+		{
+			"stale sessions",
+			func(r *appointmentRepository) { r.FetchStaleVideoSessions(context.Background(), at) },
+			[]string{"video_treatments.status <> 'ended'"},
+			[]string{"appointments.status"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, statements := dryRunRepo(t)
+			tt.query(r)
+			if len(*statements) != 1 {
+				t.Fatalf("ran %d statements, want 1", len(*statements))
+			}
+			sql := (*statements)[0]
+			for _, want := range tt.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("missing %q:\n%s", want, sql)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(sql, notWant) {
+					t.Errorf("should not contain %q:\n%s", notWant, sql)
+				}
+			}
+		})
+	}
+}
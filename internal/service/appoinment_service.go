@@ -3,34 +3,86 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
-	"os"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/NUHMANUDHEENT/hosp-connect-pb/proto/appointment"
 	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
 	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
 	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/cache"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/di"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/payer"
 	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/repository"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/video"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AppointmentService interface {
-	CheckAvailability(CategoryId int32, reqtime time.Time) ([]domain.Availability, error)
-	CheckAvailabilityByDoctorId(doctorID string) (*appointment.CheckAvailabilityByDoctorIdResponse, error)
-	ConfirmAppointment(appointment domain.Appointment) (string, string, error)
-	CancelAppointment(appointment domain.Appointment, reason string) (string, error)
-	CreateRoomForVideoTreatment(patientId, doctorId string, specializationId int64) (string, error)
-	GetUpcomingAppointments(patientId string) ([]domain.Appointment, error)
-	GetAppointmentDetails(orderid string) (domain.Appointment, error)
+	CheckAvailability(ctx context.Context, CategoryId int32, reqtime time.Time) ([]domain.Availability, error)
+	CheckAvailabilityByDoctorId(ctx context.Context, doctorID string) (*appointment.CheckAvailabilityByDoctorIdResponse, error)
+	ConfirmAppointment(ctx context.Context, appointment domain.Appointment) (string, string, error)
+	BookAppointment(ctx context.Context, appointment domain.Appointment, options domain.BookingOptions) (string, string, error)
+	CancelAppointment(ctx context.Context, appointment domain.Appointment, reason string) (string, error)
+	CreateRoomForVideoTreatment(ctx context.Context, patientId, doctorId string, specializationId int64) (string, error)
+	GetUpcomingAppointments(ctx context.Context, patientId string) ([]domain.Appointment, error)
+	GetAppointmentDetails(ctx context.Context, orderid string) (domain.Appointment, error)
 	SendDialyReminders()
-	AddSpecialization(name, Description string) (string, error)
-	FetchStatisticsDetails(param string) ([]domain.SpecializationStats, domain.StatisticsData, error)
+	AddSpecialization(ctx context.Context, name, Description string) (string, error)
+	FetchStatisticsDetails(ctx context.Context, param string) ([]domain.SpecializationStats, domain.StatisticsData, error)
+	CompleteAppointment(ctx context.Context, consultation domain.Consultation) (string, error)
+	GetPrescriptionHistory(ctx context.Context, patientId string) ([]domain.Consultation, error)
+	GetVisitDocument(ctx context.Context, appointmentId int, patientId, kind, format string) ([]byte, string, error)
+	CreateFollowUp(ctx context.Context, followUp domain.FollowUp) (domain.FollowUp, error)
+	BookFollowUp(ctx context.Context, followUpId uint, patientId string, reqTime time.Time, appointmentType string) (string, string, error)
+	GetFollowUpAdherence(ctx context.Context, from, to time.Time) ([]domain.FollowUpAdherence, error)
+	CreateVideoRoom(ctx context.Context, appointmentId int, doctorId string) (string, error)
+	GetVideoRoom(ctx context.Context, appointmentId int, patientId string) (string, error)
+	ListSpecializations(ctx context.Context, includeArchived bool) ([]domain.Specialization, error)
+	GetSpecialization(ctx context.Context, id uint, slug string) (domain.Specialization, error)
+	UpdateSpecialization(ctx context.Context, specialize domain.Specialization) (domain.Specialization, error)
+	ArchiveSpecialization(ctx context.Context, id uint) error
+	RestoreSpecialization(ctx context.Context, id uint) error
+	GetPriceQuote(ctx context.Context, specializationId int32, doctorId, appointmentType string, at time.Time) (domain.PriceQuote, error)
+	SetConsultationPrice(ctx context.Context, price domain.ConsultationPrice) (domain.ConsultationPrice, error)
+	ListConsultationPrices(ctx context.Context, specializationId int32) ([]domain.ConsultationPrice, error)
+	DeleteConsultationPrice(ctx context.Context, id uint) error
+	AddPriceSurcharge(ctx context.Context, surcharge domain.PriceSurcharge) (domain.PriceSurcharge, error)
+	ListPriceSurcharges(ctx context.Context, specializationId int32) ([]domain.PriceSurcharge, error)
+	DeletePriceSurcharge(ctx context.Context, id uint) error
+	CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error)
+	ListPromoCodes(ctx context.Context, includeInactive bool) ([]domain.PromoCode, error)
+	DeactivatePromoCode(ctx context.Context, code string) error
+	PreviewPromoCode(ctx context.Context, code string, appointment domain.Appointment) (domain.PromoRedemption, error)
+	GetPromoRedemptions(ctx context.Context, code string, from, to time.Time) ([]domain.PromoRedemption, error)
+	ExportClaims(ctx context.Context, payerId string, from, to time.Time) (domain.ClaimExport, error)
+	RecordClinicPayment(ctx context.Context, appointmentId int, amount float64, method, collectedBy string) (domain.Appointment, error)
+	FetchRevenueStatistics(ctx context.Context, param string) (domain.StatisticsData, error)
+	GetStatisticsReport(ctx context.Context, from, to time.Time, interval string) (domain.StatisticsReport, error)
+	CancelAppointmentByDoctor(ctx context.Context, appointmentId int, doctorId, reason string) (string, error)
+	RateAppointment(ctx context.Context, rating domain.AppointmentRating) (domain.AppointmentRating, error)
+	GetDoctorPerformance(ctx context.Context, doctorId string, from, to time.Time) ([]domain.DoctorPerformance, error)
+	RebuildRollups()
+	QueryAuditLog(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, uint, error)
+	VerifyAuditLog(ctx context.Context, anchor domain.AuditAnchor) (domain.AuditVerification, error)
+	AnchorAuditLog()
+	ExportPatientData(ctx context.Context, patientId string) ([]byte, string, error)
+	ErasePatientData(ctx context.Context, patientId string) (domain.PatientErasure, error)
+	ExportReport(ctx context.Context, kind, format string, from, to time.Time, w io.Writer) error
+	SearchAppointments(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, string, error)
+	GetPatientHistory(ctx context.Context, filter domain.AppointmentFilter) ([]domain.PatientVisit, string, error)
+	JoinVideoSession(ctx context.Context, roomId, participantId string) (domain.VideoTreatment, error)
+	LeaveVideoSession(ctx context.Context, roomId, participantId string) error
+	EndVideoSession(ctx context.Context, roomId, participantId string) (domain.VideoTreatment, error)
+	CloseStaleVideoSessions()
+	ExpirePendingAppointments()
 }
 
 type appointmentService struct {
@@ -38,29 +90,68 @@ type appointmentService struct {
 	DoctorClient  doctorpb.DoctorServiceClient
 	PaymentClient paymentpb.PaymentServiceClient
 	PatientClient patientpb.PatientServiceClient
+	VideoProvider video.Provider
+	Payer         payer.Provider
 	Logger        *logrus.Logger
+	countCache    *cache.TTL[int]
+	timeouts      Timeouts
+	// clinicZone is the time zone the legacy day, week and month windows are cut in
+	clinicZone *time.Location
+	// notify publishes appointment events, through Kafka outside tests
+	notify func(ctx context.Context, topic string, event domain.AppointmentEvent) error
 }
 
-func NewAppoinmentService(repo repository.AppointmentRepository, DoctorClient doctorpb.DoctorServiceClient, paymentClient paymentpb.PaymentServiceClient, patientClient patientpb.PatientServiceClient, logger *logrus.Logger) AppointmentService {
+// Timeouts bounds work the service starts itself rather than on behalf of a caller
+type Timeouts struct {
+	// StatsCall bounds each dependency of the statistics dashboard
+	StatsCall time.Duration
+	// Job bounds one run of a scheduled job
+	Job time.Duration
+}
+
+func NewAppoinmentService(repo repository.AppointmentRepository, DoctorClient doctorpb.DoctorServiceClient, paymentClient paymentpb.PaymentServiceClient, patientClient patientpb.PatientServiceClient, videoProvider video.Provider, payerProvider payer.Provider, timeouts Timeouts, clinicZone *time.Location, logger *logrus.Logger) AppointmentService {
+	if timeouts.StatsCall <= 0 {
+		timeouts.StatsCall = defaultStatsCallTimeout
+	}
+	if timeouts.Job <= 0 {
+		timeouts.Job = defaultJobTimeout
+	}
 	return &appointmentService{
 		repo:          repo,
 		DoctorClient:  DoctorClient,
 		PaymentClient: paymentClient,
 		PatientClient: patientClient,
+		VideoProvider: videoProvider,
+		Payer:         payerProvider,
 		Logger:        logger,
+		countCache:    cache.NewTTL[int](countCacheTTL),
+		timeouts:      timeouts,
+		clinicZone:    clinicZone,
+		notify:        di.HandleAppointmentNotification,
+	}
+}
+
+// clinicNow is the current time in the clinic time zone, UTC when none was configured
+func (a *appointmentService) clinicNow() time.Time {
+	if a.clinicZone == nil {
+		return time.Now().UTC()
 	}
+	return time.Now().In(a.clinicZone)
 }
 
-func (a *appointmentService) CheckAvailability(CategoryId int32, reqtime time.Time) ([]domain.Availability, error) {
+func (a *appointmentService) CheckAvailability(ctx context.Context, CategoryId int32, reqtime time.Time) ([]domain.Availability, error) {
 	a.Logger.WithFields(logrus.Fields{
 		"Function":   "CheckAvailability",
 		"CategoryId": CategoryId,
 	}).Info("Checking availability for category")
 
 	availability := []domain.Availability{}
+	if err := a.checkSpecializationActive(ctx, CategoryId); err != nil {
+		return availability, err
+	}
 	reqTimestamp := timestamppb.New(reqtime)
 
-	resp, err := a.DoctorClient.GetAvailability(context.Background(), &doctorpb.GetAvailabilityRequest{
+	resp, err := a.DoctorClient.GetAvailability(ctx, &doctorpb.GetAvailabilityRequest{
 		CategoryId:        CategoryId,
 		RequestedDateTime: reqTimestamp,
 	})
@@ -88,13 +179,13 @@ func (a *appointmentService) CheckAvailability(CategoryId int32, reqtime time.Ti
 	return availability, nil
 }
 
-func (s *appointmentService) CheckAvailabilityByDoctorId(doctorID string) (*appointment.CheckAvailabilityByDoctorIdResponse, error) {
+func (s *appointmentService) CheckAvailabilityByDoctorId(ctx context.Context, doctorID string) (*appointment.CheckAvailabilityByDoctorIdResponse, error) {
 	s.Logger.WithFields(logrus.Fields{
 		"Function": "CheckAvailabilityByDoctorId",
 		"DoctorId": doctorID,
 	}).Info("Checking availability by doctor ID")
 
-	available, err := s.DoctorClient.CheckAvailabilityByDoctorId(context.Background(), &doctorpb.CheckAvailabilityByDoctorIdRequest{
+	available, err := s.DoctorClient.CheckAvailabilityByDoctorId(ctx, &doctorpb.CheckAvailabilityByDoctorIdRequest{
 		DoctorId: doctorID,
 	})
 	if err != nil {
@@ -103,7 +194,7 @@ func (s *appointmentService) CheckAvailabilityByDoctorId(doctorID string) (*appo
 			"DoctorId": doctorID,
 			"Error":    err,
 		}).Error("Failed to check doctor availability")
-		return nil, fmt.Errorf("failed to check doctor availability: %w", err)
+		return nil, apperr.Unavailable("failed to check doctor availability", err)
 	}
 
 	availability := &appointment.CheckAvailabilityByDoctorIdResponse{
@@ -124,120 +215,209 @@ func (s *appointmentService) CheckAvailabilityByDoctorId(doctorID string) (*appo
 	return availability, nil
 }
 
-func (s *appointmentService) ConfirmAppointment(appointment domain.Appointment) (string, string, error) {
+func (s *appointmentService) ConfirmAppointment(ctx context.Context, appointment domain.Appointment) (string, string, error) {
+	return s.BookAppointment(ctx, appointment, domain.BookingOptions{})
+}
+
+// BookAppointment books an appointment, applying the booking options such as a promo code
+func (s *appointmentService) BookAppointment(ctx context.Context, appointment domain.Appointment, options domain.BookingOptions) (string, string, error) {
 	s.Logger.WithFields(logrus.Fields{
-		"Function":        "ConfirmAppointment",
+		"Function":        "BookAppointment",
 		"AppointmentID":   appointment.AppointmentId,
 		"DoctorID":        appointment.DoctorId,
 		"PatientID":       appointment.PatientId,
 		"AppointmentTime": appointment.AppointmentTime,
+		"PromoCode":       options.PromoCode,
+		"PayerId":         options.PayerId,
+		"PaymentMode":     options.PaymentMode,
 	}).Info("Starting appointment confirmation")
 
-	available, err := s.DoctorClient.CheckAvailabilityByDoctorId(context.Background(), &doctorpb.CheckAvailabilityByDoctorIdRequest{
+	if err := s.checkSpecializationActive(ctx, appointment.SpecializationId); err != nil {
+		return "", "", err
+	}
+
+	available, err := s.DoctorClient.CheckAvailabilityByDoctorId(ctx, &doctorpb.CheckAvailabilityByDoctorIdRequest{
 		DoctorId: appointment.DoctorId,
 	})
 	if err != nil {
 		s.Logger.WithFields(logrus.Fields{
-			"Function": "ConfirmAppointment",
+			"Function": "BookAppointment",
 			"DoctorID": appointment.DoctorId,
 			"Error":    err,
 		}).Error("Failed to call doctor service")
-		return "", "failed to call doctor service", err
+		return "", "", apperr.Unavailable("failed to call doctor service", err)
 	}
 
-	for _, v := range available.DoctorAvailability {
-		if v.IsAvailable == "unavailable" {
-			doctorUnavailableDate, err := time.Parse("Mon Jan 2 15:04:05 2006", v.DateTime)
-			if err != nil {
-				return "", "", errors.New("invalid doctor availability date format")
-			}
-
-			if appointment.AppointmentTime.Year() == doctorUnavailableDate.Year() &&
-				appointment.AppointmentTime.Month() == doctorUnavailableDate.Month() &&
-				appointment.AppointmentTime.Day() == doctorUnavailableDate.Day() {
-				return "", "", errors.New("doctor is not available on this date")
-			}
-		}
+	if err := checkDoctorLeave(available.DoctorAvailability, appointment.AppointmentTime); err != nil {
+		return "", "", err
 	}
 
-	isAvailable, url, message, err := s.repo.IsDoctorAvailable(appointment.DoctorId, appointment.PatientId, appointment.AppointmentTime, time.Hour)
-	if err != nil {
+	if err := s.repo.IsDoctorAvailable(ctx, appointment.DoctorId, appointment.PatientId, appointment.AppointmentTime, time.Hour, 0); err != nil {
 		s.Logger.WithFields(logrus.Fields{
-			"Function": "ConfirmAppointment",
+			"Function": "BookAppointment",
 			"DoctorID": appointment.DoctorId,
 			"Error":    err,
-		}).Error("Error in checking doctor availability from repository")
-		return "", "", err
-	}
-	if !isAvailable {
-		s.Logger.WithFields(logrus.Fields{
-			"Function": "ConfirmAppointment",
-			"Message":  message,
 		}).Info("Doctor is not available at requested time")
-		return url, message, nil
+		return "", "", err
 	}
 
-	latestAppointmentId, err := s.repo.GetLatestAppointmentId()
+	latestAppointmentId, err := s.repo.GetLatestAppointmentId(ctx)
 	if err != nil {
 		s.Logger.WithFields(logrus.Fields{
-			"Function": "ConfirmAppointment",
+			"Function": "BookAppointment",
 			"Error":    err,
 		}).Error("Failed to fetch latest appointment ID")
 		return "", "", errors.New("failed to fetch latest appointment ID")
 	}
+	quote, err := s.quotePrice(ctx, appointment.SpecializationId, appointment.DoctorId, appointment.Type, appointment.AppointmentTime)
+	if err != nil {
+		return "", "", err
+	}
+
 	newAppointmentId := latestAppointmentId + 1
 	appointment.AppointmentId = newAppointmentId
 	appointment.Duration = time.Hour
 	appointment.Status = "Pending"
+	appointment.Amount = quote.Amount
+	appointment.Currency = quote.Currency
 
-	Resp, err := s.PaymentClient.CreateRazorOrderId(context.Background(), &paymentpb.CreateRazorOrderIdRequest{
-		PatientId:     appointment.PatientId,
-		Amount:        200,
-		AppointmentId: int64(newAppointmentId),
-		Type:          "appointment fee",
-	})
-	if err != nil {
-		s.Logger.WithFields(logrus.Fields{
-			"Function": "ConfirmAppointment",
-			"Error":    err,
-		}).Error("Failed to call payment service")
-		return "", "", errors.New("failed to call payment service")
-	} else if Resp.Status != "success" {
-		s.Logger.WithFields(logrus.Fields{
-			"Function": "ConfirmAppointment",
-			"Message":  Resp.Message,
-		}).Info("Payment service responded with failure")
-		return "", "", errors.New(Resp.Message)
+	if options.PaymentMode == "" {
+		options.PaymentMode = domain.PaymentModePrepaid
+	}
+	appointment.PaymentMode = options.PaymentMode
+
+	var redemption *domain.PromoRedemption
+	switch options.PaymentMode {
+	case domain.PaymentModeFree:
+		// The waiver is recorded under whoever is signed in, never a name from the request
+		id, ok := auth.FromContext(ctx)
+		if !ok || (id.Role != auth.RoleFrontDesk && id.Role != auth.RoleAdmin) {
+			return "", "", apperr.PermissionDenied("free bookings must be authorised by a staff member")
+		}
+		appointment.Amount = 0
+		appointment.CollectedBy = id.Subject
+	case domain.PaymentModePrepaid, domain.PaymentModePayAtClinic:
+		if options.PromoCode != "" {
+			redemption, err = s.applyPromoCode(ctx, options.PromoCode, appointment)
+			if err != nil {
+				return "", "", err
+			}
+			appointment.Amount = redemption.FinalAmount
+		}
+		if options.PayerId != "" {
+			if err := s.applyCoverage(ctx, &appointment, options); err != nil {
+				return "", "", err
+			}
+		}
+	default:
+		return "", "", apperr.InvalidArgument(apperr.FieldViolation{Field: "payment_mode", Description: "must be prepaid, pay_at_clinic or free"})
+	}
+
+	if redemption != nil {
+		// Hold the discount before a payment order is created for it; the
+		// limits are re-checked here, and the hold is given back if the
+		// booking is not saved
+		reserved, err := s.repo.ReservePromoRedemption(ctx, *redemption)
+		if err != nil {
+			return "", "", err
+		}
+		defer func() {
+			if redemption == nil {
+				return
+			}
+			if err := s.repo.ReleasePromoRedemption(context.WithoutCancel(ctx), reserved.ID); err != nil {
+				s.Logger.WithError(err).Warn("Failed to release promo redemption of unsaved booking")
+			}
+		}()
+	}
+
+	paymentURL := ""
+	switch {
+	case options.PaymentMode == domain.PaymentModeFree:
+		appointment.Status = "confirmed"
+		appointment.PaymentStatus = domain.PaymentStatusWaived
+	case appointment.Amount <= 0:
+		// Fully discounted or fully covered bookings have nothing to pay upfront
+		appointment.Status = "confirmed"
+		appointment.PaymentStatus = domain.PaymentStatusNotRequired
+	case options.PaymentMode == domain.PaymentModePayAtClinic:
+		// Confirmed straight away; the front desk records the payment at the visit
+		appointment.Status = "confirmed"
+		appointment.PaymentStatus = domain.PaymentStatusDue
+	default:
+		Resp, err := s.PaymentClient.CreateRazorOrderId(ctx, &paymentpb.CreateRazorOrderIdRequest{
+			PatientId:     appointment.PatientId,
+			Amount:        appointment.Amount,
+			AppointmentId: int64(newAppointmentId),
+			Type:          "appointment fee",
+		})
+		if err != nil {
+			s.Logger.WithFields(logrus.Fields{
+				"Function": "BookAppointment",
+				"Error":    err,
+			}).Error("Failed to call payment service")
+			return "", "", apperr.Unavailable("online payment is unavailable right now, please try again or book with pay at clinic", err)
+		} else if Resp.Status != "success" {
+			s.Logger.WithFields(logrus.Fields{
+				"Function": "BookAppointment",
+				"Message":  Resp.Message,
+			}).Info("Payment service responded with failure")
+			return "", "", errors.New(Resp.Message)
+		}
+		appointment.PaymentId = Resp.OrderId
+		appointment.PaymentStatus = domain.PaymentStatusPending
+		paymentURL = Resp.PaymentUrl
 	}
-	appointment.PaymentId = Resp.OrderId
 
-	if err := s.repo.ConfirmAppointment(appointment); err != nil {
+	if err := s.repo.ConfirmAppointment(ctx, appointment); err != nil {
 		s.Logger.WithFields(logrus.Fields{
-			"Function":      "ConfirmAppointment",
+			"Function":      "BookAppointment",
 			"AppointmentID": newAppointmentId,
 			"Error":         err,
 		}).Error("Failed to save appointment")
 		return "", "", err
 	}
+	// The booking now owns the reserved redemption
+	redemption = nil
 
 	s.Logger.WithFields(logrus.Fields{
-		"Function":      "ConfirmAppointment",
+		"Function":      "BookAppointment",
 		"AppointmentID": newAppointmentId,
-		"PaymentURL":    Resp.PaymentUrl,
+		"PaymentURL":    paymentURL,
 	}).Info("Appointment confirmed successfully")
 
-	return Resp.PaymentUrl, "Appointment successfully confirmed", nil
+	return paymentURL, "Appointment successfully confirmed", nil
+}
+
+// checkDoctorLeave rejects a booking that falls on a day the doctor marked unavailable
+func checkDoctorLeave(availability []*doctorpb.DoctorAvailability, reqTime time.Time) error {
+	for _, v := range availability {
+		if v.IsAvailable == "unavailable" {
+			doctorUnavailableDate, err := time.Parse("Mon Jan 2 15:04:05 2006", v.DateTime)
+			if err != nil {
+				return errors.New("invalid doctor availability date format")
+			}
+
+			if reqTime.Year() == doctorUnavailableDate.Year() &&
+				reqTime.Month() == doctorUnavailableDate.Month() &&
+				reqTime.Day() == doctorUnavailableDate.Day() {
+				return apperr.FailedPrecondition("doctor is not available on this date").
+					WithReason("DOCTOR_ON_LEAVE", map[string]string{"date": doctorUnavailableDate.Format(time.DateOnly)})
+			}
+		}
+	}
+	return nil
 }
 
 // Cancel an appointment
-func (s *appointmentService) CancelAppointment(appointment domain.Appointment, reason string) (string, error) {
+func (s *appointmentService) CancelAppointment(ctx context.Context, appointment domain.Appointment, reason string) (string, error) {
 	s.Logger.WithFields(logrus.Fields{
 		"Function":      "CancelAppointment",
 		"AppointmentId": appointment.AppointmentId,
 		"Reason":        reason,
 	}).Info("Attempting to cancel appointment")
 
-	resp, err := s.repo.CancelAppointment(appointment, reason)
+	resp, err := s.repo.CancelAppointment(ctx, appointment, reason)
 	if err != nil {
 		s.Logger.WithError(err).Error("Failed to cancel appointment")
 		return "", err
@@ -246,14 +426,37 @@ func (s *appointmentService) CancelAppointment(appointment domain.Appointment, r
 	return resp, nil
 }
 
+// pendingPaymentWindow is how long a booking waits for its online payment
+// before it is cancelled and its slot and promo code use are given back
+const pendingPaymentWindow = time.Hour
+
+// Cancel bookings whose online payment never completed, releasing their promo redemptions
+func (s *appointmentService) ExpirePendingAppointments() {
+	s.Logger.Info("Expiring unpaid bookings")
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Job)
+	defer cancel()
+
+	now := time.Now()
+	expired, err := s.repo.ExpirePendingAppointments(ctx, now.Add(-pendingPaymentWindow), now)
+	if err != nil {
+		s.Logger.WithError(err).Error("Error expiring unpaid bookings")
+		return
+	}
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "ExpirePendingAppointments",
+		"Expired":  expired,
+	}).Info("Unpaid bookings expired")
+}
+
 // Get upcoming appointments for a patient
-func (s *appointmentService) GetUpcomingAppointments(patientId string) ([]domain.Appointment, error) {
+func (s *appointmentService) GetUpcomingAppointments(ctx context.Context, patientId string) ([]domain.Appointment, error) {
 	s.Logger.WithFields(logrus.Fields{
 		"Function":  "GetUpcomingAppointments",
 		"PatientId": patientId,
 	}).Info("Fetching upcoming appointments for patient")
 
-	appointments, err := s.repo.FetchAppointmentsByPatient(patientId)
+	appointments, err := s.repo.FetchAppointmentsByPatient(ctx, patientId, time.Now())
 	if err != nil {
 		s.Logger.WithError(err).Error("Failed to fetch upcoming appointments")
 		return nil, err
@@ -264,66 +467,57 @@ func (s *appointmentService) GetUpcomingAppointments(patientId string) ([]domain
 }
 
 // Create a room for video treatment
-func (d *appointmentService) CreateRoomForVideoTreatment(patientId, doctorId string, specializationId int64) (string, error) {
+func (d *appointmentService) CreateRoomForVideoTreatment(ctx context.Context, patientId, doctorId string, specializationId int64) (string, error) {
 	d.Logger.WithFields(logrus.Fields{
 		"Function":  "CreateRoomForVideoTreatment",
 		"PatientId": patientId,
 		"DoctorId":  doctorId,
 	}).Info("Creating video treatment room")
 
-	check, resp, err := d.repo.CheckVideoAppoitment(patientId)
+	check, resp, err := d.repo.CheckVideoAppoitment(ctx, patientId, doctorId, int32(specializationId))
 	if err != nil {
 		d.Logger.WithError(err).Error("Failed to check video appointment")
 		return "", err
 	}
 	if !check {
-		return "", errors.New("patient doesn't have an appointment")
+		return "", apperr.NotFound("patient doesn't have an appointment")
 	}
 
-	roomId := uuid.New().String()
-	err = d.repo.SaveVideoAppointment(roomId, resp.AppointmentId, int(specializationId))
-	if err != nil {
-		d.Logger.WithError(err).Error("Failed to save video appointment")
-		return "", err
-	}
+	return d.CreateVideoRoom(ctx, resp.AppointmentId, doctorId)
+}
 
-	roomURL := fmt.Sprintf("http://%s/api/v1/doctor/video-call/%s", os.Getenv("IP_ADDRESS"), roomId)
-	profile, err := d.PatientClient.GetProfile(context.Background(), &patientpb.GetProfileRequest{PatientId: patientId})
-	if err != nil {
-		d.Logger.WithError(err).Error("Failed to fetch patient profile")
-		return "", err
-	}
+// videoJoinLeadTime is how long before the appointment the room links become valid
+const videoJoinLeadTime = 10 * time.Minute
 
-	PatientRoomUrl := fmt.Sprintf("http://%s/api/v1/patient/video-call/%s", os.Getenv("IP_ADDRESS"), roomId)
-	err = di.HandleAppointmentNotification("appointment_topic", domain.AppointmentEvent{
-		AppointmentId:   resp.AppointmentId,
-		Email:           profile.Email,
-		VideoURL:        PatientRoomUrl,
-		DoctorId:        doctorId,
-		AppointmentDate: resp.AppointmentTime.Format("2016-02-01"),
-		Type:            resp.Type,
-	})
-	if err != nil {
-		d.Logger.WithError(err).Error("Failed to produce video appointment event")
-		return "", errors.New("failed to produce video appointment event")
+// videoRoom scopes a room to its appointment, from shortly before the start until the slot ends
+func videoRoom(roomId string, appointment domain.Appointment) video.Room {
+	duration := appointment.Duration
+	if duration == 0 {
+		duration = time.Hour
+	}
+	return video.Room{
+		Name:      roomId,
+		NotBefore: appointment.AppointmentTime.Add(-videoJoinLeadTime),
+		ExpiresAt: appointment.AppointmentTime.Add(duration),
 	}
-
-	d.Logger.Info("Video treatment room created successfully")
-	return roomURL, nil
 }
 
 // Get details of an appointment
-func (d *appointmentService) GetAppointmentDetails(orderid string) (domain.Appointment, error) {
+func (d *appointmentService) GetAppointmentDetails(ctx context.Context, orderid string) (domain.Appointment, error) {
 	d.Logger.WithFields(logrus.Fields{
 		"Function": "GetAppointmentDetails",
 		"OrderId":  orderid,
 	}).Info("Fetching appointment details")
 
-	appointment, err := d.repo.GetAppointmentDetails(orderid)
+	appointment, err := d.repo.GetAppointmentDetails(ctx, orderid)
 	if err != nil {
 		d.Logger.WithError(err).Error("Failed to fetch appointment details")
 		return domain.Appointment{}, err
 	}
+	err = d.recordRead(ctx, audit.NewReadEntry(ctx, audit.ActionRead, appointment.AppointmentId, appointment.PatientId, map[string]interface{}{"order_id": orderid}))
+	if err != nil {
+		return domain.Appointment{}, err
+	}
 
 	d.Logger.Info("Appointment details fetched successfully")
 	return appointment, nil
@@ -333,14 +527,17 @@ func (d *appointmentService) GetAppointmentDetails(orderid string) (domain.Appoi
 func (d *appointmentService) SendDialyReminders() {
 	d.Logger.Info("Sending daily appointment alerts")
 
-	appointments, err := d.repo.FetchTodayAppointments()
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeouts.Job)
+	defer cancel()
+
+	appointments, err := d.repo.FetchTodayAppointments(ctx)
 	if err != nil {
 		d.Logger.WithError(err).Error("Error fetching today's appointments")
 		return
 	}
 
 	for _, appointment := range appointments {
-		profile, err := d.PatientClient.GetProfile(context.Background(), &patientpb.GetProfileRequest{
+		profile, err := d.PatientClient.GetProfile(ctx, &patientpb.GetProfileRequest{
 			PatientId: appointment.PatientId,
 		})
 		if err != nil {
@@ -348,7 +545,7 @@ func (d *appointmentService) SendDialyReminders() {
 			continue
 		}
 
-		err = di.HandleAppointmentNotification("alert_topic", domain.AppointmentEvent{
+		err = d.notify(ctx, "alert_topic", domain.AppointmentEvent{
 			AppointmentId:   appointment.AppointmentId,
 			Email:           profile.Email,
 			DoctorId:        appointment.DoctorId,
@@ -365,13 +562,21 @@ func (d *appointmentService) SendDialyReminders() {
 }
 
 // Add a new specialization
-func (a *appointmentService) AddSpecialization(name, description string) (string, error) {
+func (a *appointmentService) AddSpecialization(ctx context.Context, name, description string) (string, error) {
 	a.Logger.WithFields(logrus.Fields{
 		"Function": "AddSpecialization",
 		"Name":     name,
 	}).Info("Adding a new specialization")
 
-	resp, err := a.repo.CreateSpecialization(domain.Specialization{Name: name, Description: description})
+	if strings.TrimSpace(name) == "" {
+		return "", apperr.InvalidArgument(apperr.FieldViolation{Field: "name", Description: "is required"})
+	}
+	resp, err := a.repo.CreateSpecialization(ctx, domain.Specialization{
+		Name:        strings.TrimSpace(name),
+		Description: description,
+		Slug:        slugify(name),
+		IsActive:    true,
+	})
 	if err != nil {
 		a.Logger.WithError(err).Error("Failed to add specialization")
 		return resp, err
@@ -380,49 +585,3 @@ func (a *appointmentService) AddSpecialization(name, description string) (string
 	a.Logger.Info("Specialization added successfully")
 	return resp, nil
 }
-
-// Fetch statistics details
-func (a *appointmentService) FetchStatisticsDetails(param string) ([]domain.SpecializationStats, domain.StatisticsData, error) {
-	a.Logger.WithFields(logrus.Fields{
-		"Function": "FetchStatisticsDetails",
-		"Param":    param,
-	}).Info("Fetching statistics details")
-
-	special, err := a.repo.GetSpecializationStats(param)
-	if err != nil {
-		a.Logger.WithError(err).Error("Failed to fetch specialization stats")
-		return nil, domain.StatisticsData{}, err
-	}
-
-	appointmentCount, err := a.repo.GetTotalAppointment(param)
-	if err != nil {
-		a.Logger.WithError(err).Error("Failed to fetch total appointment count")
-		return nil, domain.StatisticsData{}, err
-	}
-
-	patientCount, err := a.PatientClient.GetTotalPatient(context.Background(), &patientpb.GetTotalPatientCountRequest{})
-	if err != nil {
-		a.Logger.WithError(err).Error("Failed to fetch total patient count")
-		return nil, domain.StatisticsData{}, err
-	}
-
-	doctorCount, err := a.DoctorClient.GetTotalDoctor(context.Background(), &doctorpb.GetTotalDoctorCountRequest{Param: param})
-	if err != nil {
-		a.Logger.WithError(err).Error("Failed to fetch total doctor count")
-		return nil, domain.StatisticsData{}, err
-	}
-
-	revenue, err := a.PaymentClient.GetTotalRevenue(context.Background(), &paymentpb.GetTotalRevenueRequest{Param: param})
-	if err != nil {
-		a.Logger.WithError(err).Error("Failed to fetch total revenue")
-		return nil, domain.StatisticsData{}, err
-	}
-
-	a.Logger.Info("Statistics details fetched successfully")
-	return special, domain.StatisticsData{
-		TotalAppointments: appointmentCount,
-		TotalPatients:     int(patientCount.PatientCount),
-		TotalDoctors:      int(doctorCount.DoctorCount),
-		TotalRevenue:      revenue.TotalRevenue,
-	}, nil
-}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/repository"
+)
+
+// bookingRepo serves a free slot, the default price and one promo code, and
+// records what the booking reserves, releases and saves
+type bookingRepo struct {
+	repository.AppointmentRepository
+
+	promo      domain.PromoCode
+	reserveErr error
+	reserved   []domain.PromoRedemption
+	released   []uint
+	saved      []domain.Appointment
+}
+
+func (r *bookingRepo) GetSpecialization(ctx context.Context, id uint, slug string) (domain.Specialization, error) {
+	return domain.Specialization{IsActive: true}, nil
+}
+
+func (r *bookingRepo) IsDoctorAvailable(ctx context.Context, doctorId string, patientId string, reqTime time.Time, duration time.Duration, parentAppointmentId int) error {
+	return nil
+}
+
+func (r *bookingRepo) GetLatestAppointmentId(ctx context.Context) (int, error) {
+	return 41, nil
+}
+
+func (r *bookingRepo) GetPricing(ctx context.Context, specializationId int32, doctorId string) ([]domain.ConsultationPrice, []domain.PriceSurcharge, error) {
+	return nil, nil, nil
+}
+
+func (r *bookingRepo) GetPromoCode(ctx context.Context, code string) (domain.PromoCode, error) {
+	if code != r.promo.Code {
+		return domain.PromoCode{}, apperr.NotFound("promo code not found")
+	}
+	return r.promo, nil
+}
+
+func (r *bookingRepo) CountPromoRedemptions(ctx context.Context, promoCodeId uint, patientId string) (int, int, error) {
+	return len(r.reserved) - len(r.released), 0, nil
+}
+
+func (r *bookingRepo) ReservePromoRedemption(ctx context.Context, redemption domain.PromoRedemption) (domain.PromoRedemption, error) {
+	if r.reserveErr != nil {
+		return domain.PromoRedemption{}, r.reserveErr
+	}
+	redemption.ID = uint(len(r.reserved) + 1)
+	r.reserved = append(r.reserved, redemption)
+	return redemption, nil
+}
+
+func (r *bookingRepo) ReleasePromoRedemption(ctx context.Context, redemptionId uint) error {
+	r.released = append(r.released, redemptionId)
+	return nil
+}
+
+func (r *bookingRepo) ConfirmAppointment(ctx context.Context, appointment domain.Appointment) error {
+	r.saved = append(r.saved, appointment)
+	return nil
+}
+
+func newBookingService(repo *bookingRepo, payment *stubPaymentClient) *appointmentService {
+	s := newTestService(repo)
+	s.DoctorClient = &stubDoctorClient{}
+	s.PaymentClient = payment
+	return s
+}
+
+func promoBooking() (domain.Appointment, domain.BookingOptions) {
+	appointment := domain.Appointment{
+		DoctorId:         "d1",
+		PatientId:        "p1",
+		SpecializationId: 3,
+		Type:             "video",
+		AppointmentTime:  time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	return appointment, domain.BookingOptions{PromoCode: "SAVE10"}
+}
+
+var testPromo = domain.PromoCode{Code: "SAVE10", DiscountType: "percent", DiscountValue: 10, GlobalLimit: 1, IsActive: true}
+
+func TestBookAppointmentChecksPromoLimitBeforeCreatingOrder(t *testing.T) {
+	repo := &bookingRepo{promo: testPromo, reserveErr: errors.New("promo code usage limit reached")}
+	payment := &stubPaymentClient{}
+	appointment, options := promoBooking()
+
+	_, _, err := newBookingService(repo, payment).BookAppointment(context.Background(), appointment, options)
+	if err == nil {
+		t.Fatal("booking should fail when the promo code can no longer be redeemed")
+	}
+	if payment.orders != 0 {
+		t.Errorf("%d payment orders created for a booking that could not redeem its promo code", payment.orders)
+	}
+	if len(repo.saved) != 0 {
+		t.Errorf("appointment saved: %+v", repo.saved)
+	}
+}
+
+func TestBookAppointmentReleasesPromoWhenOrderFails(t *testing.T) {
+	tests := []struct {
+		name  string
+		order func(*paymentpb.CreateRazorOrderIdRequest) (*paymentpb.CreateRazorOrderIdResponse, error)
+	}{
+		{"payment service down", func(*paymentpb.CreateRazorOrderIdRequest) (*paymentpb.CreateRazorOrderIdResponse, error) {
+			return nil, errors.New("connection refused")
+		}},
+		{"order refused", func(*paymentpb.CreateRazorOrderIdRequest) (*paymentpb.CreateRazorOrderIdResponse, error) {
+			return &paymentpb.CreateRazorOrderIdResponse{Status: "fail", Message: "amount too small"}, nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &bookingRepo{promo: testPromo}
+			appointment, options := promoBooking()
+
+			_, _, err := newBookingService(repo, &stubPaymentClient{createOrder: tt.order}).BookAppointment(context.Background(), appointment, options)
+			if err == nil {
+				t.Fatal("booking should fail when no payment order is created")
+			}
+			if len(repo.reserved) != 1 || len(repo.released) != 1 || repo.released[0] != repo.reserved[0].ID {
+				t.Errorf("reserved %+v, released %v; the reservation should be given back", repo.reserved, repo.released)
+			}
+			if len(repo.saved) != 0 {
+				t.Errorf("appointment saved: %+v", repo.saved)
+			}
+		})
+	}
+}
+
+func TestBookAppointmentKeepsPromoOfSavedBooking(t *testing.T) {
+	repo := &bookingRepo{promo: testPromo}
+	payment := &stubPaymentClient{createOrder: func(in *paymentpb.CreateRazorOrderIdRequest) (*paymentpb.CreateRazorOrderIdResponse, error) {
+		return &paymentpb.CreateRazorOrderIdResponse{Status: "success", OrderId: "order_1", PaymentUrl: "https://pay.test/order_1"}, nil
+	}}
+	appointment, options := promoBooking()
+
+	paymentURL, _, err := newBookingService(repo, payment).BookAppointment(context.Background(), appointment, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paymentURL != "https://pay.test/order_1" {
+		t.Errorf("payment url = %q", paymentURL)
+	}
+	if len(repo.reserved) != 1 || repo.reserved[0].AppointmentId != 42 || repo.reserved[0].FinalAmount != 180 {
+		t.Errorf("reserved = %+v, want 10%% off the default fee for appointment 42", repo.reserved)
+	}
+	if len(repo.released) != 0 {
+		t.Errorf("released %v for a saved booking", repo.released)
+	}
+	if len(repo.saved) != 1 || repo.saved[0].Amount != 180 || repo.saved[0].PaymentId != "order_1" {
+		t.Errorf("saved = %+v", repo.saved)
+	}
+}
+
+func TestBookAppointmentFreeRecordsSignedInStaff(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+	}{
+		{"front desk", auth.WithIdentity(context.Background(), auth.Identity{Subject: "fd-1", Role: auth.RoleFrontDesk}), false},
+		{"admin", auth.WithIdentity(context.Background(), auth.Identity{Subject: "admin-1", Role: auth.RoleAdmin}), false},
+		{"patient", auth.WithIdentity(context.Background(), auth.Identity{Subject: "p1", Role: auth.RolePatient}), true},
+		{"gateway service", auth.WithIdentity(context.Background(), auth.Identity{Subject: "gateway", Role: auth.RoleService}), true},
+		{"not signed in", context.Background(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &bookingRepo{}
+			payment := &stubPaymentClient{}
+			appointment, _ := promoBooking()
+
+			_, _, err := newBookingService(repo, payment).BookAppointment(tt.ctx, appointment, domain.BookingOptions{PaymentMode: domain.PaymentModeFree})
+			if tt.wantErr {
+				if apperr.KindOf(err) != apperr.KindPermissionDenied || len(repo.saved) != 0 {
+					t.Errorf("err = %v, saved %d; want permission denied and nothing saved", err, len(repo.saved))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, _ := auth.FromContext(tt.ctx)
+			saved := repo.saved[0]
+			if saved.CollectedBy != id.Subject || saved.Amount != 0 || saved.PaymentStatus != domain.PaymentStatusWaived || saved.Status != "confirmed" {
+				t.Errorf("saved = %+v, want a confirmed waiver by %s", saved, id.Subject)
+			}
+			if payment.orders != 0 {
+				t.Errorf("%d payment orders created for a free booking", payment.orders)
+			}
+		})
+	}
+}
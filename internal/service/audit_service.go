@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Page sizes for audit log queries, and the batch size used to verify the chain
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 100
+	auditVerifyBatch  = 1000
+)
+
+// Query the audit log, oldest first. The returned id is the cursor of the next page, 0 when there is none.
+func (s *appointmentService) QueryAuditLog(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, uint, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "QueryAuditLog",
+		"AppointmentId": filter.AppointmentId,
+		"ActorId":       filter.ActorId,
+		"Action":        filter.Action,
+		"AfterId":       filter.AfterId,
+	}).Info("Querying audit log")
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	} else if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return nil, 0, errors.New("to must be after from")
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.repo.FetchAuditEntries(ctx, filter)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to query audit log")
+		return nil, 0, err
+	}
+	var next uint
+	if len(entries) > limit {
+		entries = entries[:limit]
+		next = entries[limit-1].Id
+	}
+	return entries, next, nil
+}
+
+// auditAnchorKey signs the anchors of the audit chain. It is kept out of the
+// database so whoever can rewrite the chain cannot sign new anchors for it.
+func auditAnchorKey() []byte {
+	return []byte(os.Getenv("AUDIT_ANCHOR_KEY"))
+}
+
+// Walk the whole audit chain and report the first entry that was changed,
+// removed or reordered. The chain is also checked against the stored anchors
+// and, when given, an anchor the caller kept elsewhere, such as a head hash
+// from the logs; rewriting the whole chain cannot satisfy those.
+func (s *appointmentService) VerifyAuditLog(ctx context.Context, anchor domain.AuditAnchor) (domain.AuditVerification, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "VerifyAuditLog",
+		"AnchorEntryId": anchor.EntryId,
+	}).Info("Verifying audit log")
+
+	key := auditAnchorKey()
+	if len(key) == 0 {
+		s.Logger.Warn("AUDIT_ANCHOR_KEY is not set, anchor signatures are not checked")
+	}
+	anchors, err := s.repo.FetchAuditAnchors(ctx)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch audit anchors")
+		return domain.AuditVerification{}, err
+	}
+	var callerAnchors []domain.AuditAnchor
+	if anchor.EntryId != 0 {
+		callerAnchors = append(callerAnchors, anchor)
+	}
+
+	result := domain.AuditVerification{Valid: true}
+	var afterId uint
+	prevHash := ""
+	for {
+		entries, err := s.repo.FetchAuditChain(ctx, afterId, auditVerifyBatch)
+		if err != nil {
+			s.Logger.WithError(err).Error("Failed to fetch audit chain")
+			return domain.AuditVerification{}, err
+		}
+		ok := audit.Verify(entries, prevHash, &result)
+		if ok {
+			anchors, ok = audit.CheckAnchors(key, entries, anchors, &result)
+		}
+		if ok {
+			// The caller's anchor is trusted as given, so it has no signature to check
+			callerAnchors, ok = audit.CheckAnchors(nil, entries, callerAnchors, &result)
+		}
+		if !ok {
+			s.logBrokenAudit(result)
+			return result, nil
+		}
+		if len(entries) < auditVerifyBatch {
+			break
+		}
+		afterId = entries[len(entries)-1].Id
+		prevHash = result.LastHash
+	}
+	// Anchors past the end of the chain mean entries were cut off the end
+	if remaining := append(anchors, callerAnchors...); len(remaining) > 0 {
+		result.Valid = false
+		result.BrokenAt, result.Reason = remaining[0].EntryId, "anchored entry is missing"
+		s.logBrokenAudit(result)
+		return result, nil
+	}
+	s.Logger.WithFields(logrus.Fields{
+		"Checked": result.Checked,
+		"Anchors": result.Anchors,
+	}).Info("Audit chain verified")
+	return result, nil
+}
+
+func (s *appointmentService) logBrokenAudit(result domain.AuditVerification) {
+	s.Logger.WithFields(logrus.Fields{
+		"BrokenAt": result.BrokenAt,
+		"Reason":   result.Reason,
+	}).Error("Audit chain is broken")
+}
+
+// Anchor the head of the audit chain. The head is always logged, so it can be
+// compared with the chain later even if the database is rewritten; with
+// AUDIT_ANCHOR_KEY set a signed anchor is stored as well.
+func (s *appointmentService) AnchorAuditLog() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Job)
+	defer cancel()
+
+	head, err := s.repo.FetchAuditHead(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch audit chain head")
+		return
+	}
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "AnchorAuditLog",
+		"EntryId":  head.Id,
+		"Hash":     head.Hash,
+	}).Info("Audit chain head")
+
+	key := auditAnchorKey()
+	if len(key) == 0 {
+		s.Logger.Warn("AUDIT_ANCHOR_KEY is not set, audit chain head is not anchored")
+		return
+	}
+	if err := s.repo.SaveAuditAnchor(ctx, audit.Anchor(key, head, time.Now())); err != nil {
+		s.Logger.WithError(err).Error("Failed to save audit anchor")
+	}
+}
+
+// recordRead audits a read before its data goes out; a read that cannot be audited fails
+func (s *appointmentService) recordRead(ctx context.Context, entry domain.AuditEntry) error {
+	if err := s.repo.RecordAudit(ctx, entry); err != nil {
+		s.Logger.WithError(err).Error("Failed to record read in audit log")
+		return apperr.Unavailable("failed to record access in the audit log", err)
+	}
+	return nil
+}
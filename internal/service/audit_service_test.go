@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// sealedChain seals n entries in order, as the repository appends them
+func sealedChain(n int) []domain.AuditEntry {
+	at := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	var entries []domain.AuditEntry
+	prevHash := ""
+	for i := 1; i <= n; i++ {
+		entry := audit.Seal(audit.NewEntry(context.Background(), audit.ActionCreate, i, nil, map[string]int{"appointment_id": i}), prevHash, at.Add(time.Duration(i)*time.Minute))
+		entry.Id = uint(i)
+		entries = append(entries, entry)
+		prevHash = entry.Hash
+	}
+	return entries
+}
+
+// chainRepo serves entries as the audit chain, in batches like the database would
+func chainRepo(entries []domain.AuditEntry, anchors []domain.AuditAnchor) *stubRepo {
+	return &stubRepo{
+		fetchAuditChain: func(ctx context.Context, afterId uint, limit int) ([]domain.AuditEntry, error) {
+			var batch []domain.AuditEntry
+			for _, e := range entries {
+				if e.Id > afterId && len(batch) < limit {
+					batch = append(batch, e)
+				}
+			}
+			return batch, nil
+		},
+		fetchAuditAnchors: func(ctx context.Context) ([]domain.AuditAnchor, error) {
+			return anchors, nil
+		},
+	}
+}
+
+func TestVerifyAuditLogChecksAnchors(t *testing.T) {
+	key := []byte("anchor-key")
+	t.Setenv("AUDIT_ANCHOR_KEY", string(key))
+	at := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	entries := sealedChain(3)
+
+	// The whole chain rewritten from entry 2 on, with hashes recomputed
+	rewritten := sealedChain(3)
+	rewritten[1].After = `{"appointment_id":99}`
+	rewritten[1] = audit.Seal(rewritten[1], rewritten[0].Hash, rewritten[1].CreatedAt)
+	rewritten[2] = audit.Seal(rewritten[2], rewritten[1].Hash, rewritten[2].CreatedAt)
+
+	tests := []struct {
+		name     string
+		entries  []domain.AuditEntry
+		anchors  []domain.AuditAnchor
+		caller   domain.AuditAnchor
+		brokenAt uint
+		reason   string
+	}{
+		{"intact", entries, []domain.AuditAnchor{audit.Anchor(key, entries[2], at)}, domain.AuditAnchor{EntryId: 1, Hash: entries[0].Hash}, 0, ""},
+		{"rewritten", rewritten, []domain.AuditAnchor{audit.Anchor(key, entries[2], at)}, domain.AuditAnchor{}, 3, "entry does not match its anchor"},
+		{"truncated", entries[:2], []domain.AuditAnchor{audit.Anchor(key, entries[2], at)}, domain.AuditAnchor{}, 3, "anchored entry is missing"},
+		{"rewritten with anchors removed", rewritten, nil, domain.AuditAnchor{EntryId: 2, Hash: entries[1].Hash}, 2, "entry does not match its anchor"},
+		{"forged anchor", rewritten, []domain.AuditAnchor{audit.Anchor([]byte("other-key"), rewritten[2], at)}, domain.AuditAnchor{}, 3, "anchor signature is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(chainRepo(tt.entries, tt.anchors))
+			result, err := s.VerifyAuditLog(context.Background(), tt.caller)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Valid != (tt.brokenAt == 0) || result.BrokenAt != tt.brokenAt || result.Reason != tt.reason {
+				t.Fatalf("got %+v, want broken at %d: %q", result, tt.brokenAt, tt.reason)
+			}
+			if result.Valid && result.Anchors != 2 {
+				t.Errorf("checked %d anchors, want 2", result.Anchors)
+			}
+		})
+	}
+}
+
+func TestAnchorAuditLogSignsHead(t *testing.T) {
+	key := []byte("anchor-key")
+	t.Setenv("AUDIT_ANCHOR_KEY", string(key))
+	head := sealedChain(2)[1]
+
+	var saved []domain.AuditAnchor
+	s := newTestService(&stubRepo{
+		fetchAuditHead: func(ctx context.Context) (domain.AuditEntry, error) { return head, nil },
+		saveAuditAnchor: func(ctx context.Context, anchor domain.AuditAnchor) error {
+			saved = append(saved, anchor)
+			return nil
+		},
+	})
+	s.AnchorAuditLog()
+	if len(saved) != 1 || saved[0].EntryId != head.Id || saved[0].Hash != head.Hash {
+		t.Fatalf("saved %+v, want one anchor on entry %d", saved, head.Id)
+	}
+	if saved[0].Signature != audit.AnchorSignature(key, saved[0]) {
+		t.Error("anchor is not signed with AUDIT_ANCHOR_KEY")
+	}
+
+	// Without a key the head is only logged
+	t.Setenv("AUDIT_ANCHOR_KEY", "")
+	saved = nil
+	s.AnchorAuditLog()
+	if len(saved) != 0 {
+		t.Errorf("saved %+v without a key", saved)
+	}
+}
+
+func TestGetAppointmentDetailsIsAudited(t *testing.T) {
+	appointment := domain.Appointment{AppointmentId: 7, PatientId: "p1", PaymentId: "order_1"}
+	var recorded []domain.AuditEntry
+	repo := &stubRepo{
+		getAppointmentDetails: func(ctx context.Context, orderid string) (domain.Appointment, error) {
+			return appointment, nil
+		},
+		recordAudit: func(ctx context.Context, entry domain.AuditEntry) error {
+			recorded = append(recorded, entry)
+			return nil
+		},
+	}
+	s := newTestService(repo)
+	if _, err := s.GetAppointmentDetails(context.Background(), "order_1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(recorded))
+	}
+	if e := recorded[0]; e.Action != audit.ActionRead || e.AppointmentId != 7 || e.PatientId != "p1" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	// A read that cannot be audited returns nothing
+	repo.recordAudit = func(ctx context.Context, entry domain.AuditEntry) error {
+		return errors.New("connection reset")
+	}
+	got, err := s.GetAppointmentDetails(context.Background(), "order_1")
+	if apperr.KindOf(err) != apperr.KindUnavailable {
+		t.Fatalf("got %v, want an unavailable error", err)
+	}
+	if got.PatientId != "" {
+		t.Errorf("returned %+v although the read was not audited", got)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/payer"
+)
+
+// applyCoverage asks the payer to cover the booking and reduces the upfront
+// amount by what it covers, keeping the claim reference on the appointment
+func (s *appointmentService) applyCoverage(ctx context.Context, appointment *domain.Appointment, options domain.BookingOptions) error {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "applyCoverage",
+		"AppointmentID": appointment.AppointmentId,
+		"PayerType":     options.PayerType,
+		"PayerId":       options.PayerId,
+	}).Info("Checking payer eligibility")
+
+	if options.PayerType != payer.KindInsurance && options.PayerType != payer.KindCorporate {
+		return errors.New("payer type must be insurance or corporate")
+	}
+	if strings.TrimSpace(options.MemberId) == "" {
+		return errors.New("policy or employee id is required")
+	}
+	if s.Payer == nil {
+		return payer.ErrUnknownPayer
+	}
+
+	eligibility, err := s.Payer.CheckEligibility(ctx, payer.EligibilityRequest{
+		Kind:             options.PayerType,
+		PayerId:          options.PayerId,
+		MemberId:         options.MemberId,
+		PatientId:        appointment.PatientId,
+		DoctorId:         appointment.DoctorId,
+		SpecializationId: appointment.SpecializationId,
+		AppointmentId:    appointment.AppointmentId,
+		Amount:           appointment.Amount,
+		Currency:         appointment.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, payer.ErrUnknownPayer) {
+			return err
+		}
+		s.Logger.WithError(err).Error("Failed to check payer eligibility")
+		return errors.New("failed to check insurance eligibility")
+	}
+	if !eligibility.Eligible {
+		s.Logger.WithFields(logrus.Fields{
+			"Function": "applyCoverage",
+			"Reason":   eligibility.Reason,
+		}).Info("Payer declined coverage")
+		return errors.New("not eligible for coverage: " + eligibility.Reason)
+	}
+
+	covered := eligibility.CoveredAmount
+	if covered > appointment.Amount {
+		covered = appointment.Amount
+	}
+	appointment.PayerType = options.PayerType
+	appointment.PayerId = options.PayerId
+	appointment.MemberId = options.MemberId
+	appointment.CoveredAmount = covered
+	appointment.Amount -= covered
+	appointment.ClaimRef = eligibility.ClaimRef
+	appointment.ClaimStatus = "pending"
+	return nil
+}
+
+// Export the payer claims booked in a period as CSV for the billing team
+func (s *appointmentService) ExportClaims(ctx context.Context, payerId string, from, to time.Time) (domain.ClaimExport, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "ExportClaims",
+		"PayerId":  payerId,
+		"From":     from,
+		"To":       to,
+	}).Info("Exporting payer claims")
+
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return domain.ClaimExport{}, errors.New("to must be after from")
+	}
+	claims, err := s.repo.FetchClaims(ctx, payerId, from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch claims")
+		return domain.ClaimExport{}, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"claim_ref", "payer_type", "payer_id", "member_id", "appointment_id", "patient_id", "doctor_id", "specialization_id", "appointment_time", "covered_amount", "patient_amount", "currency", "appointment_status", "claim_status"})
+	export := domain.ClaimExport{Claims: claims}
+	for _, c := range claims {
+		w.Write([]string{
+			c.ClaimRef,
+			c.PayerType,
+			c.PayerId,
+			c.MemberId,
+			strconv.Itoa(c.AppointmentId),
+			c.PatientId,
+			c.DoctorId,
+			strconv.Itoa(int(c.SpecializationId)),
+			c.AppointmentTime.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(c.CoveredAmount, 'f', 2, 64),
+			strconv.FormatFloat(c.Amount, 'f', 2, 64),
+			c.Currency,
+			c.Status,
+			c.ClaimStatus,
+		})
+		export.Total += c.CoveredAmount
+		if export.Currency == "" {
+			export.Currency = c.Currency
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return domain.ClaimExport{}, err
+	}
+	export.CSV = buf.Bytes()
+	return export, nil
+}
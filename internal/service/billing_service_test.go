@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/payer"
+)
+
+func TestApplyCoverage(t *testing.T) {
+	acme := payer.NewFakeProvider(
+		payer.FakePolicy{MemberId: "M-80", CoveragePercent: 80},
+		payer.FakePolicy{MemberId: "M-CAP", CoveragePercent: 100, Limit: 150},
+		payer.FakePolicy{MemberId: "M-ALL", CoveragePercent: 120},
+	)
+	registry := payer.NewRegistry()
+	registry.Register("acme", acme)
+
+	tests := []struct {
+		name        string
+		options     domain.BookingOptions
+		wantCovered float64
+		wantAmount  float64
+		wantErr     bool
+	}{
+		{"percent covered", domain.BookingOptions{PayerType: payer.KindInsurance, PayerId: "acme", MemberId: "M-80"}, 160, 40, false},
+		{"limit reached", domain.BookingOptions{PayerType: payer.KindCorporate, PayerId: "acme", MemberId: "M-CAP"}, 150, 50, false},
+		{"never more than the visit", domain.BookingOptions{PayerType: payer.KindInsurance, PayerId: "acme", MemberId: "M-ALL"}, 200, 0, false},
+		{"not a member", domain.BookingOptions{PayerType: payer.KindInsurance, PayerId: "acme", MemberId: "M-404"}, 0, 200, true},
+		{"unknown payer", domain.BookingOptions{PayerType: payer.KindInsurance, PayerId: "other", MemberId: "M-80"}, 0, 200, true},
+		{"bad payer type", domain.BookingOptions{PayerType: "charity", PayerId: "acme", MemberId: "M-80"}, 0, 200, true},
+		{"no member id", domain.BookingOptions{PayerType: payer.KindInsurance, PayerId: "acme"}, 0, 200, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(&stubRepo{})
+			s.Payer = registry
+			appointment := domain.Appointment{AppointmentId: 7, PatientId: "p1", Amount: 200, Currency: "INR"}
+
+			err := s.applyCoverage(context.Background(), &appointment, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if appointment.CoveredAmount != tt.wantCovered || appointment.Amount != tt.wantAmount {
+				t.Errorf("covered %.2f, patient pays %.2f; want %.2f and %.2f", appointment.CoveredAmount, appointment.Amount, tt.wantCovered, tt.wantAmount)
+			}
+			if tt.wantErr {
+				if appointment.ClaimRef != "" || appointment.PayerId != "" {
+					t.Errorf("declined booking carries a claim: %+v", appointment)
+				}
+				return
+			}
+			if appointment.ClaimRef == "" || appointment.ClaimStatus != "pending" || appointment.PayerId != "acme" || appointment.MemberId != tt.options.MemberId {
+				t.Errorf("claim not recorded on the appointment: %+v", appointment)
+			}
+		})
+	}
+}
+
+func TestApplyCoveragePayerDown(t *testing.T) {
+	fake := payer.NewFakeProvider(payer.FakePolicy{MemberId: "M-80", CoveragePercent: 80})
+	fake.Err = errors.New("connection reset")
+	s := newTestService(&stubRepo{})
+	s.Payer = fake
+	appointment := domain.Appointment{Amount: 200}
+
+	err := s.applyCoverage(context.Background(), &appointment, domain.BookingOptions{PayerType: payer.KindInsurance, PayerId: "acme", MemberId: "M-80"})
+	if err == nil || err.Error() != "failed to check insurance eligibility" {
+		t.Errorf("err = %v, want the payer failure hidden behind a generic message", err)
+	}
+}
+
+func TestExportClaimsCSV(t *testing.T) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	claims := []domain.Appointment{
+		{AppointmentId: 7, PatientId: "p1", DoctorId: "d1", SpecializationId: 3, AppointmentTime: at, PayerType: payer.KindInsurance, PayerId: "acme", MemberId: "M-80", CoveredAmount: 160, Amount: 40, Currency: "INR", Status: "confirmed", ClaimRef: "FAKE-acme-7-1", ClaimStatus: "pending"},
+		{AppointmentId: 9, PatientId: "p2", DoctorId: "d2", SpecializationId: 4, AppointmentTime: at.Add(24 * time.Hour), PayerType: payer.KindCorporate, PayerId: "acme", MemberId: "E-1", CoveredAmount: 150.5, Amount: 49.5, Currency: "INR", Status: "completed", ClaimRef: "FAKE-acme-9-2", ClaimStatus: "pending"},
+	}
+	var gotPayer string
+	s := newTestService(&stubRepo{
+		fetchClaims: func(_ context.Context, payerId string, _, _ time.Time) ([]domain.Appointment, error) {
+			gotPayer = payerId
+			return claims, nil
+		},
+	})
+
+	export, err := s.ExportClaims(context.Background(), "acme", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "claim_ref,payer_type,payer_id,member_id,appointment_id,patient_id,doctor_id,specialization_id,appointment_time,covered_amount,patient_amount,currency,appointment_status,claim_status\n" +
+		"FAKE-acme-7-1,insurance,acme,M-80,7,p1,d1,3,2026-03-02T04:30:00Z,160.00,40.00,INR,confirmed,pending\n" +
+		"FAKE-acme-9-2,corporate,acme,E-1,9,p2,d2,4,2026-03-03T04:30:00Z,150.50,49.50,INR,completed,pending\n"
+	if string(export.CSV) != want {
+		t.Errorf("csv =\n%s\nwant\n%s", export.CSV, want)
+	}
+	if gotPayer != "acme" || export.Total != 310.5 || export.Currency != "INR" || len(export.Claims) != 2 {
+		t.Errorf("export = payer %q, total %.2f %s, %d claims", gotPayer, export.Total, export.Currency, len(export.Claims))
+	}
+}
+
+func TestExportClaimsRejectsBackwardsPeriod(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	// fetchClaims is unset: reaching the repository would panic
+	if _, err := newTestService(&stubRepo{}).ExportClaims(context.Background(), "acme", from, from.Add(-time.Hour)); err == nil {
+		t.Error("a period ending before it starts should be rejected")
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/document"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Complete an appointment with the doctor's clinical notes and prescription
+func (s *appointmentService) CompleteAppointment(ctx context.Context, consultation domain.Consultation) (string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "CompleteAppointment",
+		"AppointmentId": consultation.AppointmentId,
+		"DoctorId":      consultation.DoctorId,
+	}).Info("Completing appointment")
+
+	for _, p := range consultation.Prescriptions {
+		if p.Drug == "" || p.Dose == "" || p.Frequency == "" || p.DurationDays <= 0 {
+			return "", errors.New("each medication needs drug, dose, frequency and duration")
+		}
+	}
+
+	appointment, err := s.repo.CompleteAppointment(ctx, consultation)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to complete appointment")
+		return "", err
+	}
+
+	profile, err := s.PatientClient.GetProfile(ctx, &patientpb.GetProfileRequest{PatientId: appointment.PatientId})
+	if err != nil {
+		s.Logger.WithError(err).Warn("Failed to fetch patient profile, skipping completion event")
+		return "Appointment completed successfully", nil
+	}
+
+	err = s.notify(ctx, "completion_topic", domain.AppointmentEvent{
+		AppointmentId:   appointment.AppointmentId,
+		Email:           profile.Email,
+		DoctorId:        appointment.DoctorId,
+		AppointmentDate: appointment.AppointmentTime.Format("2006-01-02"),
+		Type:            appointment.Type,
+		Status:          "completed",
+	})
+	if err != nil {
+		s.Logger.WithError(err).Warn("Failed to produce appointment completion event")
+	}
+
+	s.Logger.Info("Appointment completed successfully")
+	return "Appointment completed successfully", nil
+}
+
+// Get the prescription history of a patient
+func (s *appointmentService) GetPrescriptionHistory(ctx context.Context, patientId string) ([]domain.Consultation, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":  "GetPrescriptionHistory",
+		"PatientId": patientId,
+	}).Info("Fetching prescription history for patient")
+
+	if err := s.checkDoctorTreats(ctx, patientId); err != nil {
+		return nil, err
+	}
+	consultations, err := s.repo.FetchConsultationsByPatient(ctx, patientId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch prescription history")
+		return nil, err
+	}
+
+	s.Logger.Info("Prescription history fetched successfully")
+	return consultations, nil
+}
+
+// checkDoctorTreats lets a doctor read a patient's records only once they
+// have an appointment together. The policies check roles and ids in the
+// request; this needs the appointments, so it is checked here.
+func (s *appointmentService) checkDoctorTreats(ctx context.Context, patientId string) error {
+	id, ok := auth.FromContext(ctx)
+	if !ok || id.Role != auth.RoleDoctor {
+		return nil
+	}
+	treats, err := s.repo.DoctorTreatsPatient(ctx, id.Subject, patientId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to check the doctor's patients")
+		return err
+	}
+	if !treats {
+		return apperr.PermissionDenied("doctors may only read the records of their own patients")
+	}
+	return nil
+}
+
+// Render the visit summary or prescription of a completed appointment
+func (s *appointmentService) GetVisitDocument(ctx context.Context, appointmentId int, patientId, kind, format string) ([]byte, string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "GetVisitDocument",
+		"AppointmentId": appointmentId,
+		"Kind":          kind,
+		"Format":        format,
+	}).Info("Rendering visit document")
+
+	consultation, appointment, err := s.repo.GetConsultationByAppointment(ctx, appointmentId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch consultation")
+		return nil, "", err
+	}
+	if appointment.PatientId != patientId {
+		return nil, "", apperr.NotFound("appointment not found")
+	}
+	if err := s.checkDoctorTreats(ctx, patientId); err != nil {
+		return nil, "", err
+	}
+
+	verificationCode, err := document.VerificationCode(os.Getenv("DOCUMENT_SECRET"), appointment.AppointmentId, consultation.ID)
+	if err != nil {
+		s.Logger.WithError(err).Error("Refusing to issue an unsigned visit document")
+		return nil, "", apperr.Unavailable("visit documents are not available", err)
+	}
+
+	doctor, err := s.DoctorClient.GetProfile(ctx, &doctorpb.GetProfileRequest{DoctorId: appointment.DoctorId})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch doctor profile")
+		return nil, "", apperr.Unavailable("failed to call doctor service", err)
+	}
+	patient, err := s.PatientClient.GetProfile(ctx, &patientpb.GetProfileRequest{PatientId: appointment.PatientId})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch patient profile")
+		return nil, "", apperr.Unavailable("failed to call patient service", err)
+	}
+
+	content, contentType, err := document.Render(domain.VisitDocument{
+		AppointmentId:      appointment.AppointmentId,
+		PatientName:        patient.Name,
+		DoctorName:         doctor.Name,
+		SpecializationName: appointment.Specialization.Name,
+		AppointmentTime:    appointment.AppointmentTime,
+		Type:               appointment.Type,
+		Diagnosis:          consultation.Diagnosis,
+		Notes:              consultation.Notes,
+		Vitals:             consultation.Vitals,
+		Prescriptions:      consultation.Prescriptions,
+		VerificationCode:   verificationCode,
+	}, kind, format)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to render visit document")
+		return nil, "", err
+	}
+	err = s.recordRead(ctx, audit.NewReadEntry(ctx, audit.ActionDocumentRead, appointment.AppointmentId, appointment.PatientId, map[string]interface{}{"kind": kind, "format": format}))
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.Logger.Info("Visit document rendered successfully")
+	return content, contentType, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/auth"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+func TestGetPrescriptionHistoryLimitsDoctorsToTheirPatients(t *testing.T) {
+	repo := &stubRepo{
+		doctorTreatsPatient: func(ctx context.Context, doctorId, patientId string) (bool, error) {
+			return doctorId == "d1" && patientId == "p1", nil
+		},
+		fetchConsultationsByPatient: func(ctx context.Context, patientId string) ([]domain.Consultation, error) {
+			return []domain.Consultation{{PatientId: patientId}}, nil
+		},
+	}
+	s := newTestService(repo)
+
+	tests := []struct {
+		name    string
+		caller  auth.Identity
+		patient string
+		denied  bool
+	}{
+		{"own patient", auth.Identity{Subject: "d1", Role: auth.RoleDoctor}, "p1", false},
+		{"another doctor's patient", auth.Identity{Subject: "d1", Role: auth.RoleDoctor}, "p2", true},
+		{"doctor who never saw the patient", auth.Identity{Subject: "d2", Role: auth.RoleDoctor}, "p1", true},
+		{"front desk", auth.Identity{Subject: "fd1", Role: auth.RoleFrontDesk}, "p2", false},
+		{"patient", auth.Identity{Subject: "p2", Role: auth.RolePatient}, "p2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consultations, err := s.GetPrescriptionHistory(auth.WithIdentity(context.Background(), tt.caller), tt.patient)
+			if tt.denied {
+				if apperr.KindOf(err) != apperr.KindPermissionDenied || consultations != nil {
+					t.Fatalf("got %v, %v, want permission denied", consultations, err)
+				}
+				return
+			}
+			if err != nil || len(consultations) != 1 {
+				t.Fatalf("got %v, %v", consultations, err)
+			}
+		})
+	}
+}
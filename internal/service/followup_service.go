@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Recommend a follow-up visit for an appointment the doctor has seen
+func (s *appointmentService) CreateFollowUp(ctx context.Context, followUp domain.FollowUp) (domain.FollowUp, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":            "CreateFollowUp",
+		"ParentAppointmentId": followUp.ParentAppointmentId,
+		"DoctorId":            followUp.DoctorId,
+	}).Info("Creating follow-up recommendation")
+
+	parent, err := s.repo.GetAppointmentById(ctx, followUp.ParentAppointmentId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch parent appointment")
+		return domain.FollowUp{}, err
+	}
+	if parent.DoctorId != followUp.DoctorId {
+		return domain.FollowUp{}, apperr.NotFound("appointment not found")
+	}
+	if parent.Status == "cancelled" || parent.Status == "Pending" || parent.Status == "pending" {
+		return domain.FollowUp{}, apperr.FailedPrecondition("follow-up can only be recommended for an attended appointment")
+	}
+	if !followUp.WindowEnd.After(followUp.WindowStart) {
+		return domain.FollowUp{}, apperr.InvalidArgument(apperr.FieldViolation{Field: "window_end", Description: "must be after window_start"})
+	}
+	if followUp.WindowStart.Before(parent.AppointmentTime) {
+		return domain.FollowUp{}, apperr.InvalidArgument(apperr.FieldViolation{Field: "window_start", Description: "cannot be before the visit"})
+	}
+	if followUp.FeePercent < 0 || followUp.FeePercent > 100 {
+		return domain.FollowUp{}, apperr.InvalidArgument(apperr.FieldViolation{Field: "fee_percent", Description: "must be between 0 and 100"})
+	}
+
+	followUp.PatientId = parent.PatientId
+	followUp.SpecializationId = parent.SpecializationId
+	followUp.Status = "recommended"
+	followUp, err = s.repo.CreateFollowUp(ctx, followUp)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to save follow-up recommendation")
+		return domain.FollowUp{}, err
+	}
+
+	s.Logger.Info("Follow-up recommendation created successfully")
+	return followUp, nil
+}
+
+// Book the appointment recommended by a follow-up within its window
+func (s *appointmentService) BookFollowUp(ctx context.Context, followUpId uint, patientId string, reqTime time.Time, appointmentType string) (string, string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":        "BookFollowUp",
+		"FollowUpId":      followUpId,
+		"PatientID":       patientId,
+		"AppointmentTime": reqTime,
+	}).Info("Booking follow-up appointment")
+
+	followUp, err := s.repo.GetFollowUp(ctx, followUpId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch follow-up")
+		return "", "", err
+	}
+	if followUp.PatientId != patientId {
+		return "", "", apperr.NotFound("follow-up not found")
+	}
+	if followUp.Status != "recommended" {
+		return "", "", apperr.FailedPrecondition("follow-up is already booked")
+	}
+	if reqTime.Before(followUp.WindowStart) || reqTime.After(followUp.WindowEnd) {
+		return "", "", apperr.InvalidArgument(apperr.FieldViolation{
+			Field:       "confirmed_date_time",
+			Description: fmt.Sprintf("follow-up must be booked between %s and %s", followUp.WindowStart.Format(time.ANSIC), followUp.WindowEnd.Format(time.ANSIC)),
+		})
+	}
+
+	available, err := s.DoctorClient.CheckAvailabilityByDoctorId(ctx, &doctorpb.CheckAvailabilityByDoctorIdRequest{
+		DoctorId: followUp.DoctorId,
+	})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to call doctor service")
+		return "", "", apperr.Unavailable("failed to call doctor service", err)
+	}
+	if err := checkDoctorLeave(available.DoctorAvailability, reqTime); err != nil {
+		return "", "", err
+	}
+
+	if err := s.repo.IsDoctorAvailable(ctx, followUp.DoctorId, patientId, reqTime, time.Hour, followUp.ParentAppointmentId); err != nil {
+		s.Logger.WithError(err).Info("Doctor is not available for the follow-up")
+		return "", "", err
+	}
+
+	latestAppointmentId, err := s.repo.GetLatestAppointmentId(ctx)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch latest appointment ID")
+		return "", "", errors.New("failed to fetch latest appointment ID")
+	}
+	appointment := domain.Appointment{
+		AppointmentId:       latestAppointmentId + 1,
+		PatientId:           patientId,
+		DoctorId:            followUp.DoctorId,
+		SpecializationId:    followUp.SpecializationId,
+		AppointmentTime:     reqTime,
+		Duration:            time.Hour,
+		Type:                appointmentType,
+		ParentAppointmentId: followUp.ParentAppointmentId,
+		Status:              "confirmed",
+		PaymentMode:         domain.PaymentModeFree,
+		PaymentStatus:       domain.PaymentStatusWaived,
+	}
+
+	quote, err := s.quotePrice(ctx, followUp.SpecializationId, followUp.DoctorId, appointmentType, reqTime)
+	if err != nil {
+		return "", "", err
+	}
+	appointment.Amount = math.Round(quote.Amount*float64(followUp.FeePercent)) / 100
+	appointment.Currency = quote.Currency
+
+	// The order is only created once the follow-up is claimed, so a booking
+	// that loses the race to another one leaves no order behind
+	paymentURL := ""
+	var charge func(*domain.Appointment) error
+	if appointment.Amount > 0 {
+		charge = func(appointment *domain.Appointment) error {
+			resp, err := s.PaymentClient.CreateRazorOrderId(ctx, &paymentpb.CreateRazorOrderIdRequest{
+				PatientId:     patientId,
+				Amount:        appointment.Amount,
+				AppointmentId: int64(appointment.AppointmentId),
+				Type:          "follow-up fee",
+			})
+			if err != nil {
+				s.Logger.WithError(err).Error("Failed to call payment service")
+				return apperr.Unavailable("failed to call payment service", err)
+			} else if resp.Status != "success" {
+				return errors.New(resp.Message)
+			}
+			appointment.Status = "Pending"
+			appointment.PaymentMode = domain.PaymentModePrepaid
+			appointment.PaymentStatus = domain.PaymentStatusPending
+			appointment.PaymentId = resp.OrderId
+			paymentURL = resp.PaymentUrl
+			return nil
+		}
+	}
+
+	if err := s.repo.BookFollowUpAppointment(ctx, followUp.ID, appointment, charge); err != nil {
+		s.Logger.WithError(err).Error("Failed to save follow-up appointment")
+		return "", "", err
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "BookFollowUp",
+		"AppointmentID": appointment.AppointmentId,
+	}).Info("Follow-up appointment booked successfully")
+	return paymentURL, "Follow-up appointment successfully confirmed", nil
+}
+
+// Report how many recommended follow-ups were booked and attended
+func (s *appointmentService) GetFollowUpAdherence(ctx context.Context, from, to time.Time) ([]domain.FollowUpAdherence, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "GetFollowUpAdherence",
+		"From":     from,
+		"To":       to,
+	}).Info("Fetching follow-up adherence")
+
+	if !to.After(from) {
+		return nil, apperr.InvalidArgument(apperr.FieldViolation{Field: "to", Description: "must be after from"})
+	}
+	adherence, err := s.repo.GetFollowUpAdherence(ctx, from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch follow-up adherence")
+		return nil, err
+	}
+
+	s.Logger.Info("Follow-up adherence fetched successfully")
+	return adherence, nil
+}
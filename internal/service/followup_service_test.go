@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+	"gorm.io/gorm"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// followUpRepo serves one paid follow-up and claims it like the repository
+// does: only while it is still recommended, before charge runs
+type followUpRepo struct {
+	bookingRepo
+
+	followUp domain.FollowUp
+}
+
+func (r *followUpRepo) GetFollowUp(ctx context.Context, followUpId uint) (domain.FollowUp, error) {
+	return r.followUp, nil
+}
+
+func (r *followUpRepo) BookFollowUpAppointment(ctx context.Context, followUpId uint, appointment domain.Appointment, charge func(*domain.Appointment) error) error {
+	if r.followUp.Status != "recommended" {
+		return apperr.FailedPrecondition("follow-up is already booked")
+	}
+	r.followUp.Status = "booked"
+	if charge != nil {
+		if err := charge(&appointment); err != nil {
+			return err
+		}
+	}
+	r.saved = append(r.saved, appointment)
+	return nil
+}
+
+func TestBookFollowUpCreatesNoOrderWhenTheRaceIsLost(t *testing.T) {
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	repo := &followUpRepo{followUp: domain.FollowUp{
+		Model:       gorm.Model{ID: 5},
+		PatientId:   "p1",
+		DoctorId:    "d1",
+		WindowStart: start,
+		WindowEnd:   start.AddDate(0, 0, 14),
+		FeePercent:  50,
+		Status:      "recommended",
+	}}
+	payment := &stubPaymentClient{createOrder: func(in *paymentpb.CreateRazorOrderIdRequest) (*paymentpb.CreateRazorOrderIdResponse, error) {
+		return &paymentpb.CreateRazorOrderIdResponse{Status: "success", OrderId: "order_1", PaymentUrl: "https://pay/order_1"}, nil
+	}}
+	s := newBookingService(&repo.bookingRepo, payment)
+	s.repo = repo
+
+	url, _, err := s.BookFollowUp(context.Background(), 5, "p1", start.Add(2*time.Hour), domain.AppointmentTypeVideo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://pay/order_1" || payment.orders != 1 || repo.saved[0].PaymentId != "order_1" || repo.saved[0].Status != "Pending" {
+		t.Fatalf("first booking: url %q, %d orders, saved %+v", url, payment.orders, repo.saved)
+	}
+
+	// A second booking passes the status check on the follow-up it read
+	// earlier but loses the claim, and must not create an order
+	s.repo = &staleFollowUpRepo{followUpRepo: repo}
+	_, _, err = s.BookFollowUp(context.Background(), 5, "p1", start.Add(3*time.Hour), domain.AppointmentTypeVideo)
+	if apperr.KindOf(err) != apperr.KindFailedPrecondition {
+		t.Fatalf("err = %v, want failed precondition", err)
+	}
+	if payment.orders != 1 {
+		t.Errorf("created %d orders, want none for the lost booking", payment.orders-1)
+	}
+}
+
+// staleFollowUpRepo serves the follow-up as it was before another booking claimed it
+type staleFollowUpRepo struct {
+	*followUpRepo
+}
+
+func (r *staleFollowUpRepo) GetFollowUp(ctx context.Context, followUpId uint) (domain.FollowUp, error) {
+	followUp := r.followUp
+	followUp.Status = "recommended"
+	return followUp, nil
+}
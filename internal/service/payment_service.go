@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/stats"
+)
+
+// Record cash or card collected at the front desk for a pay-at-clinic booking
+func (s *appointmentService) RecordClinicPayment(ctx context.Context, appointmentId int, amount float64, method, collectedBy string) (domain.Appointment, error) {
+	method = strings.ToLower(strings.TrimSpace(method))
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "RecordClinicPayment",
+		"AppointmentId": appointmentId,
+		"Amount":        amount,
+		"Method":        method,
+		"CollectedBy":   collectedBy,
+	}).Info("Recording clinic payment")
+
+	if method != "cash" && method != "card" {
+		return domain.Appointment{}, errors.New("payment method must be cash or card")
+	}
+	if strings.TrimSpace(collectedBy) == "" {
+		return domain.Appointment{}, errors.New("collected by is required")
+	}
+	if amount < 0 {
+		return domain.Appointment{}, errors.New("amount cannot be negative")
+	}
+
+	appointment, err := s.repo.RecordClinicPayment(ctx, appointmentId, amount, method, collectedBy)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to record clinic payment")
+		return domain.Appointment{}, err
+	}
+	s.Logger.Info("Clinic payment recorded successfully")
+	return appointment, nil
+}
+
+// Revenue for a period, split into what was collected and what bookings are expected to bring in
+func (s *appointmentService) FetchRevenueStatistics(ctx context.Context, param string) (domain.StatisticsData, error) {
+	return withDeadline(ctx, s.timeouts.StatsCall, func(ctx context.Context) (domain.StatisticsData, error) {
+		return s.fetchRevenueStatistics(ctx, param)
+	})
+}
+
+func (s *appointmentService) fetchRevenueStatistics(ctx context.Context, param string) (domain.StatisticsData, error) {
+	revenue, err := s.PaymentClient.GetTotalRevenue(ctx, &paymentpb.GetTotalRevenueRequest{Param: param})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch total revenue")
+		return domain.StatisticsData{}, err
+	}
+	from, to := stats.Window(param, s.clinicNow())
+	data, err := s.repo.GetRevenueStats(ctx, from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch clinic revenue")
+		return domain.StatisticsData{}, err
+	}
+	// Online payments are settled by the payment service; cash and card taken at the clinic are recorded here
+	data.CollectedRevenue = revenue.TotalRevenue + data.ClinicCollected
+	data.TotalRevenue = data.CollectedRevenue
+	return data, nil
+}
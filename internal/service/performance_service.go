@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Cancel an appointment on behalf of its doctor
+func (s *appointmentService) CancelAppointmentByDoctor(ctx context.Context, appointmentId int, doctorId, reason string) (string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "CancelAppointmentByDoctor",
+		"AppointmentId": appointmentId,
+		"DoctorId":      doctorId,
+		"Reason":        reason,
+	}).Info("Doctor cancelling appointment")
+
+	if doctorId == "" {
+		return "", errors.New("doctor id is required")
+	}
+	resp, err := s.repo.CancelAppointment(ctx, domain.Appointment{AppointmentId: appointmentId, DoctorId: doctorId}, reason)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to cancel appointment")
+		return "", err
+	}
+	s.Logger.Info("Appointment cancelled successfully")
+	return resp, nil
+}
+
+// Let a patient rate a completed appointment from 1 to 5
+func (s *appointmentService) RateAppointment(ctx context.Context, rating domain.AppointmentRating) (domain.AppointmentRating, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "RateAppointment",
+		"AppointmentId": rating.AppointmentId,
+		"PatientId":     rating.PatientId,
+		"Score":         rating.Score,
+	}).Info("Rating appointment")
+
+	if rating.Score < 1 || rating.Score > 5 {
+		return domain.AppointmentRating{}, errors.New("score must be between 1 and 5")
+	}
+	appointment, err := s.repo.GetAppointmentById(ctx, rating.AppointmentId)
+	if err != nil {
+		return domain.AppointmentRating{}, err
+	}
+	if appointment.PatientId != rating.PatientId {
+		return domain.AppointmentRating{}, apperr.NotFound("appointment not found")
+	}
+	if appointment.Status != "completed" {
+		return domain.AppointmentRating{}, errors.New("only completed appointments can be rated")
+	}
+	rating.DoctorId = appointment.DoctorId
+	rating.Comment = strings.TrimSpace(rating.Comment)
+
+	saved, err := s.repo.CreateRating(ctx, rating)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to save rating")
+		return domain.AppointmentRating{}, err
+	}
+	return saved, nil
+}
+
+// Get per-doctor performance for [from, to), for one doctor when doctorId is set
+func (s *appointmentService) GetDoctorPerformance(ctx context.Context, doctorId string, from, to time.Time) ([]domain.DoctorPerformance, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "GetDoctorPerformance",
+		"DoctorId": doctorId,
+		"From":     from,
+		"To":       to,
+	}).Info("Fetching doctor performance")
+
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return nil, errors.New("to must be after from")
+	}
+	performance, err := s.repo.GetDoctorPerformance(ctx, doctorId, from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch doctor performance")
+		return nil, err
+	}
+	for i := range performance {
+		doctor, err := s.DoctorClient.GetProfile(ctx, &doctorpb.GetProfileRequest{DoctorId: performance[i].DoctorId})
+		if err != nil {
+			s.Logger.WithError(err).Warn("Failed to fetch doctor profile, leaving doctor name empty")
+			continue
+		}
+		performance[i].DoctorName = doctor.Name
+	}
+	return performance, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/pricing"
+)
+
+// quotePrice works out what a booking costs from the configured prices
+func (s *appointmentService) quotePrice(ctx context.Context, specializationId int32, doctorId, appointmentType string, at time.Time) (domain.PriceQuote, error) {
+	prices, surcharges, err := s.repo.GetPricing(ctx, specializationId, doctorId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch pricing")
+		return domain.PriceQuote{}, errors.New("failed to fetch consultation price")
+	}
+	return pricing.Quote(prices, surcharges, doctorId, appointmentType, at), nil
+}
+
+// Get the price a booking would be charged
+func (s *appointmentService) GetPriceQuote(ctx context.Context, specializationId int32, doctorId, appointmentType string, at time.Time) (domain.PriceQuote, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":         "GetPriceQuote",
+		"SpecializationId": specializationId,
+		"DoctorId":         doctorId,
+		"Type":             appointmentType,
+	}).Info("Quoting consultation price")
+
+	return s.quotePrice(ctx, specializationId, doctorId, appointmentType, at)
+}
+
+// Set the base price of a specialization, or a doctor's own price when DoctorId is set
+func (s *appointmentService) SetConsultationPrice(ctx context.Context, price domain.ConsultationPrice) (domain.ConsultationPrice, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":         "SetConsultationPrice",
+		"SpecializationId": price.SpecializationId,
+		"DoctorId":         price.DoctorId,
+	}).Info("Setting consultation price")
+
+	if price.SpecializationId <= 0 {
+		return domain.ConsultationPrice{}, errors.New("specialization id is required")
+	}
+	if price.VideoFee < 0 || price.InClinicFee < 0 {
+		return domain.ConsultationPrice{}, errors.New("fees cannot be negative")
+	}
+	price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+	if price.Currency == "" {
+		price.Currency = pricing.DefaultCurrency
+	} else if len(price.Currency) != 3 {
+		return domain.ConsultationPrice{}, errors.New("currency must be a 3-letter ISO code")
+	}
+	if _, err := s.repo.GetSpecialization(ctx, uint(price.SpecializationId), ""); err != nil {
+		return domain.ConsultationPrice{}, err
+	}
+
+	saved, err := s.repo.UpsertConsultationPrice(ctx, price)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to save consultation price")
+		return domain.ConsultationPrice{}, err
+	}
+	s.Logger.Info("Consultation price saved successfully")
+	return saved, nil
+}
+
+// List configured prices, optionally for one specialization
+func (s *appointmentService) ListConsultationPrices(ctx context.Context, specializationId int32) ([]domain.ConsultationPrice, error) {
+	prices, err := s.repo.ListConsultationPrices(ctx, specializationId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to list consultation prices")
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (s *appointmentService) DeleteConsultationPrice(ctx context.Context, id uint) error {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "DeleteConsultationPrice",
+		"Id":       id,
+	}).Info("Deleting consultation price")
+
+	if err := s.repo.DeleteConsultationPrice(ctx, id); err != nil {
+		s.Logger.WithError(err).Error("Failed to delete consultation price")
+		return err
+	}
+	return nil
+}
+
+// Add a time-of-day surcharge; SpecializationId 0 applies it to every specialization
+func (s *appointmentService) AddPriceSurcharge(ctx context.Context, surcharge domain.PriceSurcharge) (domain.PriceSurcharge, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":         "AddPriceSurcharge",
+		"SpecializationId": surcharge.SpecializationId,
+		"Name":             surcharge.Name,
+	}).Info("Adding price surcharge")
+
+	if surcharge.StartHour < 0 || surcharge.StartHour > 23 || surcharge.EndHour < 0 || surcharge.EndHour > 24 || surcharge.StartHour == surcharge.EndHour {
+		return domain.PriceSurcharge{}, errors.New("surcharge hours must be a non-empty range within 0-24")
+	}
+	if surcharge.Percent <= 0 {
+		return domain.PriceSurcharge{}, errors.New("surcharge percent must be positive")
+	}
+
+	saved, err := s.repo.CreatePriceSurcharge(ctx, surcharge)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to save price surcharge")
+		return domain.PriceSurcharge{}, err
+	}
+	return saved, nil
+}
+
+func (s *appointmentService) ListPriceSurcharges(ctx context.Context, specializationId int32) ([]domain.PriceSurcharge, error) {
+	surcharges, err := s.repo.ListPriceSurcharges(ctx, specializationId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to list price surcharges")
+		return nil, err
+	}
+	return surcharges, nil
+}
+
+func (s *appointmentService) DeletePriceSurcharge(ctx context.Context, id uint) error {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "DeletePriceSurcharge",
+		"Id":       id,
+	}).Info("Deleting price surcharge")
+
+	if err := s.repo.DeletePriceSurcharge(ctx, id); err != nil {
+		s.Logger.WithError(err).Error("Failed to delete price surcharge")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/privacy"
+)
+
+// Export everything this service holds about a patient as a JSON bundle
+func (s *appointmentService) ExportPatientData(ctx context.Context, patientId string) ([]byte, string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":  "ExportPatientData",
+		"PatientId": patientId,
+	}).Info("Exporting patient data")
+
+	if strings.TrimSpace(patientId) == "" {
+		return nil, "", errors.New("patient id is required")
+	}
+	data, err := s.repo.FetchPatientData(ctx, patientId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch patient data")
+		return nil, "", err
+	}
+	content, err := privacy.Bundle(data)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to render patient data")
+		return nil, "", err
+	}
+	if err := s.recordRead(ctx, audit.NewReadEntry(ctx, audit.ActionPatientExport, 0, patientId, map[string]interface{}{"patient_id": patientId})); err != nil {
+		return nil, "", err
+	}
+
+	s.Logger.Info("Patient data exported successfully")
+	return content, privacy.ContentType, nil
+}
+
+// Erase a patient: their id is replaced by a pseudonym on the rows kept for
+// statistics, and their consultations and personal free text are removed
+func (s *appointmentService) ErasePatientData(ctx context.Context, patientId string) (domain.PatientErasure, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":  "ErasePatientData",
+		"PatientId": patientId,
+	}).Info("Erasing patient data")
+
+	if strings.TrimSpace(patientId) == "" {
+		return domain.PatientErasure{}, errors.New("patient id is required")
+	}
+	if strings.HasPrefix(patientId, privacy.PseudonymPrefix) {
+		return domain.PatientErasure{}, errors.New("patient data is already erased")
+	}
+	erasure, err := s.repo.ErasePatientData(ctx, patientId, privacy.NewPseudonym())
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to erase patient data")
+		return domain.PatientErasure{}, err
+	}
+
+	s.Logger.WithFields(logrus.Fields{
+		"Appointments":  erasure.Appointments,
+		"Consultations": erasure.Consultations,
+		"AuditEntries":  erasure.AuditEntries,
+	}).Info("Patient data erased successfully")
+	return erasure, nil
+}
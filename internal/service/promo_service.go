@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/pricing"
+)
+
+// Create a promo code, optionally restricted to a set of specializations
+func (s *appointmentService) CreatePromoCode(ctx context.Context, promo domain.PromoCode) (domain.PromoCode, error) {
+	promo.Code = strings.ToUpper(strings.TrimSpace(promo.Code))
+	s.Logger.WithFields(logrus.Fields{
+		"Function":     "CreatePromoCode",
+		"Code":         promo.Code,
+		"DiscountType": promo.DiscountType,
+	}).Info("Creating promo code")
+
+	if promo.Code == "" {
+		return domain.PromoCode{}, errors.New("promo code is required")
+	}
+	switch promo.DiscountType {
+	case pricing.DiscountPercent:
+		if promo.DiscountValue <= 0 || promo.DiscountValue > 100 {
+			return domain.PromoCode{}, errors.New("percent discount must be between 0 and 100")
+		}
+	case pricing.DiscountFlat:
+		if promo.DiscountValue <= 0 {
+			return domain.PromoCode{}, errors.New("flat discount must be greater than zero")
+		}
+	default:
+		return domain.PromoCode{}, errors.New("discount type must be percent or flat")
+	}
+	if promo.MaxDiscount < 0 || promo.GlobalLimit < 0 || promo.PerPatientLimit < 0 {
+		return domain.PromoCode{}, errors.New("limits cannot be negative")
+	}
+	if !promo.ValidFrom.IsZero() && !promo.ValidUntil.IsZero() && !promo.ValidUntil.After(promo.ValidFrom) {
+		return domain.PromoCode{}, errors.New("valid until must be after valid from")
+	}
+	for i, spec := range promo.Specializations {
+		saved, err := s.repo.GetSpecialization(ctx, spec.ID, "")
+		if err != nil {
+			return domain.PromoCode{}, err
+		}
+		promo.Specializations[i] = saved
+	}
+	promo.IsActive = true
+
+	saved, err := s.repo.CreatePromoCode(ctx, promo)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to create promo code")
+		return domain.PromoCode{}, err
+	}
+	s.Logger.Info("Promo code created successfully")
+	return saved, nil
+}
+
+func (s *appointmentService) ListPromoCodes(ctx context.Context, includeInactive bool) ([]domain.PromoCode, error) {
+	promos, err := s.repo.ListPromoCodes(ctx, includeInactive)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to list promo codes")
+		return nil, err
+	}
+	return promos, nil
+}
+
+func (s *appointmentService) DeactivatePromoCode(ctx context.Context, code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "DeactivatePromoCode",
+		"Code":     code,
+	}).Info("Deactivating promo code")
+
+	if err := s.repo.DeactivatePromoCode(ctx, code); err != nil {
+		s.Logger.WithError(err).Error("Failed to deactivate promo code")
+		return err
+	}
+	return nil
+}
+
+// Work out what a promo code would take off a booking without redeeming it
+func (s *appointmentService) PreviewPromoCode(ctx context.Context, code string, appointment domain.Appointment) (domain.PromoRedemption, error) {
+	quote, err := s.quotePrice(ctx, appointment.SpecializationId, appointment.DoctorId, appointment.Type, appointment.AppointmentTime)
+	if err != nil {
+		return domain.PromoRedemption{}, err
+	}
+	appointment.Amount = quote.Amount
+	redemption, err := s.applyPromoCode(ctx, code, appointment)
+	if err != nil {
+		return domain.PromoRedemption{}, err
+	}
+	return *redemption, nil
+}
+
+func (s *appointmentService) GetPromoRedemptions(ctx context.Context, code string, from, to time.Time) ([]domain.PromoRedemption, error) {
+	redemptions, err := s.repo.FetchPromoRedemptions(ctx, strings.ToUpper(strings.TrimSpace(code)), from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch promo redemptions")
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+// applyPromoCode checks a promo code against the booking and works out the
+// discounted amount. The limits are checked again when the redemption is saved.
+func (s *appointmentService) applyPromoCode(ctx context.Context, code string, appointment domain.Appointment) (*domain.PromoRedemption, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	s.Logger.WithFields(logrus.Fields{
+		"Function":  "applyPromoCode",
+		"Code":      code,
+		"PatientID": appointment.PatientId,
+	}).Info("Applying promo code")
+
+	promo, err := s.repo.GetPromoCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := pricing.CheckPromo(promo, appointment.SpecializationId, time.Now()); err != nil {
+		return nil, err
+	}
+	total, byPatient, err := s.repo.CountPromoRedemptions(ctx, promo.ID, appointment.PatientId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to count promo redemptions")
+		return nil, errors.New("failed to check promo code usage")
+	}
+	if promo.GlobalLimit > 0 && total >= promo.GlobalLimit {
+		return nil, errors.New("promo code usage limit reached")
+	}
+	if promo.PerPatientLimit > 0 && byPatient >= promo.PerPatientLimit {
+		return nil, apperr.FailedPrecondition("promo code already used the maximum number of times")
+	}
+
+	discount := pricing.Discount(promo, appointment.Amount)
+	return &domain.PromoRedemption{
+		PromoCodeId:    promo.ID,
+		Code:           promo.Code,
+		PatientId:      appointment.PatientId,
+		AppointmentId:  appointment.AppointmentId,
+		OriginalAmount: appointment.Amount,
+		DiscountAmount: discount,
+		FinalAmount:    appointment.Amount - discount,
+	}, nil
+}
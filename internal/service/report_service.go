@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/report"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/stats"
+)
+
+// Appointments are read in pages of this size while a report is written
+const reportPageSize = 500
+
+// ReportFileName names an exported report file
+func ReportFileName(kind, format string, from, to time.Time) string {
+	return fmt.Sprintf("%s_%s_%s.%s", kind, from.UTC().Format("20060102"), to.UTC().Format("20060102"), format)
+}
+
+// Export a report over [from, to) as CSV or XLSX, writing rows to w as they are read
+func (s *appointmentService) ExportReport(ctx context.Context, kind, format string, from, to time.Time, w io.Writer) error {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "ExportReport",
+		"Report":   kind,
+		"Format":   format,
+		"From":     from,
+		"To":       to,
+	}).Info("Exporting report")
+
+	columns, ok := report.ColumnsFor(kind)
+	if !ok {
+		return apperr.InvalidArgument(apperr.FieldViolation{Field: "report", Description: "must be appointments, cancellations, revenue_by_specialization or doctor_utilisation"})
+	}
+	if from.IsZero() || to.IsZero() || !to.After(from) {
+		return apperr.InvalidArgument(apperr.FieldViolation{Field: "to", Description: "must be after from"})
+	}
+	if format != report.FormatCSV && format != report.FormatXLSX {
+		return apperr.InvalidArgument(apperr.FieldViolation{Field: "format", Description: "must be csv or xlsx"})
+	}
+	// Rows go out as they are read, so the export is audited before it starts
+	err := s.recordRead(ctx, audit.NewReadEntry(ctx, audit.ActionReportExport, 0, "", map[string]interface{}{"report": kind, "format": format, "from": from, "to": to}))
+	if err != nil {
+		return err
+	}
+	out, err := report.NewWriter(format, w, kind, columns)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case report.Appointments, report.Cancellations:
+		filter := domain.AppointmentFilter{From: from, To: to, Limit: reportPageSize}
+		row := report.AppointmentRow
+		if kind == report.Cancellations {
+			filter.Status = "cancelled"
+			row = report.CancellationRow
+		}
+		for {
+			appointments, next, err := s.repo.SearchAppointments(ctx, filter)
+			if err != nil {
+				s.Logger.WithError(err).Error("Failed to read appointments for report")
+				return err
+			}
+			for _, a := range appointments {
+				if err := out.WriteRow(row(a)); err != nil {
+					return err
+				}
+			}
+			if next == "" {
+				break
+			}
+			filter.Cursor = next
+		}
+	case report.RevenueBySpecialization:
+		specializations, err := s.repo.GetSpecializationStats(ctx, from, to)
+		if err != nil {
+			s.Logger.WithError(err).Error("Failed to read specialization stats for report")
+			return err
+		}
+		for _, sp := range specializations {
+			if err := out.WriteRow(report.RevenueBySpecializationRow(sp)); err != nil {
+				return err
+			}
+		}
+	case report.DoctorUtilisation:
+		summary, err := s.GetStatisticsReport(ctx, from, to, stats.IntervalWeek)
+		if err != nil {
+			return err
+		}
+		for _, d := range summary.Doctors {
+			if err := out.WriteRow(report.DoctorUtilisationRow(d)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+	s.Logger.Info("Report exported successfully")
+	return nil
+}
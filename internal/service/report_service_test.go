@@ -0,0 +1,37 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+)
+
+func TestExportReportRejectsBadArguments(t *testing.T) {
+	s := newTestService(&stubRepo{})
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	cases := []struct {
+		name, kind, format string
+		from, to           time.Time
+	}{
+		{"unknown report", "invoices", "csv", from, to},
+		{"unknown format", "appointments", "pdf", from, to},
+		{"backwards range", "appointments", "csv", to, from},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := s.ExportReport(context.Background(), tc.kind, tc.format, tc.from, tc.to, &buf)
+			if apperr.KindOf(err) != apperr.KindInvalidArgument {
+				t.Fatalf("got %v, want an invalid argument error", err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("nothing should be written for a rejected export, got %d bytes", buf.Len())
+			}
+		})
+	}
+}
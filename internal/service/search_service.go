@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/audit"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// Page sizes for appointment search
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search appointments by patient, doctor, specialization, status, type, payment state and date range
+func (s *appointmentService) SearchAppointments(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":         "SearchAppointments",
+		"PatientId":        filter.PatientId,
+		"DoctorId":         filter.DoctorId,
+		"SpecializationId": filter.SpecializationId,
+		"Status":           filter.Status,
+		"Limit":            filter.Limit,
+	}).Info("Searching appointments")
+
+	appointments, next, err := s.searchAppointments(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.recordRead(ctx, audit.NewReadEntry(ctx, audit.ActionSearch, 0, filter.PatientId, filter)); err != nil {
+		return nil, "", err
+	}
+	return appointments, next, nil
+}
+
+// searchAppointments runs a search without auditing it, for callers that audit their own read
+func (s *appointmentService) searchAppointments(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, string, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	} else if filter.Limit > maxSearchLimit {
+		filter.Limit = maxSearchLimit
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return nil, "", errors.New("to must be after from")
+	}
+
+	appointments, next, err := s.repo.SearchAppointments(ctx, filter)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to search appointments")
+		return nil, "", err
+	}
+	return appointments, next, nil
+}
+
+// Get a patient's past and cancelled visits, newest first, with their outcome
+func (s *appointmentService) GetPatientHistory(ctx context.Context, filter domain.AppointmentFilter) ([]domain.PatientVisit, string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":  "GetPatientHistory",
+		"PatientId": filter.PatientId,
+		"From":      filter.From,
+		"To":        filter.To,
+	}).Info("Fetching patient history")
+
+	if filter.PatientId == "" {
+		return nil, "", errors.New("patient id is required")
+	}
+	if err := s.checkDoctorTreats(ctx, filter.PatientId); err != nil {
+		return nil, "", err
+	}
+	filter.PastOrCancelled = true
+	filter.Descending = true
+	appointments, next, err := s.searchAppointments(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ids := make([]int, 0, len(appointments))
+	for _, a := range appointments {
+		ids = append(ids, a.AppointmentId)
+	}
+	consultations, sessions, err := s.repo.FetchVisitOutcomes(ctx, ids)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch visit outcomes")
+		return nil, "", err
+	}
+	consultationByAppointment := make(map[int]domain.Consultation, len(consultations))
+	for _, c := range consultations {
+		consultationByAppointment[c.AppointmentId] = c
+	}
+	sessionByAppointment := make(map[int]domain.VideoTreatment, len(sessions))
+	for _, v := range sessions {
+		sessionByAppointment[v.AppointmentId] = v
+	}
+
+	// A page usually has a handful of doctors, so look each one up once
+	doctorNames := map[string]string{}
+	visits := make([]domain.PatientVisit, 0, len(appointments))
+	for _, a := range appointments {
+		name, ok := doctorNames[a.DoctorId]
+		if !ok {
+			doctor, err := s.DoctorClient.GetProfile(ctx, &doctorpb.GetProfileRequest{DoctorId: a.DoctorId})
+			if err != nil {
+				s.Logger.WithError(err).Warn("Failed to fetch doctor profile, leaving doctor name empty")
+			} else {
+				name = doctor.Name
+			}
+			doctorNames[a.DoctorId] = name
+		}
+		visit := domain.PatientVisit{Appointment: a, DoctorName: name}
+		if c, ok := consultationByAppointment[a.AppointmentId]; ok {
+			visit.ConsultationId = c.ID
+			visit.PrescriptionCount = len(c.Prescriptions)
+		}
+		if v, ok := sessionByAppointment[a.AppointmentId]; ok {
+			visit.VideoSession = &v
+		}
+		visits = append(visits, visit)
+	}
+	if err := s.recordRead(ctx, audit.NewReadEntry(ctx, audit.ActionHistoryRead, 0, filter.PatientId, filter)); err != nil {
+		return nil, "", err
+	}
+	return visits, next, nil
+}
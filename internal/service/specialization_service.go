@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+)
+
+// List the specializations in display order
+func (a *appointmentService) ListSpecializations(ctx context.Context, includeArchived bool) ([]domain.Specialization, error) {
+	a.Logger.WithFields(logrus.Fields{
+		"Function":        "ListSpecializations",
+		"IncludeArchived": includeArchived,
+	}).Info("Listing specializations")
+
+	specializations, err := a.repo.ListSpecializations(ctx, includeArchived)
+	if err != nil {
+		a.Logger.WithError(err).Error("Failed to list specializations")
+		return nil, err
+	}
+	return specializations, nil
+}
+
+// Get a specialization by id or slug
+func (a *appointmentService) GetSpecialization(ctx context.Context, id uint, slug string) (domain.Specialization, error) {
+	a.Logger.WithFields(logrus.Fields{
+		"Function": "GetSpecialization",
+		"Id":       id,
+		"Slug":     slug,
+	}).Info("Fetching specialization")
+
+	if id == 0 && slug == "" {
+		return domain.Specialization{}, apperr.InvalidArgument(apperr.FieldViolation{Field: "id", Description: "or slug is required"})
+	}
+	specialization, err := a.repo.GetSpecialization(ctx, id, slug)
+	if err != nil {
+		a.Logger.WithError(err).Error("Failed to fetch specialization")
+		return domain.Specialization{}, err
+	}
+	return specialization, nil
+}
+
+// Update the catalogue details of a specialization
+func (a *appointmentService) UpdateSpecialization(ctx context.Context, specialize domain.Specialization) (domain.Specialization, error) {
+	a.Logger.WithFields(logrus.Fields{
+		"Function": "UpdateSpecialization",
+		"Id":       specialize.ID,
+		"Name":     specialize.Name,
+	}).Info("Updating specialization")
+
+	specialize.Name = strings.TrimSpace(specialize.Name)
+	if specialize.Name == "" {
+		return domain.Specialization{}, apperr.InvalidArgument(apperr.FieldViolation{Field: "name", Description: "is required"})
+	}
+	if specialize.Slug == "" {
+		specialize.Slug = slugify(specialize.Name)
+	} else if specialize.Slug != slugify(specialize.Slug) {
+		return domain.Specialization{}, apperr.InvalidArgument(apperr.FieldViolation{Field: "slug", Description: "may only contain lowercase letters, digits and hyphens"})
+	}
+	updated, err := a.repo.UpdateSpecialization(ctx, specialize)
+	if err != nil {
+		a.Logger.WithError(err).Error("Failed to update specialization")
+		return domain.Specialization{}, err
+	}
+
+	a.Logger.Info("Specialization updated successfully")
+	return updated, nil
+}
+
+// Archive a specialization so no new appointments can be booked under it
+func (a *appointmentService) ArchiveSpecialization(ctx context.Context, id uint) error {
+	a.Logger.WithFields(logrus.Fields{
+		"Function": "ArchiveSpecialization",
+		"Id":       id,
+	}).Info("Archiving specialization")
+
+	if err := a.repo.SetSpecializationActive(ctx, id, false); err != nil {
+		a.Logger.WithError(err).Error("Failed to archive specialization")
+		return err
+	}
+	return nil
+}
+
+// Restore an archived specialization
+func (a *appointmentService) RestoreSpecialization(ctx context.Context, id uint) error {
+	a.Logger.WithFields(logrus.Fields{
+		"Function": "RestoreSpecialization",
+		"Id":       id,
+	}).Info("Restoring specialization")
+
+	if err := a.repo.SetSpecializationActive(ctx, id, true); err != nil {
+		a.Logger.WithError(err).Error("Failed to restore specialization")
+		return err
+	}
+	return nil
+}
+
+// checkSpecializationActive rejects bookings and searches under an archived specialization
+func (a *appointmentService) checkSpecializationActive(ctx context.Context, id int32) error {
+	specialization, err := a.repo.GetSpecialization(ctx, uint(id), "")
+	if err != nil {
+		a.Logger.WithError(err).Error("Failed to fetch specialization")
+		return err
+	}
+	if !specialization.IsActive {
+		return apperr.FailedPrecondition("this specialization is no longer available").WithReason("SPECIALIZATION_ARCHIVED", nil)
+	}
+	return nil
+}
+
+// slugify turns a name like "Ear, Nose & Throat" into "ear-nose-throat"
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
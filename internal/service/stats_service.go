@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/stats"
+)
+
+// Build a statistics report for [from, to) with a daily or weekly time series
+func (s *appointmentService) GetStatisticsReport(ctx context.Context, from, to time.Time, interval string) (domain.StatisticsReport, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "GetStatisticsReport",
+		"From":     from,
+		"To":       to,
+		"Interval": interval,
+	}).Info("Building statistics report")
+
+	if interval == "" {
+		interval = stats.IntervalDay
+	}
+	// Series buckets are cut on UTC day and week boundaries
+	from, to = from.UTC(), to.UTC()
+	buckets, err := stats.Buckets(from, to, interval)
+	if err != nil {
+		return domain.StatisticsReport{}, err
+	}
+
+	rows, err := s.repo.GetStatsSeries(ctx, from, to, interval)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch statistics series")
+		return domain.StatisticsReport{}, err
+	}
+	specializations, err := s.repo.GetSpecializationStats(ctx, from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch specialization stats")
+		return domain.StatisticsReport{}, err
+	}
+	doctors, err := s.repo.GetDoctorBookedSlots(ctx, from, to)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch doctor utilisation")
+		return domain.StatisticsReport{}, err
+	}
+
+	report := domain.StatisticsReport{
+		From:            from,
+		To:              to,
+		Interval:        interval,
+		Specializations: specializations,
+	}
+
+	// Fill the series so days or weeks without appointments show up as zeros
+	byStart := make(map[int64]domain.StatsBucket, len(rows))
+	for _, row := range rows {
+		byStart[row.Start.Unix()] = row
+		report.Appointments += row.Appointments
+		report.Completed += row.Completed
+		report.Cancelled += row.Cancelled
+		report.NoShows += row.NoShows
+		report.Revenue += row.Revenue
+	}
+	for _, start := range buckets {
+		bucket := byStart[start.Unix()]
+		bucket.Start = start
+		report.Series = append(report.Series, bucket)
+	}
+	report.CancellationRate = stats.Rate(report.Cancelled, report.Appointments)
+	report.NoShowRate = stats.Rate(report.NoShows, report.Appointments)
+
+	slotsPerDoctor := stats.Days(from, to) * (domain.WorkdayEndHour - domain.WorkdayStartHour)
+	booked := 0
+	for i := range doctors {
+		doctors[i].AvailableSlots = slotsPerDoctor
+		doctors[i].Utilisation = stats.Rate(doctors[i].BookedSlots, slotsPerDoctor)
+		booked += doctors[i].BookedSlots
+	}
+	report.Doctors = doctors
+	report.Utilisation = stats.Rate(booked, slotsPerDoctor*len(doctors))
+
+	s.Logger.Info("Statistics report built successfully")
+	return report, nil
+}
+
+// Rebuild the daily statistics rollups from scratch; run nightly to pick up changes made outside the service
+func (s *appointmentService) RebuildRollups() {
+	s.Logger.Info("Rebuilding statistics rollups")
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Job)
+	defer cancel()
+
+	start := time.Now()
+	if err := s.repo.RebuildRollups(ctx); err != nil {
+		s.Logger.WithError(err).Error("Failed to rebuild statistics rollups")
+		return
+	}
+	s.Logger.WithFields(logrus.Fields{
+		"Function": "RebuildRollups",
+		"Duration": time.Since(start).String(),
+	}).Info("Statistics rollups rebuilt successfully")
+}
+
+// Sections of the statistics dashboard, reported in StatisticsData.Unavailable when they fail
+const (
+	SectionSpecializations = "specializations"
+	SectionAppointments    = "appointments"
+	SectionPatients        = "patients"
+	SectionDoctors         = "doctors"
+	SectionRevenue         = "revenue"
+)
+
+// statisticsSections lists every section in the order Unavailable reports them
+var statisticsSections = []string{SectionSpecializations, SectionAppointments, SectionPatients, SectionDoctors, SectionRevenue}
+
+const (
+	// defaultStatsCallTimeout bounds each dependency of the statistics dashboard
+	defaultStatsCallTimeout = 2 * time.Second
+	// defaultJobTimeout bounds one run of a scheduled job
+	defaultJobTimeout = 10 * time.Minute
+	// countCacheTTL is how long patient and doctor counts are reused
+	countCacheTTL = time.Minute
+)
+
+// Fetch statistics details. Sections are fetched concurrently; a section that
+// fails or times out is listed in Unavailable and the rest are still returned.
+// An error is returned only when every section failed.
+func (a *appointmentService) FetchStatisticsDetails(ctx context.Context, param string) ([]domain.SpecializationStats, domain.StatisticsData, error) {
+	a.Logger.WithFields(logrus.Fields{
+		"Function": "FetchStatisticsDetails",
+		"Param":    param,
+	}).Info("Fetching statistics details")
+
+	// Rollups are kept per clinic day, so the window is cut in the clinic time zone too
+	from, to := stats.Window(param, a.clinicNow())
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		special  []domain.SpecializationStats
+		data     domain.StatisticsData
+		revenue  domain.StatisticsData
+		failures = map[string]error{}
+	)
+	run := func(section string, call func(ctx context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := withDeadline(ctx, a.timeouts.StatsCall, func(ctx context.Context) (struct{}, error) {
+				return struct{}{}, call(ctx)
+			})
+			if err != nil {
+				mu.Lock()
+				failures[section] = err
+				mu.Unlock()
+			}
+		}()
+	}
+
+	run(SectionSpecializations, func(ctx context.Context) error {
+		result, err := a.repo.GetRollupSpecializationStats(ctx, from, to)
+		mu.Lock()
+		special = result
+		mu.Unlock()
+		return err
+	})
+	run(SectionAppointments, func(ctx context.Context) error {
+		count, err := a.repo.GetRollupTotal(ctx, from, to)
+		mu.Lock()
+		data.TotalAppointments = count
+		mu.Unlock()
+		return err
+	})
+	run(SectionPatients, func(ctx context.Context) error {
+		count, err := a.cachedCount("patients", func() (int, error) {
+			resp, err := a.PatientClient.GetTotalPatient(ctx, &patientpb.GetTotalPatientCountRequest{})
+			if err != nil {
+				return 0, err
+			}
+			return int(resp.PatientCount), nil
+		})
+		mu.Lock()
+		data.TotalPatients = count
+		mu.Unlock()
+		return err
+	})
+	run(SectionDoctors, func(ctx context.Context) error {
+		count, err := a.cachedCount("doctors:"+param, func() (int, error) {
+			resp, err := a.DoctorClient.GetTotalDoctor(ctx, &doctorpb.GetTotalDoctorCountRequest{Param: param})
+			if err != nil {
+				return 0, err
+			}
+			return int(resp.DoctorCount), nil
+		})
+		mu.Lock()
+		data.TotalDoctors = count
+		mu.Unlock()
+		return err
+	})
+	run(SectionRevenue, func(ctx context.Context) error {
+		result, err := a.fetchRevenueStatistics(ctx, param)
+		mu.Lock()
+		revenue = result
+		mu.Unlock()
+		return err
+	})
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	// A call that timed out may still finish later, so clear whatever it left behind
+	if _, failed := failures[SectionSpecializations]; failed {
+		special = nil
+	}
+	if _, failed := failures[SectionAppointments]; failed {
+		data.TotalAppointments = 0
+	}
+	if _, failed := failures[SectionPatients]; failed {
+		data.TotalPatients = 0
+	}
+	if _, failed := failures[SectionDoctors]; failed {
+		data.TotalDoctors = 0
+	}
+	if _, failed := failures[SectionRevenue]; !failed {
+		data.TotalRevenue = revenue.TotalRevenue
+		data.CollectedRevenue = revenue.CollectedRevenue
+		data.ExpectedRevenue = revenue.ExpectedRevenue
+		data.ClinicCollected = revenue.ClinicCollected
+		data.ClinicOutstanding = revenue.ClinicOutstanding
+	}
+	for _, section := range statisticsSections {
+		if err, failed := failures[section]; failed {
+			a.Logger.WithError(err).WithField("Section", section).Error("Failed to fetch statistics section")
+			data.Unavailable = append(data.Unavailable, section)
+		}
+	}
+	if len(data.Unavailable) == len(statisticsSections) {
+		return nil, domain.StatisticsData{}, apperr.Unavailable("statistics are unavailable right now", nil)
+	}
+
+	a.Logger.Info("Statistics details fetched successfully")
+	return special, data, nil
+}
+
+// cachedCount returns a count from the short-lived cache, fetching it on a miss
+func (a *appointmentService) cachedCount(key string, fetch func() (int, error)) (int, error) {
+	if count, ok := a.countCache.Get(key); ok {
+		return count, nil
+	}
+	count, err := fetch()
+	if err != nil {
+		return 0, err
+	}
+	a.countCache.Set(key, count)
+	return count, nil
+}
+
+// withDeadline runs call with a context that expires after timeout, or earlier
+// if parent is done, and stops waiting for it once that happens
+func withDeadline[T any](parent context.Context, timeout time.Duration, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call(ctx)
+		done <- result{value, err}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
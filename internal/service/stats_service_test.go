@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
+	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+	"google.golang.org/grpc"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/repository"
+)
+
+var errSectionDown = errors.New("section down")
+
+// statsRepo answers the dashboard queries, failing the sections in fail and
+// recording the window the rollups were read for
+type statsRepo struct {
+	repository.AppointmentRepository
+
+	fail     map[string]bool
+	mu       sync.Mutex
+	from, to time.Time
+}
+
+func (r *statsRepo) GetRollupSpecializationStats(ctx context.Context, from, to time.Time) ([]domain.SpecializationStats, error) {
+	r.mu.Lock()
+	r.from, r.to = from, to
+	r.mu.Unlock()
+	if r.fail[SectionSpecializations] {
+		return nil, errSectionDown
+	}
+	return []domain.SpecializationStats{{Name: "General Medicine", Count: 4}}, nil
+}
+
+func (r *statsRepo) GetRollupTotal(ctx context.Context, from, to time.Time) (int, error) {
+	if r.fail[SectionAppointments] {
+		return 0, errSectionDown
+	}
+	return 4, nil
+}
+
+func (r *statsRepo) GetRevenueStats(ctx context.Context, from, to time.Time) (domain.StatisticsData, error) {
+	if r.fail[SectionRevenue] {
+		return domain.StatisticsData{}, errSectionDown
+	}
+	return domain.StatisticsData{ClinicCollected: 200, ExpectedRevenue: 900}, nil
+}
+
+type countsPatientClient struct {
+	patientpb.PatientServiceClient
+	err error
+}
+
+func (c *countsPatientClient) GetTotalPatient(ctx context.Context, in *patientpb.GetTotalPatientCountRequest, opts ...grpc.CallOption) (*patientpb.GetTotalPatientCountResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &patientpb.GetTotalPatientCountResponse{PatientCount: 10}, nil
+}
+
+type countsDoctorClient struct {
+	doctorpb.DoctorServiceClient
+	err error
+}
+
+func (c *countsDoctorClient) GetTotalDoctor(ctx context.Context, in *doctorpb.GetTotalDoctorCountRequest, opts ...grpc.CallOption) (*doctorpb.GetTotalDoctorCountResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &doctorpb.GetTotalDoctorCountResponse{DoctorCount: 3}, nil
+}
+
+type revenuePaymentClient struct {
+	paymentpb.PaymentServiceClient
+}
+
+func (c *revenuePaymentClient) GetTotalRevenue(ctx context.Context, in *paymentpb.GetTotalRevenueRequest, opts ...grpc.CallOption) (*paymentpb.GetTotalRevenueResponse, error) {
+	return &paymentpb.GetTotalRevenueResponse{TotalRevenue: 1000}, nil
+}
+
+func newStatsService(repo *statsRepo) *appointmentService {
+	s := newTestService(repo)
+	s.PaymentClient = &revenuePaymentClient{}
+	s.PatientClient = &countsPatientClient{}
+	s.DoctorClient = &countsDoctorClient{}
+	if repo.fail[SectionPatients] {
+		s.PatientClient = &countsPatientClient{err: errSectionDown}
+	}
+	if repo.fail[SectionDoctors] {
+		s.DoctorClient = &countsDoctorClient{err: errSectionDown}
+	}
+	return s
+}
+
+func TestFetchStatisticsDetailsReportsFailedSections(t *testing.T) {
+	repo := &statsRepo{fail: map[string]bool{SectionPatients: true, SectionRevenue: true}}
+	special, data, err := newStatsService(repo).FetchStatisticsDetails(context.Background(), "month")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{SectionPatients, SectionRevenue}; !reflect.DeepEqual(data.Unavailable, want) {
+		t.Errorf("Unavailable = %v, want %v", data.Unavailable, want)
+	}
+	if data.TotalPatients != 0 || data.TotalRevenue != 0 {
+		t.Errorf("failed sections should be left empty, got %+v", data)
+	}
+	if len(special) != 1 || data.TotalAppointments != 4 || data.TotalDoctors != 3 {
+		t.Errorf("sections that succeeded should be returned, got %+v %+v", special, data)
+	}
+}
+
+func TestFetchStatisticsDetailsFailsWhenEverySectionFails(t *testing.T) {
+	fail := map[string]bool{}
+	for _, section := range statisticsSections {
+		fail[section] = true
+	}
+	_, _, err := newStatsService(&statsRepo{fail: fail}).FetchStatisticsDetails(context.Background(), "month")
+	if apperr.KindOf(err) != apperr.KindUnavailable {
+		t.Fatalf("got %v, want an unavailable error", err)
+	}
+}
+
+func TestFetchStatisticsDetailsCutsWindowInClinicZone(t *testing.T) {
+	zone, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &statsRepo{}
+	s := newStatsService(repo)
+	s.clinicZone = zone
+
+	before := time.Now()
+	if _, _, err := s.FetchStatisticsDetails(context.Background(), "day"); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now()
+
+	local := repo.from.In(zone)
+	if local.Hour() != 0 || local.Minute() != 0 || local.Second() != 0 {
+		t.Errorf("day should start at clinic midnight, got %v", local)
+	}
+	if repo.to.Sub(repo.from) != 24*time.Hour {
+		t.Errorf("day window is %v long", repo.to.Sub(repo.from))
+	}
+	if repo.from.After(before) || !repo.to.After(after) {
+		t.Errorf("window [%v, %v) should contain now", repo.from, repo.to)
+	}
+	if _, offset := repo.from.Zone(); offset != 5*3600+1800 {
+		t.Errorf("window should be cut in the clinic zone, got offset %d", offset)
+	}
+}
+
+// slowTotalRepo never answers the appointment count until its context ends
+type slowTotalRepo struct {
+	statsRepo
+}
+
+func (r *slowTotalRepo) GetRollupTotal(ctx context.Context, from, to time.Time) (int, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestFetchStatisticsDetailsBoundsSlowSections(t *testing.T) {
+	s := newStatsService(&statsRepo{})
+	s.repo = &slowTotalRepo{}
+	s.timeouts.StatsCall = 20 * time.Millisecond
+
+	start := time.Now()
+	_, data, err := s.FetchStatisticsDetails(context.Background(), "month")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data.Unavailable, []string{SectionAppointments}) {
+		t.Errorf("Unavailable = %v, want only %s", data.Unavailable, SectionAppointments)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("a slow section held up the dashboard past its deadline")
+	}
+}
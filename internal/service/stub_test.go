@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	doctorpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/doctor"
+	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
+	paymentpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/payment"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/cache"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/repository"
+)
+
+// stubRepo implements the repository methods a test sets and panics on any
+// other call, through the nil embedded interface
+type stubRepo struct {
+	repository.AppointmentRepository
+
+	fetchClaims                    func(ctx context.Context, payerId string, from, to time.Time) ([]domain.Appointment, error)
+	getAppointmentById             func(ctx context.Context, appointmentId int) (domain.Appointment, error)
+	saveVideoAppointment           func(ctx context.Context, roomid string, appointmentid, specializationId int) (domain.VideoTreatment, bool, error)
+	getVideoTreatmentByAppointment func(ctx context.Context, appointmentId int) (domain.VideoTreatment, error)
+	closeVideoParticipant          func(ctx context.Context, roomId, participantId string, leftAt time.Time) error
+	fetchVideoParticipants         func(ctx context.Context, roomId string) ([]domain.VideoParticipant, error)
+	getVideoTreatment              func(ctx context.Context, roomId string) (domain.VideoTreatment, error)
+	addVideoParticipant            func(ctx context.Context, participant domain.VideoParticipant) error
+	joinVideoTreatment             func(ctx context.Context, roomId, role string, at time.Time) (domain.VideoTreatment, error)
+	endVideoTreatment              func(ctx context.Context, roomId string, endedAt time.Time, consultationSeconds int) (domain.VideoTreatment, error)
+	updateAppointmentStatus        func(ctx context.Context, appointmentId int, from, status string) error
+	markVideoPatientNotified       func(ctx context.Context, roomId string, at time.Time) error
+	getAppointmentDetails          func(ctx context.Context, orderid string) (domain.Appointment, error)
+	recordAudit                    func(ctx context.Context, entry domain.AuditEntry) error
+	fetchAuditChain                func(ctx context.Context, afterId uint, limit int) ([]domain.AuditEntry, error)
+	fetchAuditHead                 func(ctx context.Context) (domain.AuditEntry, error)
+	saveAuditAnchor                func(ctx context.Context, anchor domain.AuditAnchor) error
+	fetchAuditAnchors              func(ctx context.Context) ([]domain.AuditAnchor, error)
+	doctorTreatsPatient            func(ctx context.Context, doctorId, patientId string) (bool, error)
+	fetchConsultationsByPatient    func(ctx context.Context, patientId string) ([]domain.Consultation, error)
+}
+
+func (r *stubRepo) FetchClaims(ctx context.Context, payerId string, from, to time.Time) ([]domain.Appointment, error) {
+	return r.fetchClaims(ctx, payerId, from, to)
+}
+
+func (r *stubRepo) GetAppointmentById(ctx context.Context, appointmentId int) (domain.Appointment, error) {
+	return r.getAppointmentById(ctx, appointmentId)
+}
+
+func (r *stubRepo) SaveVideoAppointment(ctx context.Context, roomid string, appointmentid, specializationId int) (domain.VideoTreatment, bool, error) {
+	return r.saveVideoAppointment(ctx, roomid, appointmentid, specializationId)
+}
+
+func (r *stubRepo) GetVideoTreatmentByAppointment(ctx context.Context, appointmentId int) (domain.VideoTreatment, error) {
+	return r.getVideoTreatmentByAppointment(ctx, appointmentId)
+}
+
+func (r *stubRepo) CloseVideoParticipant(ctx context.Context, roomId, participantId string, leftAt time.Time) error {
+	return r.closeVideoParticipant(ctx, roomId, participantId, leftAt)
+}
+
+func (r *stubRepo) FetchVideoParticipants(ctx context.Context, roomId string) ([]domain.VideoParticipant, error) {
+	return r.fetchVideoParticipants(ctx, roomId)
+}
+
+func (r *stubRepo) GetVideoTreatment(ctx context.Context, roomId string) (domain.VideoTreatment, error) {
+	return r.getVideoTreatment(ctx, roomId)
+}
+
+func (r *stubRepo) AddVideoParticipant(ctx context.Context, participant domain.VideoParticipant) error {
+	return r.addVideoParticipant(ctx, participant)
+}
+
+func (r *stubRepo) JoinVideoTreatment(ctx context.Context, roomId, role string, at time.Time) (domain.VideoTreatment, error) {
+	return r.joinVideoTreatment(ctx, roomId, role, at)
+}
+
+func (r *stubRepo) EndVideoTreatment(ctx context.Context, roomId string, endedAt time.Time, consultationSeconds int) (domain.VideoTreatment, error) {
+	return r.endVideoTreatment(ctx, roomId, endedAt, consultationSeconds)
+}
+
+func (r *stubRepo) UpdateAppointmentStatus(ctx context.Context, appointmentId int, from, status string) error {
+	return r.updateAppointmentStatus(ctx, appointmentId, from, status)
+}
+
+func (r *stubRepo) MarkVideoPatientNotified(ctx context.Context, roomId string, at time.Time) error {
+	return r.markVideoPatientNotified(ctx, roomId, at)
+}
+
+func (r *stubRepo) GetAppointmentDetails(ctx context.Context, orderid string) (domain.Appointment, error) {
+	return r.getAppointmentDetails(ctx, orderid)
+}
+
+func (r *stubRepo) RecordAudit(ctx context.Context, entry domain.AuditEntry) error {
+	return r.recordAudit(ctx, entry)
+}
+
+func (r *stubRepo) FetchAuditChain(ctx context.Context, afterId uint, limit int) ([]domain.AuditEntry, error) {
+	return r.fetchAuditChain(ctx, afterId, limit)
+}
+
+func (r *stubRepo) FetchAuditHead(ctx context.Context) (domain.AuditEntry, error) {
+	return r.fetchAuditHead(ctx)
+}
+
+func (r *stubRepo) SaveAuditAnchor(ctx context.Context, anchor domain.AuditAnchor) error {
+	return r.saveAuditAnchor(ctx, anchor)
+}
+
+func (r *stubRepo) DoctorTreatsPatient(ctx context.Context, doctorId, patientId string) (bool, error) {
+	return r.doctorTreatsPatient(ctx, doctorId, patientId)
+}
+
+func (r *stubRepo) FetchConsultationsByPatient(ctx context.Context, patientId string) ([]domain.Consultation, error) {
+	return r.fetchConsultationsByPatient(ctx, patientId)
+}
+
+func (r *stubRepo) FetchAuditAnchors(ctx context.Context) ([]domain.AuditAnchor, error) {
+	return r.fetchAuditAnchors(ctx)
+}
+
+// stubPatientClient answers GetProfile with profile and panics on any other call
+type stubPatientClient struct {
+	patientpb.PatientServiceClient
+
+	profile *patientpb.GetProfileResponse
+}
+
+func (c *stubPatientClient) GetProfile(ctx context.Context, in *patientpb.GetProfileRequest, opts ...grpc.CallOption) (*patientpb.GetProfileResponse, error) {
+	return c.profile, nil
+}
+
+// stubDoctorClient reports the doctor as available every day and panics on any other call
+type stubDoctorClient struct {
+	doctorpb.DoctorServiceClient
+}
+
+func (c *stubDoctorClient) CheckAvailabilityByDoctorId(ctx context.Context, in *doctorpb.CheckAvailabilityByDoctorIdRequest, opts ...grpc.CallOption) (*doctorpb.CheckAvailabilityByDoctorIdResponse, error) {
+	return &doctorpb.CheckAvailabilityByDoctorIdResponse{}, nil
+}
+
+// stubPaymentClient creates payment orders through createOrder, counting the calls
+type stubPaymentClient struct {
+	paymentpb.PaymentServiceClient
+
+	createOrder func(in *paymentpb.CreateRazorOrderIdRequest) (*paymentpb.CreateRazorOrderIdResponse, error)
+	orders      int
+}
+
+func (c *stubPaymentClient) CreateRazorOrderId(ctx context.Context, in *paymentpb.CreateRazorOrderIdRequest, opts ...grpc.CallOption) (*paymentpb.CreateRazorOrderIdResponse, error) {
+	c.orders++
+	return c.createOrder(in)
+}
+
+// newTestService builds a service around repo with a silent logger and no
+// downstream clients; a test that reaches a client it did not set panics
+func newTestService(repo repository.AppointmentRepository) *appointmentService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &appointmentService{
+		repo:       repo,
+		Logger:     logger,
+		countCache: cache.NewTTL[int](countCacheTTL),
+		timeouts:   Timeouts{StatsCall: defaultStatsCallTimeout, Job: defaultJobTimeout},
+	}
+}
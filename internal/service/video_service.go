@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/video"
+)
+
+// Create the video room of an appointment and send the patient their link.
+// Calling it again returns the existing room, and only notifies the patient
+// if no earlier notification went out.
+func (s *appointmentService) CreateVideoRoom(ctx context.Context, appointmentId int, doctorId string) (string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "CreateVideoRoom",
+		"AppointmentId": appointmentId,
+		"DoctorId":      doctorId,
+	}).Info("Creating video room for appointment")
+
+	appointment, err := s.repo.GetAppointmentById(ctx, appointmentId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch appointment")
+		return "", err
+	}
+	if appointment.DoctorId != doctorId {
+		return "", apperr.NotFound("appointment not found")
+	}
+	if err := checkVideoAppointment(appointment); err != nil {
+		return "", err
+	}
+
+	session, _, err := s.repo.SaveVideoAppointment(ctx, uuid.New().String(), appointment.AppointmentId, int(appointment.SpecializationId))
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to save video appointment")
+		return "", err
+	}
+
+	room := videoRoom(session.VideoTreatmentId, appointment)
+	roomURL, err := s.VideoProvider.JoinURL(room, video.Participant{Id: appointment.DoctorId, Role: video.RoleDoctor})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to issue doctor video link")
+		return "", err
+	}
+	if session.PatientNotifiedAt != nil {
+		s.Logger.Info("Video room already exists for appointment")
+		return roomURL, nil
+	}
+
+	profile, err := s.PatientClient.GetProfile(ctx, &patientpb.GetProfileRequest{PatientId: appointment.PatientId})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch patient profile")
+		return "", err
+	}
+	patientRoomURL, err := s.VideoProvider.JoinURL(room, video.Participant{Id: appointment.PatientId, Name: profile.Name, Email: profile.Email, Role: video.RolePatient})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to issue patient video link")
+		return "", err
+	}
+	err = s.notify(ctx, "appointment_topic", domain.AppointmentEvent{
+		AppointmentId:   appointment.AppointmentId,
+		Email:           profile.Email,
+		VideoURL:        patientRoomURL,
+		DoctorId:        appointment.DoctorId,
+		AppointmentDate: appointment.AppointmentTime.Format("2006-01-02"),
+		Type:            appointment.Type,
+	})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to produce video appointment event")
+		return "", apperr.Unavailable("failed to produce video appointment event", err)
+	}
+	if err := s.repo.MarkVideoPatientNotified(ctx, session.VideoTreatmentId, time.Now()); err != nil {
+		// The link went out; a later call may send it again, which is harmless
+		s.Logger.WithError(err).Error("Failed to record video room notification")
+	}
+
+	s.Logger.Info("Video treatment room created successfully")
+	return roomURL, nil
+}
+
+// Get the patient's link to the video room of their appointment
+func (s *appointmentService) GetVideoRoom(ctx context.Context, appointmentId int, patientId string) (string, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "GetVideoRoom",
+		"AppointmentId": appointmentId,
+		"PatientId":     patientId,
+	}).Info("Fetching video room for appointment")
+
+	appointment, err := s.repo.GetAppointmentById(ctx, appointmentId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch appointment")
+		return "", err
+	}
+	if appointment.PatientId != patientId {
+		return "", apperr.NotFound("appointment not found")
+	}
+	if err := checkVideoAppointment(appointment); err != nil {
+		return "", err
+	}
+	session, err := s.repo.GetVideoTreatmentByAppointment(ctx, appointmentId)
+	if err != nil {
+		return "", err
+	}
+
+	profile, err := s.PatientClient.GetProfile(ctx, &patientpb.GetProfileRequest{PatientId: patientId})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch patient profile")
+		return "", err
+	}
+	roomURL, err := s.VideoProvider.JoinURL(videoRoom(session.VideoTreatmentId, appointment), video.Participant{Id: patientId, Name: profile.Name, Email: profile.Email, Role: video.RolePatient})
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to issue patient video link")
+		return "", err
+	}
+
+	s.Logger.Info("Video room fetched successfully")
+	return roomURL, nil
+}
+
+// checkVideoAppointment makes sure a room can be opened for the appointment right now
+func checkVideoAppointment(appointment domain.Appointment) error {
+	if appointment.Type != domain.AppointmentTypeVideo {
+		return apperr.FailedPrecondition("this is not a video appointment")
+	}
+	switch appointment.Status {
+	case "confirmed":
+	case "Pending", "pending":
+		return apperr.FailedPrecondition("this appointment payment not completed").WithReason("PAYMENT_PENDING", nil)
+	case "cancelled":
+		return apperr.FailedPrecondition("this appointment is cancelled").WithReason("STATUS_CHANGED", map[string]string{"status": appointment.Status})
+	default:
+		return apperr.FailedPrecondition("this appointment is "+appointment.Status).WithReason("STATUS_CHANGED", map[string]string{"status": appointment.Status})
+	}
+	if !time.Now().Before(videoRoom("", appointment).ExpiresAt) {
+		return apperr.FailedPrecondition("this appointment is already over")
+	}
+	return nil
+}
+
+// Record a doctor or patient joining the video room of their appointment
+func (s *appointmentService) JoinVideoSession(ctx context.Context, roomId, participantId string) (domain.VideoTreatment, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "JoinVideoSession",
+		"RoomId":        roomId,
+		"ParticipantId": participantId,
+	}).Info("Participant joining video session")
+
+	session, appointment, err := s.videoSessionWithAppointment(ctx, roomId)
+	if err != nil {
+		return domain.VideoTreatment{}, err
+	}
+	if session.Status == "ended" {
+		return domain.VideoTreatment{}, apperr.FailedPrecondition("this video session has already ended")
+	}
+	role, err := participantRole(appointment, participantId)
+	if err != nil {
+		return domain.VideoTreatment{}, err
+	}
+	// Joining is only possible for a confirmed appointment, inside its slot
+	if err := checkVideoAppointment(appointment); err != nil {
+		return domain.VideoTreatment{}, err
+	}
+	now := time.Now()
+	if now.Before(videoRoom(roomId, appointment).NotBefore) {
+		return domain.VideoTreatment{}, apperr.FailedPrecondition("this appointment has not started yet")
+	}
+
+	if err := s.repo.AddVideoParticipant(ctx, domain.VideoParticipant{
+		VideoTreatmentId: roomId,
+		ParticipantId:    participantId,
+		Role:             role,
+		JoinedAt:         now,
+	}); err != nil {
+		s.Logger.WithError(err).Error("Failed to record participant join")
+		return domain.VideoTreatment{}, err
+	}
+
+	session, err = s.repo.JoinVideoTreatment(ctx, roomId, role, now)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to update video session")
+		return domain.VideoTreatment{}, err
+	}
+
+	s.Logger.Info("Participant joined video session")
+	return session, nil
+}
+
+// Record a participant leaving the video room
+func (s *appointmentService) LeaveVideoSession(ctx context.Context, roomId, participantId string) error {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "LeaveVideoSession",
+		"RoomId":        roomId,
+		"ParticipantId": participantId,
+	}).Info("Participant leaving video session")
+
+	_, appointment, err := s.videoSessionWithAppointment(ctx, roomId)
+	if err != nil {
+		return err
+	}
+	if _, err := participantRole(appointment, participantId); err != nil {
+		return err
+	}
+	if err := s.repo.CloseVideoParticipant(ctx, roomId, participantId, time.Now()); err != nil {
+		s.Logger.WithError(err).Error("Failed to record participant leave")
+		return err
+	}
+
+	s.Logger.Info("Participant left video session")
+	return nil
+}
+
+// End the video session; only the doctor of the appointment can end it
+func (s *appointmentService) EndVideoSession(ctx context.Context, roomId, participantId string) (domain.VideoTreatment, error) {
+	s.Logger.WithFields(logrus.Fields{
+		"Function":      "EndVideoSession",
+		"RoomId":        roomId,
+		"ParticipantId": participantId,
+	}).Info("Ending video session")
+
+	session, appointment, err := s.videoSessionWithAppointment(ctx, roomId)
+	if err != nil {
+		return domain.VideoTreatment{}, err
+	}
+	if appointment.DoctorId != participantId {
+		return domain.VideoTreatment{}, apperr.PermissionDenied("only the doctor can end the video session")
+	}
+	if session.Status == "ended" {
+		return session, nil
+	}
+
+	session, err = s.finishVideoSession(ctx, session, appointment, time.Now())
+	if err != nil {
+		return domain.VideoTreatment{}, err
+	}
+	s.Logger.Info("Video session ended successfully")
+	return session, nil
+}
+
+// Close sessions whose appointment slot is over, flagging the side that never joined
+func (s *appointmentService) CloseStaleVideoSessions() {
+	s.Logger.Info("Closing stale video sessions")
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Job)
+	defer cancel()
+
+	now := time.Now()
+	sessions, err := s.repo.FetchStaleVideoSessions(ctx, now)
+	if err != nil {
+		s.Logger.WithError(err).Error("Error fetching stale video sessions")
+		return
+	}
+	for _, session := range sessions {
+		appointment, err := s.repo.GetAppointmentById(ctx, session.AppointmentId)
+		if err != nil {
+			s.Logger.WithError(err).Warn("Failed to fetch appointment of video session, skipping")
+			continue
+		}
+		if _, err := s.finishVideoSession(ctx, session, appointment, now); err != nil {
+			s.Logger.WithError(err).Warn("Failed to close stale video session")
+		}
+	}
+
+	s.Logger.Info("Stale video sessions closed")
+}
+
+func (s *appointmentService) videoSessionWithAppointment(ctx context.Context, roomId string) (domain.VideoTreatment, domain.Appointment, error) {
+	session, err := s.repo.GetVideoTreatment(ctx, roomId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch video session")
+		return domain.VideoTreatment{}, domain.Appointment{}, err
+	}
+	appointment, err := s.repo.GetAppointmentById(ctx, session.AppointmentId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch appointment of video session")
+		return domain.VideoTreatment{}, domain.Appointment{}, err
+	}
+	return session, appointment, nil
+}
+
+func participantRole(appointment domain.Appointment, participantId string) (string, error) {
+	switch participantId {
+	case appointment.DoctorId:
+		return "doctor", nil
+	case appointment.PatientId:
+		return "patient", nil
+	}
+	return "", apperr.PermissionDenied("participant is not part of this appointment")
+}
+
+// finishVideoSession closes open stays, works out how long doctor and patient
+// were actually together and marks no-shows on the appointment.
+func (s *appointmentService) finishVideoSession(ctx context.Context, session domain.VideoTreatment, appointment domain.Appointment, now time.Time) (domain.VideoTreatment, error) {
+	if err := s.repo.CloseVideoParticipant(ctx, session.VideoTreatmentId, "", now); err != nil {
+		s.Logger.WithError(err).Error("Failed to close video participants")
+		return domain.VideoTreatment{}, err
+	}
+	participants, err := s.repo.FetchVideoParticipants(ctx, session.VideoTreatmentId)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to fetch video participants")
+		return domain.VideoTreatment{}, err
+	}
+
+	seconds := int(consultationDuration(participants, now).Seconds())
+	session, err = s.repo.EndVideoTreatment(ctx, session.VideoTreatmentId, now, seconds)
+	if err != nil {
+		s.Logger.WithError(err).Error("Failed to update video session")
+		return domain.VideoTreatment{}, err
+	}
+
+	status := ""
+	if session.DoctorNoShow {
+		status = "doctor_no_show"
+	} else if session.PatientNoShow {
+		status = "patient_no_show"
+	}
+	// Only a confirmed appointment can turn into a no-show; a cancelled or
+	// completed one keeps its status and raises no refund event
+	if status == "" || appointment.Status != "confirmed" {
+		return session, nil
+	}
+	if err := s.repo.UpdateAppointmentStatus(ctx, appointment.AppointmentId, "confirmed", status); err != nil {
+		if apperr.KindOf(err) == apperr.KindFailedPrecondition {
+			s.Logger.WithError(err).Info("Appointment changed status meanwhile, not marking no-show")
+			return session, nil
+		}
+		s.Logger.WithError(err).Error("Failed to mark appointment no-show")
+		return domain.VideoTreatment{}, err
+	}
+
+	// A doctor no-show makes the patient eligible for a refund
+	if session.DoctorNoShow {
+		profile, err := s.PatientClient.GetProfile(ctx, &patientpb.GetProfileRequest{PatientId: appointment.PatientId})
+		if err != nil {
+			s.Logger.WithError(err).Warn("Failed to fetch patient profile, skipping no-show event")
+			return session, nil
+		}
+		err = s.notify(ctx, "completion_topic", domain.AppointmentEvent{
+			AppointmentId:   appointment.AppointmentId,
+			Email:           profile.Email,
+			DoctorId:        appointment.DoctorId,
+			AppointmentDate: appointment.AppointmentTime.Format("2006-01-02"),
+			Type:            appointment.Type,
+			Status:          status,
+		})
+		if err != nil {
+			s.Logger.WithError(err).Warn("Failed to produce no-show event")
+		}
+	}
+	return session, nil
+}
+
+// consultationDuration sums the time during which both a doctor and a patient were in the room
+func consultationDuration(participants []domain.VideoParticipant, end time.Time) time.Duration {
+	type edge struct {
+		at    time.Time
+		role  string
+		delta int
+	}
+	var edges []edge
+	for _, p := range participants {
+		left := end
+		if p.LeftAt != nil {
+			left = *p.LeftAt
+		}
+		edges = append(edges, edge{p.JoinedAt, p.Role, 1}, edge{left, p.Role, -1})
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	var total time.Duration
+	var since time.Time
+	present := map[string]int{}
+	for _, e := range edges {
+		before := present["doctor"] > 0 && present["patient"] > 0
+		present[e.role] += e.delta
+		after := present["doctor"] > 0 && present["patient"] > 0
+		if !before && after {
+			since = e.at
+		} else if before && !after {
+			total += e.at.Sub(since)
+		}
+	}
+	return total
+}
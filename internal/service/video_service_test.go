@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	patientpb "github.com/NUHMANUDHEENT/hosp-connect-pb/proto/patient"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/domain"
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/video"
+)
+
+// unattendedSessionRepo ends a session only the given participants joined, flagging
+// no-shows as EndVideoTreatment does, and records status changes
+func unattendedSessionRepo(doctorJoined bool, statusErr error, changes *[]string) *stubRepo {
+	return &stubRepo{
+		closeVideoParticipant: func(context.Context, string, string, time.Time) error { return nil },
+		fetchVideoParticipants: func(context.Context, string) ([]domain.VideoParticipant, error) {
+			return nil, nil
+		},
+		endVideoTreatment: func(_ context.Context, roomId string, endedAt time.Time, seconds int) (domain.VideoTreatment, error) {
+			return domain.VideoTreatment{
+				VideoTreatmentId:    roomId,
+				Status:              "ended",
+				EndedAt:             &endedAt,
+				ConsultationSeconds: seconds,
+				DoctorJoined:        doctorJoined,
+				DoctorNoShow:        !doctorJoined,
+				PatientNoShow:       doctorJoined,
+			}, nil
+		},
+		updateAppointmentStatus: func(_ context.Context, _ int, from, status string) error {
+			*changes = append(*changes, from+"->"+status)
+			return statusErr
+		},
+	}
+}
+
+func TestFinishVideoSessionLeavesClosedAppointmentsAlone(t *testing.T) {
+	for _, status := range []string{"cancelled", "completed", "doctor_no_show", "pending"} {
+		t.Run(status, func(t *testing.T) {
+			var changes []string
+			s := newTestService(unattendedSessionRepo(false, nil, &changes))
+			appointment := domain.Appointment{AppointmentId: 7, Status: status, PatientId: "p1", DoctorId: "d1"}
+
+			// PatientClient is nil: raising the refund event would panic
+			session, err := s.finishVideoSession(context.Background(), domain.VideoTreatment{VideoTreatmentId: "room"}, appointment, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !session.DoctorNoShow || session.Status != "ended" {
+				t.Errorf("session should still be ended and flagged, got %+v", session)
+			}
+			if len(changes) != 0 {
+				t.Errorf("appointment status changed: %v", changes)
+			}
+		})
+	}
+}
+
+func TestFinishVideoSessionMarksConfirmedNoShow(t *testing.T) {
+	var changes []string
+	// The appointment is cancelled between the read and the update: the
+	// conditional update fails and no refund event is raised
+	raced := apperr.FailedPrecondition("appointment is no longer confirmed")
+	s := newTestService(unattendedSessionRepo(true, raced, &changes))
+	appointment := domain.Appointment{AppointmentId: 7, Status: "confirmed", PatientId: "p1", DoctorId: "d1"}
+
+	// The flags come from the ended row, not from the session read before a
+	// join that raced with the end
+	if _, err := s.finishVideoSession(context.Background(), domain.VideoTreatment{VideoTreatmentId: "room"}, appointment, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0] != "confirmed->patient_no_show" {
+		t.Errorf("got status changes %v, want one conditional confirmed->patient_no_show", changes)
+	}
+}
+
+func TestGetVideoRoomIssuesPatientLink(t *testing.T) {
+	start := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+	s := newTestService(&stubRepo{
+		getAppointmentById: func(context.Context, int) (domain.Appointment, error) {
+			return domain.Appointment{AppointmentId: 7, PatientId: "p1", DoctorId: "d1", Type: "video", Status: "confirmed", AppointmentTime: start, Duration: 30 * time.Minute}, nil
+		},
+		getVideoTreatmentByAppointment: func(context.Context, int) (domain.VideoTreatment, error) {
+			return domain.VideoTreatment{VideoTreatmentId: "room-7", AppointmentId: 7}, nil
+		},
+	})
+	provider := video.NewFakeProvider()
+	s.VideoProvider = provider
+	s.PatientClient = &stubPatientClient{profile: &patientpb.GetProfileResponse{Name: "Asha", Email: "asha@example.com"}}
+
+	link, err := s.GetVideoRoom(context.Background(), 7, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	room := videoRoom("room-7", domain.Appointment{AppointmentTime: start, Duration: 30 * time.Minute})
+	want := fmt.Sprintf("https://video.test/room-7?role=patient&user=p1&nbf=%d&exp=%d", room.NotBefore.Unix(), room.ExpiresAt.Unix())
+	if link != want {
+		t.Errorf("link = %q, want %q", link, want)
+	}
+	if len(provider.Issued) != 1 || provider.Issued[0] != (video.Participant{Id: "p1", Name: "Asha", Email: "asha@example.com", Role: video.RolePatient}) {
+		t.Errorf("issued = %+v", provider.Issued)
+	}
+}
+
+func TestGetVideoRoomRejectsOtherPatients(t *testing.T) {
+	s := newTestService(&stubRepo{
+		getAppointmentById: func(context.Context, int) (domain.Appointment, error) {
+			return domain.Appointment{AppointmentId: 7, PatientId: "p1", Type: "video", Status: "confirmed", AppointmentTime: time.Now()}, nil
+		},
+	})
+	provider := video.NewFakeProvider()
+	s.VideoProvider = provider
+
+	_, err := s.GetVideoRoom(context.Background(), 7, "p2")
+	if apperr.KindOf(err) != apperr.KindNotFound {
+		t.Errorf("err = %v, want not found", err)
+	}
+	if len(provider.Issued) != 0 {
+		t.Errorf("no link should be issued, got %+v", provider.Issued)
+	}
+}
+
+func TestJoinVideoSession(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		status  string
+		start   time.Time
+		wantErr bool
+	}{
+		{"in the slot", "confirmed", now.Add(-10 * time.Minute), false},
+		{"just before the slot", "confirmed", now.Add(5 * time.Minute), false},
+		{"cancelled", "cancelled", now.Add(-10 * time.Minute), true},
+		{"too early", "confirmed", now.Add(2 * time.Hour), true},
+		{"slot over", "confirmed", now.Add(-2 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var joined []string
+			s := newTestService(&stubRepo{
+				getVideoTreatment: func(context.Context, string) (domain.VideoTreatment, error) {
+					return domain.VideoTreatment{VideoTreatmentId: "room-7", AppointmentId: 7, Status: "waiting"}, nil
+				},
+				getAppointmentById: func(context.Context, int) (domain.Appointment, error) {
+					return domain.Appointment{AppointmentId: 7, PatientId: "p1", DoctorId: "d1", Type: "video", Status: tt.status, AppointmentTime: tt.start}, nil
+				},
+				addVideoParticipant: func(context.Context, domain.VideoParticipant) error { return nil },
+				joinVideoTreatment: func(_ context.Context, roomId, role string, at time.Time) (domain.VideoTreatment, error) {
+					joined = append(joined, role)
+					return domain.VideoTreatment{VideoTreatmentId: roomId, Status: "active", PatientJoined: true}, nil
+				},
+			})
+
+			session, err := s.JoinVideoSession(context.Background(), "room-7", "p1")
+			if tt.wantErr {
+				if apperr.KindOf(err) != apperr.KindFailedPrecondition {
+					t.Fatalf("err = %v, want failed precondition", err)
+				}
+				if len(joined) != 0 {
+					t.Errorf("joined %v although the join was rejected", joined)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(joined) != 1 || joined[0] != "patient" || !session.PatientJoined {
+				t.Errorf("joined %v, session %+v; want one patient join", joined, session)
+			}
+		})
+	}
+}
+
+func TestCheckVideoAppointment(t *testing.T) {
+	soon := time.Now().Add(10 * time.Minute)
+	tests := []struct {
+		name        string
+		appointment domain.Appointment
+		wantErr     bool
+	}{
+		{"confirmed", domain.Appointment{Type: "video", Status: "confirmed", AppointmentTime: soon}, false},
+		{"in person", domain.Appointment{Type: "in-person", Status: "confirmed", AppointmentTime: soon}, true},
+		{"pending", domain.Appointment{Type: "video", Status: "pending", AppointmentTime: soon}, true},
+		{"legacy pending", domain.Appointment{Type: "video", Status: "Pending", AppointmentTime: soon}, true},
+		{"cancelled", domain.Appointment{Type: "video", Status: "cancelled", AppointmentTime: soon}, true},
+		{"completed", domain.Appointment{Type: "video", Status: "completed", AppointmentTime: soon}, true},
+		{"doctor no-show", domain.Appointment{Type: "video", Status: "doctor_no_show", AppointmentTime: soon}, true},
+		{"patient no-show", domain.Appointment{Type: "video", Status: "patient_no_show", AppointmentTime: soon}, true},
+		{"over", domain.Appointment{Type: "video", Status: "confirmed", AppointmentTime: time.Now().Add(-2 * time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVideoAppointment(tt.appointment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && apperr.KindOf(err) != apperr.KindFailedPrecondition {
+				t.Errorf("got %v kind %v, want a failed precondition", err, apperr.KindOf(err))
+			}
+		})
+	}
+}
+
+func TestCreateVideoRoomDoesNotRenotify(t *testing.T) {
+	notifiedAt := time.Now().Add(-time.Minute)
+	s := newTestService(&stubRepo{
+		getAppointmentById: func(context.Context, int) (domain.Appointment, error) {
+			return domain.Appointment{AppointmentId: 7, PatientId: "p1", DoctorId: "d1", Type: "video", Status: "confirmed", AppointmentTime: time.Now().Add(5 * time.Minute)}, nil
+		},
+		saveVideoAppointment: func(context.Context, string, int, int) (domain.VideoTreatment, bool, error) {
+			return domain.VideoTreatment{VideoTreatmentId: "room-7", AppointmentId: 7, PatientNotifiedAt: &notifiedAt}, false, nil
+		},
+	})
+	provider := video.NewFakeProvider()
+	s.VideoProvider = provider
+
+	// PatientClient is nil: notifying the patient again would panic
+	if _, err := s.CreateVideoRoom(context.Background(), 7, "d1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.Issued) != 1 || provider.Issued[0].Role != video.RoleDoctor {
+		t.Errorf("only the doctor link should be issued, got %+v", provider.Issued)
+	}
+}
+
+// newVideoRoomService serves a confirmed video appointment whose patient has not been notified
+func newVideoRoomService(marked *int) *appointmentService {
+	s := newTestService(&stubRepo{
+		getAppointmentById: func(context.Context, int) (domain.Appointment, error) {
+			return domain.Appointment{AppointmentId: 7, PatientId: "p1", DoctorId: "d1", Type: "video", Status: "confirmed", AppointmentTime: time.Now().Add(5 * time.Minute)}, nil
+		},
+		saveVideoAppointment: func(context.Context, string, int, int) (domain.VideoTreatment, bool, error) {
+			return domain.VideoTreatment{VideoTreatmentId: "room-7", AppointmentId: 7}, true, nil
+		},
+		markVideoPatientNotified: func(context.Context, string, time.Time) error {
+			*marked++
+			return nil
+		},
+	})
+	s.VideoProvider = video.NewFakeProvider()
+	s.PatientClient = &stubPatientClient{profile: &patientpb.GetProfileResponse{Name: "Asha", Email: "asha@example.com"}}
+	return s
+}
+
+func TestCreateVideoRoomNotifiesPatient(t *testing.T) {
+	var marked int
+	s := newVideoRoomService(&marked)
+	var events []domain.AppointmentEvent
+	s.notify = func(_ context.Context, topic string, event domain.AppointmentEvent) error {
+		if topic != "appointment_topic" {
+			t.Errorf("published to %s", topic)
+		}
+		events = append(events, event)
+		return nil
+	}
+
+	if _, err := s.CreateVideoRoom(context.Background(), 7, "d1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].AppointmentId != 7 || events[0].VideoURL == "" {
+		t.Fatalf("expected one event carrying the patient link, got %+v", events)
+	}
+	if marked != 1 {
+		t.Errorf("the session should be marked notified once, got %d", marked)
+	}
+}
+
+func TestCreateVideoRoomStopsWhenCancelled(t *testing.T) {
+	var marked int
+	s := newVideoRoomService(&marked)
+	// The broker never answers; only the caller's context ends the wait
+	s.notify = func(ctx context.Context, _ string, _ domain.AppointmentEvent) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.CreateVideoRoom(ctx, 7, "d1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want the cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CreateVideoRoom kept waiting after its context was cancelled")
+	}
+	if marked != 0 {
+		t.Error("a patient who was never notified should not be marked notified")
+	}
+}
+
+func TestCreateVideoRoomStopsAtRepositoryDeadline(t *testing.T) {
+	s := newTestService(&stubRepo{
+		getAppointmentById: func(ctx context.Context, _ int) (domain.Appointment, error) {
+			<-ctx.Done()
+			return domain.Appointment{}, ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.CreateVideoRoom(ctx, 7, "d1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the deadline", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("the repository call outlived the caller's deadline")
+	}
+}
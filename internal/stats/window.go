@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"errors"
+	"time"
+)
+
+// Time series bucket sizes
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// Window turns the legacy "day", "week" and "month" params into a calendar
+// window [from, to) containing now: today, this week starting Monday, or this
+// month. Any other param ("all" included) returns zero times, meaning no bound.
+func Window(param string, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch param {
+	case "day":
+		return today, today.AddDate(0, 0, 1)
+	case "week":
+		start := StartOfWeek(today)
+		return start, start.AddDate(0, 0, 7)
+	case "month":
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	return time.Time{}, time.Time{}
+}
+
+// StartOfWeek returns midnight of the Monday on or before t, matching
+// Postgres date_trunc('week', ...)
+func StartOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// Buckets returns the start of every day or week bucket overlapping [from, to)
+func Buckets(from, to time.Time, interval string) ([]time.Time, error) {
+	if from.IsZero() || to.IsZero() || !to.After(from) {
+		return nil, errors.New("a date range with to after from is required")
+	}
+	var start time.Time
+	var step func(time.Time) time.Time
+	switch interval {
+	case IntervalDay:
+		start = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case IntervalWeek:
+		start = StartOfWeek(from)
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	default:
+		return nil, errors.New("interval must be day or week")
+	}
+	var buckets []time.Time
+	for t := start; t.Before(to); t = step(t) {
+		buckets = append(buckets, t)
+	}
+	return buckets, nil
+}
+
+// Rate returns part as a fraction of whole, or 0 when whole is 0
+func Rate(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// Days counts the calendar days overlapping [from, to)
+func Days(from, to time.Time) int {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	days := 0
+	for t := start; t.Before(to); t = t.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
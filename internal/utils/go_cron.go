@@ -13,6 +13,22 @@ func StartCroneSheduler(serviceInterface service.AppointmentService) {
 	if err != nil {
 		log.Fatalf("Failed to schedule reminder job: %v", err)
 	}
+	_, err = croneSheduler.AddFunc("*/15 * * * *", serviceInterface.CloseStaleVideoSessions)
+	if err != nil {
+		log.Fatalf("Failed to schedule video session cleanup job: %v", err)
+	}
+	_, err = croneSheduler.AddFunc("*/15 * * * *", serviceInterface.ExpirePendingAppointments)
+	if err != nil {
+		log.Fatalf("Failed to schedule unpaid booking expiry job: %v", err)
+	}
+	_, err = croneSheduler.AddFunc("30 2 * * *", serviceInterface.RebuildRollups)
+	if err != nil {
+		log.Fatalf("Failed to schedule statistics rollup job: %v", err)
+	}
+	_, err = croneSheduler.AddFunc("0 * * * *", serviceInterface.AnchorAuditLog)
+	if err != nil {
+		log.Fatalf("Failed to schedule audit anchor job: %v", err)
+	}
 	croneSheduler.Start()
 
 	select {}
@@ -0,0 +1,146 @@
+// Package validation collects field level violations of a request so they
+// can be reported together as one InvalidArgument error.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nuhmanudheent/hosp-connect-appointment-service/internal/apperr"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Validator accumulates violations. Fields are named by their proto names.
+type Validator struct {
+	now        time.Time
+	violations []apperr.FieldViolation
+}
+
+// New returns a Validator that treats now as the current time for Future
+func New(now time.Time) *Validator {
+	return &Validator{now: now}
+}
+
+// Add records a violation
+func (v *Validator) Add(field, description string) {
+	v.violations = append(v.violations, apperr.FieldViolation{Field: field, Description: description})
+}
+
+// Required rejects empty or blank strings
+func (v *Validator) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, "is required")
+	}
+}
+
+// MaxLen rejects strings longer than max characters
+func (v *Validator) MaxLen(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.Add(field, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+// Positive rejects zero and negative ids
+func (v *Validator) Positive(field string, value int64) {
+	if value <= 0 {
+		v.Add(field, "must be greater than zero")
+	}
+}
+
+// NonNegative rejects negative values of optional ids and limits
+func (v *Validator) NonNegative(field string, value int64) {
+	if value < 0 {
+		v.Add(field, "cannot be negative")
+	}
+}
+
+// NonNegativeAmount rejects negative money amounts and percentages
+func (v *Validator) NonNegativeAmount(field string, value float64) {
+	if value < 0 {
+		v.Add(field, "cannot be negative")
+	}
+}
+
+// Range rejects values outside [min, max]
+func (v *Validator) Range(field string, value, min, max int64) {
+	if value < min || value > max {
+		v.Add(field, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
+
+// OneOf rejects values that are not in allowed. Include "" in allowed when
+// the field is optional.
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	var named []string
+	for _, a := range allowed {
+		if a != "" {
+			named = append(named, a)
+		}
+	}
+	v.Add(field, "must be one of "+strings.Join(named, ", "))
+}
+
+// Time rejects a missing time
+func (v *Validator) Time(field string, t time.Time) {
+	if t.IsZero() {
+		v.Add(field, "is required")
+	}
+}
+
+// Future rejects a missing time or one that is not after now
+func (v *Validator) Future(field string, t time.Time) {
+	if t.IsZero() {
+		v.Add(field, "is required")
+	} else if !t.After(v.now) {
+		v.Add(field, "must be in the future")
+	}
+}
+
+// Timestamp rejects a nil or out of range protobuf timestamp and returns its
+// time, or the zero time when it is invalid so later checks do not repeat
+// the violation
+func (v *Validator) Timestamp(field string, ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		v.Add(field, "is required")
+		return time.Time{}
+	}
+	if err := ts.CheckValid(); err != nil {
+		v.Add(field, "is not a valid timestamp")
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// FutureTimestamp is Timestamp followed by Future
+func (v *Validator) FutureTimestamp(field string, ts *timestamppb.Timestamp) {
+	if t := v.Timestamp(field, ts); !t.IsZero() && !t.After(v.now) {
+		v.Add(field, "must be in the future")
+	}
+}
+
+// Window rejects a range whose end is not after its start. With required
+// both ends must be set; otherwise either may be left open.
+func (v *Validator) Window(fromField, toField string, from, to time.Time, required bool) {
+	if required {
+		v.Time(fromField, from)
+		v.Time(toField, to)
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		v.Add(toField, "must be after "+fromField)
+	}
+}
+
+// Err returns the collected violations as an InvalidArgument error, or nil
+func (v *Validator) Err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return apperr.InvalidArgument(v.violations...)
+}
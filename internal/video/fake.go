@@ -0,0 +1,28 @@
+package video
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FakeProvider records issued links and returns predictable URLs, for tests
+// and local runs without a Jitsi deployment.
+type FakeProvider struct {
+	mu     sync.Mutex
+	Issued []Participant
+	Err    error
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+func (f *FakeProvider) JoinURL(room Room, participant Participant) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return "", f.Err
+	}
+	f.Issued = append(f.Issued, participant)
+	return fmt.Sprintf("https://video.test/%s?role=%s&user=%s&nbf=%d&exp=%d", room.Name, participant.Role, participant.Id, room.NotBefore.Unix(), room.ExpiresAt.Unix()), nil
+}
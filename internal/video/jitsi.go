@@ -0,0 +1,94 @@
+package video
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+type jitsiProvider struct {
+	domain string
+	appId  string
+	secret []byte
+}
+
+// NewJitsiProvider returns a provider for a Jitsi deployment with token
+// authentication enabled; links carry an HS256 JWT signed with the app secret.
+func NewJitsiProvider(domain, appId, secret string) Provider {
+	return &jitsiProvider{
+		domain: domain,
+		appId:  appId,
+		secret: []byte(secret),
+	}
+}
+
+type jitsiUser struct {
+	Id        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Moderator bool   `json:"moderator"`
+}
+
+type jitsiClaims struct {
+	Audience  string `json:"aud"`
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Room      string `json:"room"`
+	NotBefore int64  `json:"nbf"`
+	ExpiresAt int64  `json:"exp"`
+	Context   struct {
+		User jitsiUser `json:"user"`
+	} `json:"context"`
+}
+
+func (j *jitsiProvider) JoinURL(room Room, participant Participant) (string, error) {
+	if len(j.secret) == 0 {
+		return "", errors.New("jitsi app secret is not configured")
+	}
+	if !room.ExpiresAt.After(room.NotBefore) {
+		return "", errors.New("video room expiry must be after its start")
+	}
+	if participant.Role != RoleDoctor && participant.Role != RolePatient {
+		return "", fmt.Errorf("unknown participant role %q", participant.Role)
+	}
+
+	claims := jitsiClaims{
+		Audience:  "jitsi",
+		Issuer:    j.appId,
+		Subject:   j.domain,
+		Room:      room.Name,
+		NotBefore: room.NotBefore.Unix(),
+		ExpiresAt: room.ExpiresAt.Unix(),
+	}
+	claims.Context.User = jitsiUser{
+		Id:        participant.Id,
+		Name:      participant.Name,
+		Email:     participant.Email,
+		Moderator: participant.Role == RoleDoctor,
+	}
+	token, err := signHS256(claims, j.secret)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s/%s?jwt=%s", j.domain, url.PathEscape(room.Name), token), nil
+}
+
+func signHS256(claims interface{}, secret []byte) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + enc.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,99 @@
+package video
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRoom = Room{
+	Name:      "room-42",
+	NotBefore: time.Date(2026, 3, 2, 9, 50, 0, 0, time.UTC),
+	ExpiresAt: time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC),
+}
+
+// decodeJitsiToken checks the token's signature with secret and returns its claims
+func decodeJitsiToken(t *testing.T, link, secret string) jitsiClaims {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse link: %v", err)
+	}
+	parts := strings.Split(u.Query().Get("jwt"), ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if got := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); got != parts[2] {
+		t.Fatalf("token signature does not verify with the app secret")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	var claims jitsiClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("unmarshal claims: %v", err)
+	}
+	return claims
+}
+
+func TestJitsiJoinURL(t *testing.T) {
+	provider := NewJitsiProvider("meet.example.org", "hospconnect", "s3cret")
+	tests := []struct {
+		role      string
+		moderator bool
+	}{
+		{RoleDoctor, true},
+		{RolePatient, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			link, err := provider.JoinURL(testRoom, Participant{Id: "user-1", Name: "Asha", Role: tt.role})
+			if err != nil {
+				t.Fatalf("JoinURL: %v", err)
+			}
+			if !strings.HasPrefix(link, "https://meet.example.org/room-42?jwt=") {
+				t.Errorf("link = %q", link)
+			}
+			claims := decodeJitsiToken(t, link, "s3cret")
+			if claims.Audience != "jitsi" || claims.Issuer != "hospconnect" || claims.Subject != "meet.example.org" || claims.Room != "room-42" {
+				t.Errorf("claims = %+v", claims)
+			}
+			if claims.NotBefore != testRoom.NotBefore.Unix() || claims.ExpiresAt != testRoom.ExpiresAt.Unix() {
+				t.Errorf("nbf/exp = %d/%d, want %d/%d", claims.NotBefore, claims.ExpiresAt, testRoom.NotBefore.Unix(), testRoom.ExpiresAt.Unix())
+			}
+			if claims.Context.User.Id != "user-1" || claims.Context.User.Moderator != tt.moderator {
+				t.Errorf("user = %+v, want moderator %v", claims.Context.User, tt.moderator)
+			}
+		})
+	}
+}
+
+func TestJitsiJoinURLErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		secret      string
+		room        Room
+		participant Participant
+		want        string
+	}{
+		{"no secret", "", testRoom, Participant{Id: "user-1", Role: RolePatient}, "secret is not configured"},
+		{"expiry before start", "s3cret", Room{Name: "r", NotBefore: testRoom.ExpiresAt, ExpiresAt: testRoom.NotBefore}, Participant{Id: "user-1", Role: RolePatient}, "expiry must be after"},
+		{"unknown role", "s3cret", testRoom, Participant{Id: "user-1", Role: "admin"}, "unknown participant role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJitsiProvider("meet.example.org", "hospconnect", tt.secret).JoinURL(tt.room, tt.participant)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package video
+
+import "time"
+
+// Provider issues the join link a participant uses to enter a video room
+type Provider interface {
+	JoinURL(room Room, participant Participant) (string, error)
+}
+
+// Room is a video room bound to the time window of its appointment
+type Room struct {
+	Name      string
+	NotBefore time.Time
+	ExpiresAt time.Time
+}
+
+type Participant struct {
+	Id    string
+	Name  string
+	Email string
+	Role  string
+}
+
+const (
+	RoleDoctor  = "doctor"
+	RolePatient = "patient"
+)
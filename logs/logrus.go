@@ -1,25 +1,86 @@
 package logs
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// defaultRetentionDays is how long log lines are kept when LOG_RETENTION_DAYS
+// is not set. Lines carry patient ids that erasure cannot reach, so they must
+// age out well within the month an erasure request may take.
+const defaultRetentionDays = 14
+
 func NewLogger() *logrus.Logger {
 	logger := logrus.New()
 
+	// Files are kept by age, not count, so LOG_RETENTION_DAYS is what decides when lines go
 	lumberjackLogger := &lumberjack.Logger{
-		Filename:   "./logs/appointment.log",
-		MaxSize:    10,
-		MaxBackups: 5,
-		MaxAge:     30,
-		Compress:   true,
+		Filename: "./logs/appointment.log",
+		MaxSize:  10,
+		MaxAge:   retentionDays(),
+		Compress: true,
 	}
+	go rotateDaily(lumberjackLogger)
 	mutliwriter := io.MultiWriter(os.Stdout, lumberjackLogger)
 	logger.SetOutput(mutliwriter)
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetFormatter(&redactingFormatter{next: &logrus.JSONFormatter{}})
 	return logger
 }
+
+// retentionDays reads LOG_RETENTION_DAYS, the age in days after which rotated logs are deleted
+func retentionDays() int {
+	days, err := strconv.Atoi(os.Getenv("LOG_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		return defaultRetentionDays
+	}
+	return days
+}
+
+// rotateDaily starts a new log file now and at every midnight UTC. Rotation
+// is when lumberjack deletes files older than MaxAge, and a file only holds
+// one day of lines, so every line is gone at most a day after it expires. A
+// file rotated on size alone could keep old lines as long as it stays small.
+func rotateDaily(l *lumberjack.Logger) {
+	for {
+		if err := l.Rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to rotate log file: %v\n", err)
+		}
+		now := time.Now().UTC()
+		time.Sleep(now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now))
+	}
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// redactingFormatter masks email addresses in the message and string fields
+// so they never reach the log files
+type redactingFormatter struct {
+	next logrus.Formatter
+}
+
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	redacted := *entry
+	redacted.Message = redact(entry.Message)
+	redacted.Data = make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			value = redact(v)
+		case error:
+			value = redact(v.Error())
+		}
+		redacted.Data[key] = value
+	}
+	return f.next.Format(&redacted)
+}
+
+func redact(s string) string {
+	return emailPattern.ReplaceAllString(s, "[email redacted]")
+}
@@ -0,0 +1,56 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func TestRetentionDays(t *testing.T) {
+	for value, want := range map[string]int{"": defaultRetentionDays, "7": 7, "0": defaultRetentionDays, "two weeks": defaultRetentionDays} {
+		t.Setenv("LOG_RETENTION_DAYS", value)
+		if got := retentionDays(); got != want {
+			t.Errorf("LOG_RETENTION_DAYS=%q: got %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestRotateDailyPurgesExpiredFiles(t *testing.T) {
+	dir := t.TempDir()
+	// Backups are named after the time they were rotated, which is what MaxAge goes by
+	expired := filepath.Join(dir, "appointment-"+time.Now().UTC().AddDate(0, 0, -15).Format("2006-01-02T15-04-05.000")+".log.gz")
+	recent := filepath.Join(dir, "appointment-"+time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02T15-04-05.000")+".log.gz")
+	current := filepath.Join(dir, "appointment.log")
+	for _, name := range []string{expired, recent, current} {
+		if err := os.WriteFile(name, []byte(`{"PatientId":"p1"}`+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := &lumberjack.Logger{Filename: current, MaxSize: 10, MaxAge: 14, Compress: true}
+	t.Cleanup(func() { l.Close() })
+	go rotateDaily(l)
+
+	// Lumberjack deletes and compresses in the background after rotating
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := os.Stat(expired)
+		uncompressed, _ := filepath.Glob(filepath.Join(dir, "appointment-*.log"))
+		if os.IsNotExist(err) && len(uncompressed) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expired log file was not deleted or the rotated file was not compressed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("recent log file was deleted: %v", err)
+	}
+	if data, err := os.ReadFile(current); err != nil || len(data) != 0 {
+		t.Errorf("current log file should be new and empty, got %q, %v", data, err)
+	}
+}
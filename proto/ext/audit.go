@@ -0,0 +1,62 @@
+package extpb
+
+import "time"
+
+// AuditLogRequest filters the audit log; empty fields are ignored. from is
+// inclusive and to exclusive. Pass next_cursor from a previous response as
+// cursor to fetch the following page.
+type AuditLogRequest struct {
+	AppointmentId int64     `json:"appointment_id"`
+	ActorId       string    `json:"actor_id"`
+	Action        string    `json:"action"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Limit         int32     `json:"limit"`
+	Cursor        string    `json:"cursor"`
+}
+
+// AuditEntry is one entry of the audit log. before and after are JSON
+// snapshots of the changed record and are empty when there is none.
+type AuditEntry struct {
+	Id            uint64    `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	RequestId     string    `json:"request_id"`
+	ActorId       string    `json:"actor_id"`
+	ActorRole     string    `json:"actor_role"`
+	Action        string    `json:"action"`
+	AppointmentId int64     `json:"appointment_id"`
+	Before        string    `json:"before"`
+	After         string    `json:"after"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+type AuditLogResponse struct {
+	Status     string       `json:"status"`
+	StatusCode int32        `json:"status_code"`
+	Message    string       `json:"message"`
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+// VerifyAuditLogRequest may carry an anchor kept outside the service, such as
+// a logged chain head: the entry with anchor_entry_id must still have anchor_hash.
+type VerifyAuditLogRequest struct {
+	AnchorEntryId uint64 `json:"anchor_entry_id"`
+	AnchorHash    string `json:"anchor_hash"`
+}
+
+// VerifyAuditLogResponse reports whether the audit chain is intact. When it
+// is not, broken_at is the id of the first entry that fails and reason says
+// why. anchors counts the signed anchors and caller anchor that were matched.
+type VerifyAuditLogResponse struct {
+	Status     string `json:"status"`
+	StatusCode int32  `json:"status_code"`
+	Message    string `json:"message"`
+	Valid      bool   `json:"valid"`
+	Checked    int64  `json:"checked"`
+	BrokenAt   uint64 `json:"broken_at"`
+	Reason     string `json:"reason"`
+	LastHash   string `json:"last_hash"`
+	Anchors    int64  `json:"anchors"`
+}
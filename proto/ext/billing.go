@@ -0,0 +1,36 @@
+package extpb
+
+import "time"
+
+type ExportClaimsRequest struct {
+	PayerId string    `json:"payer_id"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+}
+
+type Claim struct {
+	ClaimRef         string    `json:"claim_ref"`
+	PayerType        string    `json:"payer_type"`
+	PayerId          string    `json:"payer_id"`
+	MemberId         string    `json:"member_id"`
+	AppointmentId    int64     `json:"appointment_id"`
+	PatientId        string    `json:"patient_id"`
+	DoctorId         string    `json:"doctor_id"`
+	SpecializationId int32     `json:"specialization_id"`
+	AppointmentTime  time.Time `json:"appointment_time"`
+	CoveredAmount    float64   `json:"covered_amount"`
+	PatientAmount    float64   `json:"patient_amount"`
+	Currency         string    `json:"currency"`
+	ClaimStatus      string    `json:"claim_status"`
+}
+
+// ExportClaimsResponse carries the claims both as rows and as a CSV file
+type ExportClaimsResponse struct {
+	Status     string  `json:"status"`
+	StatusCode int32   `json:"status_code"`
+	Message    string  `json:"message"`
+	Claims     []Claim `json:"claims"`
+	Csv        []byte  `json:"csv"`
+	Total      float64 `json:"total"`
+	Currency   string  `json:"currency"`
+}
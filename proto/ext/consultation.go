@@ -0,0 +1,73 @@
+package extpb
+
+import "time"
+
+type StandardResponse struct {
+	Status     string `json:"status"`
+	StatusCode int32  `json:"status_code"`
+	Message    string `json:"message"`
+	Error      string `json:"error"`
+}
+
+type Vitals struct {
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+	PulseRate          int32   `json:"pulse_rate"`
+	BloodPressure      string  `json:"blood_pressure"`
+	RespiratoryRate    int32   `json:"respiratory_rate"`
+	OxygenSaturation   int32   `json:"oxygen_saturation"`
+	WeightKg           float64 `json:"weight_kg"`
+}
+
+type Medication struct {
+	Drug         string `json:"drug"`
+	Dose         string `json:"dose"`
+	Frequency    string `json:"frequency"`
+	DurationDays int32  `json:"duration_days"`
+	Instructions string `json:"instructions"`
+}
+
+type CompleteAppointmentRequest struct {
+	AppointmentId int32        `json:"appointment_id"`
+	DoctorId      string       `json:"doctor_id"`
+	Diagnosis     string       `json:"diagnosis"`
+	Notes         string       `json:"notes"`
+	Vitals        Vitals       `json:"vitals"`
+	Medications   []Medication `json:"medications"`
+}
+
+type GetPrescriptionHistoryRequest struct {
+	PatientId string `json:"patient_id"`
+}
+
+type PrescriptionRecord struct {
+	AppointmentId int32        `json:"appointment_id"`
+	DoctorId      string       `json:"doctor_id"`
+	Diagnosis     string       `json:"diagnosis"`
+	PrescribedAt  time.Time    `json:"prescribed_at"`
+	Medications   []Medication `json:"medications"`
+}
+
+type GetPrescriptionHistoryResponse struct {
+	Status        string               `json:"status"`
+	StatusCode    int32                `json:"status_code"`
+	Message       string               `json:"message"`
+	Prescriptions []PrescriptionRecord `json:"prescriptions"`
+}
+
+// GetVisitDocumentRequest asks for a rendered document; kind is "visit_summary"
+// or "prescription" and format is "pdf" or "html".
+type GetVisitDocumentRequest struct {
+	AppointmentId int32  `json:"appointment_id"`
+	PatientId     string `json:"patient_id"`
+	Kind          string `json:"kind"`
+	Format        string `json:"format"`
+}
+
+type GetVisitDocumentResponse struct {
+	Status      string `json:"status"`
+	StatusCode  int32  `json:"status_code"`
+	Message     string `json:"message"`
+	Content     []byte `json:"content"`
+	ContentType string `json:"content_type"`
+	FileName    string `json:"file_name"`
+}
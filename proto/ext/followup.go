@@ -0,0 +1,56 @@
+package extpb
+
+import "time"
+
+// CreateFollowUpRequest recommends a follow-up; fee_percent is the share of the
+// regular fee charged, so 0 makes the visit free.
+type CreateFollowUpRequest struct {
+	ParentAppointmentId int32     `json:"parent_appointment_id"`
+	DoctorId            string    `json:"doctor_id"`
+	WindowStart         time.Time `json:"window_start"`
+	WindowEnd           time.Time `json:"window_end"`
+	FeePercent          int32     `json:"fee_percent"`
+	Notes               string    `json:"notes"`
+}
+
+type CreateFollowUpResponse struct {
+	Status     string `json:"status"`
+	StatusCode int32  `json:"status_code"`
+	Message    string `json:"message"`
+	FollowUpId uint64 `json:"follow_up_id"`
+}
+
+type BookFollowUpRequest struct {
+	FollowUpId        uint64    `json:"follow_up_id"`
+	PatientId         string    `json:"patient_id"`
+	ConfirmedDateTime time.Time `json:"confirmed_date_time"`
+	Type              string    `json:"type"`
+}
+
+type BookFollowUpResponse struct {
+	Status     string `json:"status"`
+	StatusCode int32  `json:"status_code"`
+	Message    string `json:"message"`
+	PaymentUrl string `json:"payment_url"`
+}
+
+type FollowUpAdherenceRequest struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type DoctorFollowUpAdherence struct {
+	DoctorId      string  `json:"doctor_id"`
+	Recommended   int32   `json:"recommended"`
+	Booked        int32   `json:"booked"`
+	Completed     int32   `json:"completed"`
+	Missed        int32   `json:"missed"`
+	AdherenceRate float64 `json:"adherence_rate"`
+}
+
+type FollowUpAdherenceResponse struct {
+	Status     string                    `json:"status"`
+	StatusCode int32                     `json:"status_code"`
+	Message    string                    `json:"message"`
+	Doctors    []DoctorFollowUpAdherence `json:"doctors"`
+}
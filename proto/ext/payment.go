@@ -0,0 +1,38 @@
+package extpb
+
+import "time"
+
+// RecordClinicPaymentRequest is sent by the front desk when a pay-at-clinic
+// booking is paid. method is "cash" or "card".
+type RecordClinicPaymentRequest struct {
+	AppointmentId int64   `json:"appointment_id"`
+	Amount        float64 `json:"amount"`
+	Method        string  `json:"method"`
+	CollectedBy   string  `json:"collected_by"`
+}
+
+type RecordClinicPaymentResponse struct {
+	Status        string    `json:"status"`
+	StatusCode    int32     `json:"status_code"`
+	Message       string    `json:"message"`
+	AppointmentId int64     `json:"appointment_id"`
+	PaymentStatus string    `json:"payment_status"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	CollectedAt   time.Time `json:"collected_at"`
+}
+
+// RevenueStatisticsRequest takes the same param as FetchStatisticsDetails: day, week, month or all
+type RevenueStatisticsRequest struct {
+	Param string `json:"param"`
+}
+
+type RevenueStatisticsResponse struct {
+	Status            string  `json:"status"`
+	StatusCode        int32   `json:"status_code"`
+	Message           string  `json:"message"`
+	CollectedRevenue  float64 `json:"collected_revenue"`
+	ExpectedRevenue   float64 `json:"expected_revenue"`
+	ClinicCollected   float64 `json:"clinic_collected"`
+	ClinicOutstanding float64 `json:"clinic_outstanding"`
+}
@@ -0,0 +1,47 @@
+package extpb
+
+import "time"
+
+type DoctorCancelAppointmentRequest struct {
+	AppointmentId int64  `json:"appointment_id"`
+	DoctorId      string `json:"doctor_id"`
+	Reason        string `json:"reason"`
+}
+
+// RateAppointmentRequest scores a completed appointment from 1 to 5
+type RateAppointmentRequest struct {
+	AppointmentId int64  `json:"appointment_id"`
+	PatientId     string `json:"patient_id"`
+	Score         int32  `json:"score"`
+	Comment       string `json:"comment"`
+}
+
+// DoctorPerformanceRequest covers [from, to); leave doctor_id empty for every doctor
+type DoctorPerformanceRequest struct {
+	DoctorId string    `json:"doctor_id"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+}
+
+type DoctorPerformance struct {
+	DoctorId                   string  `json:"doctor_id"`
+	DoctorName                 string  `json:"doctor_name"`
+	Booked                     int32   `json:"booked"`
+	Completed                  int32   `json:"completed"`
+	CancelledByDoctor          int32   `json:"cancelled_by_doctor"`
+	CancelledByPatient         int32   `json:"cancelled_by_patient"`
+	DoctorNoShows              int32   `json:"doctor_no_shows"`
+	PatientNoShows             int32   `json:"patient_no_shows"`
+	VideoSessions              int32   `json:"video_sessions"`
+	AverageConsultationSeconds float64 `json:"average_consultation_seconds"`
+	Revenue                    float64 `json:"revenue"`
+	Ratings                    int32   `json:"ratings"`
+	AverageRating              float64 `json:"average_rating"`
+}
+
+type DoctorPerformanceResponse struct {
+	Status     string              `json:"status"`
+	StatusCode int32               `json:"status_code"`
+	Message    string              `json:"message"`
+	Doctors    []DoctorPerformance `json:"doctors"`
+}
@@ -0,0 +1,78 @@
+package extpb
+
+import "time"
+
+// ConsultationPrice is the base price of a specialization, or a doctor's own
+// price when doctor_id is set. A zero fee falls back to the base price.
+type ConsultationPrice struct {
+	Id               uint64  `json:"id"`
+	SpecializationId int32   `json:"specialization_id"`
+	DoctorId         string  `json:"doctor_id"`
+	VideoFee         float64 `json:"video_fee"`
+	InClinicFee      float64 `json:"in_clinic_fee"`
+	Currency         string  `json:"currency"`
+}
+
+// PriceSurcharge adds percent of the base fee to bookings starting in
+// [start_hour, end_hour). specialization_id 0 applies to all specializations.
+type PriceSurcharge struct {
+	Id               uint64  `json:"id"`
+	SpecializationId int32   `json:"specialization_id"`
+	Name             string  `json:"name"`
+	StartHour        int32   `json:"start_hour"`
+	EndHour          int32   `json:"end_hour"`
+	Percent          float64 `json:"percent"`
+}
+
+type GetPriceQuoteRequest struct {
+	SpecializationId  int32     `json:"specialization_id"`
+	DoctorId          string    `json:"doctor_id"`
+	Type              string    `json:"type"`
+	ConfirmedDateTime time.Time `json:"confirmed_date_time"`
+}
+
+type GetPriceQuoteResponse struct {
+	Status     string  `json:"status"`
+	StatusCode int32   `json:"status_code"`
+	Message    string  `json:"message"`
+	BaseFee    float64 `json:"base_fee"`
+	Surcharge  float64 `json:"surcharge"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+}
+
+type ConsultationPriceResponse struct {
+	Status     string             `json:"status"`
+	StatusCode int32              `json:"status_code"`
+	Message    string             `json:"message"`
+	Price      *ConsultationPrice `json:"price,omitempty"`
+}
+
+type ListPricingRequest struct {
+	SpecializationId int32 `json:"specialization_id"`
+}
+
+type ListConsultationPricesResponse struct {
+	Status     string              `json:"status"`
+	StatusCode int32               `json:"status_code"`
+	Message    string              `json:"message"`
+	Prices     []ConsultationPrice `json:"prices"`
+}
+
+type PriceSurchargeResponse struct {
+	Status     string          `json:"status"`
+	StatusCode int32           `json:"status_code"`
+	Message    string          `json:"message"`
+	Surcharge  *PriceSurcharge `json:"surcharge,omitempty"`
+}
+
+type ListPriceSurchargesResponse struct {
+	Status     string           `json:"status"`
+	StatusCode int32            `json:"status_code"`
+	Message    string           `json:"message"`
+	Surcharges []PriceSurcharge `json:"surcharges"`
+}
+
+type DeletePricingRequest struct {
+	Id uint64 `json:"id"`
+}
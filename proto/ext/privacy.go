@@ -0,0 +1,30 @@
+package extpb
+
+type PatientDataRequest struct {
+	PatientId string `json:"patient_id"`
+}
+
+// PatientDataResponse carries the patient's data as one JSON document
+type PatientDataResponse struct {
+	Status      string `json:"status"`
+	StatusCode  int32  `json:"status_code"`
+	Message     string `json:"message"`
+	Content     []byte `json:"content"`
+	ContentType string `json:"content_type"`
+	FileName    string `json:"file_name"`
+}
+
+// ErasePatientDataResponse counts the rows that were pseudonymised, cleared or deleted
+type ErasePatientDataResponse struct {
+	Status            string `json:"status"`
+	StatusCode        int32  `json:"status_code"`
+	Message           string `json:"message"`
+	Appointments      int32  `json:"appointments"`
+	Consultations     int32  `json:"consultations"`
+	Prescriptions     int32  `json:"prescriptions"`
+	FollowUps         int32  `json:"follow_ups"`
+	VideoParticipants int32  `json:"video_participants"`
+	Ratings           int32  `json:"ratings"`
+	PromoRedemptions  int32  `json:"promo_redemptions"`
+	AuditEntries      int32  `json:"audit_entries"`
+}
@@ -0,0 +1,111 @@
+package extpb
+
+import "time"
+
+// BookAppointmentRequest books an appointment like ConfirmAppointment, with
+// optional booking options such as a promo code
+type BookAppointmentRequest struct {
+	DoctorId          string    `json:"doctor_id"`
+	PatientId         string    `json:"patient_id"`
+	SpecializationId  int32     `json:"specialization_id"`
+	Type              string    `json:"type"`
+	ConfirmedDateTime time.Time `json:"confirmed_date_time"`
+	PromoCode         string    `json:"promo_code"`
+	// payer_type is "insurance" or "corporate"; member_id is the policy or employee id
+	PayerType string `json:"payer_type"`
+	PayerId   string `json:"payer_id"`
+	MemberId  string `json:"member_id"`
+	// payment_mode is "prepaid" (default), "pay_at_clinic" or "free"; free
+	// bookings are waived by the signed-in staff member. waived_by is optional
+	// and, when sent, must be that member's own id.
+	PaymentMode string `json:"payment_mode"`
+	WaivedBy    string `json:"waived_by"`
+}
+
+type BookAppointmentResponse struct {
+	Status     string `json:"status"`
+	StatusCode int32  `json:"status_code"`
+	Message    string `json:"message"`
+	PaymentUrl string `json:"payment_url"`
+}
+
+// PromoCode is a discount code. discount_type is "percent" or "flat";
+// zero limits and empty validity bounds mean unlimited.
+type PromoCode struct {
+	Id                uint64    `json:"id"`
+	Code              string    `json:"code"`
+	Description       string    `json:"description"`
+	DiscountType      string    `json:"discount_type"`
+	DiscountValue     float64   `json:"discount_value"`
+	MaxDiscount       float64   `json:"max_discount"`
+	ValidFrom         time.Time `json:"valid_from"`
+	ValidUntil        time.Time `json:"valid_until"`
+	GlobalLimit       int32     `json:"global_limit"`
+	PerPatientLimit   int32     `json:"per_patient_limit"`
+	IsActive          bool      `json:"is_active"`
+	SpecializationIds []int32   `json:"specialization_ids"`
+}
+
+type PromoCodeResponse struct {
+	Status     string     `json:"status"`
+	StatusCode int32      `json:"status_code"`
+	Message    string     `json:"message"`
+	Promo      *PromoCode `json:"promo,omitempty"`
+}
+
+type ListPromoCodesRequest struct {
+	IncludeInactive bool `json:"include_inactive"`
+}
+
+type ListPromoCodesResponse struct {
+	Status     string      `json:"status"`
+	StatusCode int32       `json:"status_code"`
+	Message    string      `json:"message"`
+	Promos     []PromoCode `json:"promos"`
+}
+
+type PromoCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type ValidatePromoCodeRequest struct {
+	Code              string    `json:"code"`
+	PatientId         string    `json:"patient_id"`
+	DoctorId          string    `json:"doctor_id"`
+	SpecializationId  int32     `json:"specialization_id"`
+	Type              string    `json:"type"`
+	ConfirmedDateTime time.Time `json:"confirmed_date_time"`
+}
+
+type ValidatePromoCodeResponse struct {
+	Status         string  `json:"status"`
+	StatusCode     int32   `json:"status_code"`
+	Message        string  `json:"message"`
+	OriginalAmount float64 `json:"original_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+	FinalAmount    float64 `json:"final_amount"`
+}
+
+type PromoRedemption struct {
+	Id             uint64    `json:"id"`
+	Code           string    `json:"code"`
+	PatientId      string    `json:"patient_id"`
+	AppointmentId  int64     `json:"appointment_id"`
+	OriginalAmount float64   `json:"original_amount"`
+	DiscountAmount float64   `json:"discount_amount"`
+	FinalAmount    float64   `json:"final_amount"`
+	RedeemedAt     time.Time `json:"redeemed_at"`
+}
+
+type ListPromoRedemptionsRequest struct {
+	Code string    `json:"code"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type ListPromoRedemptionsResponse struct {
+	Status      string            `json:"status"`
+	StatusCode  int32             `json:"status_code"`
+	Message     string            `json:"message"`
+	Redemptions []PromoRedemption `json:"redemptions"`
+}
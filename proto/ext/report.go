@@ -0,0 +1,21 @@
+package extpb
+
+import "time"
+
+// ExportReportRequest asks for a report over [from, to). report is one of
+// "appointments", "cancellations", "revenue_by_specialization" or
+// "doctor_utilisation"; format is "csv" or "xlsx".
+type ExportReportRequest struct {
+	Report string    `json:"report"`
+	Format string    `json:"format"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// ReportChunk is one piece of the exported file. The first chunk carries the
+// file name and content type; concatenating data in order gives the file.
+type ReportChunk struct {
+	FileName    string `json:"file_name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"data"`
+}
@@ -0,0 +1,73 @@
+package extpb
+
+import "time"
+
+// AppointmentSummary is an appointment as returned by search and history RPCs
+type AppointmentSummary struct {
+	AppointmentId    int64     `json:"appointment_id"`
+	PatientId        string    `json:"patient_id"`
+	DoctorId         string    `json:"doctor_id"`
+	SpecializationId int32     `json:"specialization_id"`
+	AppointmentTime  time.Time `json:"appointment_time"`
+	Type             string    `json:"type"`
+	Status           string    `json:"status"`
+	PaymentMode      string    `json:"payment_mode"`
+	PaymentStatus    string    `json:"payment_status"`
+	Amount           float64   `json:"amount"`
+	Currency         string    `json:"currency"`
+}
+
+// SearchAppointmentsRequest filters appointments; empty fields are ignored.
+// from is inclusive and to exclusive. Pass next_cursor from a previous
+// response as cursor to fetch the following page.
+type SearchAppointmentsRequest struct {
+	PatientId        string    `json:"patient_id"`
+	DoctorId         string    `json:"doctor_id"`
+	SpecializationId int32     `json:"specialization_id"`
+	Status           string    `json:"status"`
+	Type             string    `json:"type"`
+	PaymentStatus    string    `json:"payment_status"`
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	SortOrder        string    `json:"sort_order"`
+	Limit            int32     `json:"limit"`
+	Cursor           string    `json:"cursor"`
+}
+
+type SearchAppointmentsResponse struct {
+	Status       string               `json:"status"`
+	StatusCode   int32                `json:"status_code"`
+	Message      string               `json:"message"`
+	Appointments []AppointmentSummary `json:"appointments"`
+	NextCursor   string               `json:"next_cursor"`
+}
+
+type PatientHistoryRequest struct {
+	PatientId string    `json:"patient_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Limit     int32     `json:"limit"`
+	Cursor    string    `json:"cursor"`
+}
+
+// PatientVisit links a past visit to its outcome. When has_prescription is
+// set, GetVisitDocument with kind "prescription" renders it; video_status is
+// set for video visits that had a session.
+type PatientVisit struct {
+	Appointment         AppointmentSummary `json:"appointment"`
+	DoctorName          string             `json:"doctor_name"`
+	ConsultationId      uint64             `json:"consultation_id"`
+	HasPrescription     bool               `json:"has_prescription"`
+	PrescriptionCount   int32              `json:"prescription_count"`
+	VideoRoomId         string             `json:"video_room_id"`
+	VideoStatus         string             `json:"video_status"`
+	ConsultationSeconds int32              `json:"consultation_seconds"`
+}
+
+type PatientHistoryResponse struct {
+	Status     string         `json:"status"`
+	StatusCode int32          `json:"status_code"`
+	Message    string         `json:"message"`
+	Visits     []PatientVisit `json:"visits"`
+	NextCursor string         `json:"next_cursor"`
+}
@@ -0,0 +1,309 @@
+// Package extpb holds the AppointmentExtService definitions that are not yet
+// part of hosp-connect-pb. Messages are plain Go structs carried over the JSON
+// codec, so the shapes here should be kept in sync with the proto once the
+// RPCs are upstreamed.
+package extpb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const ServiceName = "appointment.AppointmentExtService"
+
+// AppointmentExtServiceServer is the server API for AppointmentExtService.
+type AppointmentExtServiceServer interface {
+	CompleteAppointment(context.Context, *CompleteAppointmentRequest) (*StandardResponse, error)
+	GetPrescriptionHistory(context.Context, *GetPrescriptionHistoryRequest) (*GetPrescriptionHistoryResponse, error)
+	GetVisitDocument(context.Context, *GetVisitDocumentRequest) (*GetVisitDocumentResponse, error)
+	CreateFollowUp(context.Context, *CreateFollowUpRequest) (*CreateFollowUpResponse, error)
+	BookFollowUp(context.Context, *BookFollowUpRequest) (*BookFollowUpResponse, error)
+	GetFollowUpAdherence(context.Context, *FollowUpAdherenceRequest) (*FollowUpAdherenceResponse, error)
+	CreateVideoRoom(context.Context, *CreateVideoRoomRequest) (*VideoRoomResponse, error)
+	GetVideoRoom(context.Context, *GetVideoRoomRequest) (*VideoRoomResponse, error)
+	JoinVideoSession(context.Context, *VideoSessionRequest) (*VideoSessionResponse, error)
+	LeaveVideoSession(context.Context, *VideoSessionRequest) (*VideoSessionResponse, error)
+	EndVideoSession(context.Context, *VideoSessionRequest) (*VideoSessionResponse, error)
+	ListSpecializations(context.Context, *ListSpecializationsRequest) (*ListSpecializationsResponse, error)
+	GetSpecialization(context.Context, *GetSpecializationRequest) (*SpecializationResponse, error)
+	UpdateSpecialization(context.Context, *UpdateSpecializationRequest) (*SpecializationResponse, error)
+	ArchiveSpecialization(context.Context, *SpecializationIdRequest) (*StandardResponse, error)
+	RestoreSpecialization(context.Context, *SpecializationIdRequest) (*StandardResponse, error)
+	GetPriceQuote(context.Context, *GetPriceQuoteRequest) (*GetPriceQuoteResponse, error)
+	SetConsultationPrice(context.Context, *ConsultationPrice) (*ConsultationPriceResponse, error)
+	ListConsultationPrices(context.Context, *ListPricingRequest) (*ListConsultationPricesResponse, error)
+	DeleteConsultationPrice(context.Context, *DeletePricingRequest) (*StandardResponse, error)
+	AddPriceSurcharge(context.Context, *PriceSurcharge) (*PriceSurchargeResponse, error)
+	ListPriceSurcharges(context.Context, *ListPricingRequest) (*ListPriceSurchargesResponse, error)
+	DeletePriceSurcharge(context.Context, *DeletePricingRequest) (*StandardResponse, error)
+	BookAppointment(context.Context, *BookAppointmentRequest) (*BookAppointmentResponse, error)
+	CreatePromoCode(context.Context, *PromoCode) (*PromoCodeResponse, error)
+	ListPromoCodes(context.Context, *ListPromoCodesRequest) (*ListPromoCodesResponse, error)
+	DeactivatePromoCode(context.Context, *PromoCodeRequest) (*StandardResponse, error)
+	ValidatePromoCode(context.Context, *ValidatePromoCodeRequest) (*ValidatePromoCodeResponse, error)
+	ListPromoRedemptions(context.Context, *ListPromoRedemptionsRequest) (*ListPromoRedemptionsResponse, error)
+	ExportClaims(context.Context, *ExportClaimsRequest) (*ExportClaimsResponse, error)
+	RecordClinicPayment(context.Context, *RecordClinicPaymentRequest) (*RecordClinicPaymentResponse, error)
+	FetchRevenueStatistics(context.Context, *RevenueStatisticsRequest) (*RevenueStatisticsResponse, error)
+	SearchAppointments(context.Context, *SearchAppointmentsRequest) (*SearchAppointmentsResponse, error)
+	GetPatientHistory(context.Context, *PatientHistoryRequest) (*PatientHistoryResponse, error)
+	GetStatisticsReport(context.Context, *StatisticsReportRequest) (*StatisticsReportResponse, error)
+	DoctorCancelAppointment(context.Context, *DoctorCancelAppointmentRequest) (*StandardResponse, error)
+	RateAppointment(context.Context, *RateAppointmentRequest) (*StandardResponse, error)
+	GetDoctorPerformance(context.Context, *DoctorPerformanceRequest) (*DoctorPerformanceResponse, error)
+	ExportReport(*ExportReportRequest, AppointmentExtService_ExportReportServer) error
+	FetchStatisticsDashboard(context.Context, *StatisticsDashboardRequest) (*StatisticsDashboardResponse, error)
+	QueryAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
+	VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*VerifyAuditLogResponse, error)
+	ExportPatientData(context.Context, *PatientDataRequest) (*PatientDataResponse, error)
+	ErasePatientData(context.Context, *PatientDataRequest) (*ErasePatientDataResponse, error)
+	mustEmbedUnimplementedAppointmentExtServiceServer()
+}
+
+// UnimplementedAppointmentExtServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAppointmentExtServiceServer struct{}
+
+func (UnimplementedAppointmentExtServiceServer) CompleteAppointment(context.Context, *CompleteAppointmentRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteAppointment not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetPrescriptionHistory(context.Context, *GetPrescriptionHistoryRequest) (*GetPrescriptionHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrescriptionHistory not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetVisitDocument(context.Context, *GetVisitDocumentRequest) (*GetVisitDocumentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVisitDocument not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) CreateFollowUp(context.Context, *CreateFollowUpRequest) (*CreateFollowUpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFollowUp not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) BookFollowUp(context.Context, *BookFollowUpRequest) (*BookFollowUpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BookFollowUp not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetFollowUpAdherence(context.Context, *FollowUpAdherenceRequest) (*FollowUpAdherenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFollowUpAdherence not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) CreateVideoRoom(context.Context, *CreateVideoRoomRequest) (*VideoRoomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVideoRoom not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetVideoRoom(context.Context, *GetVideoRoomRequest) (*VideoRoomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVideoRoom not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) JoinVideoSession(context.Context, *VideoSessionRequest) (*VideoSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinVideoSession not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) LeaveVideoSession(context.Context, *VideoSessionRequest) (*VideoSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveVideoSession not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) EndVideoSession(context.Context, *VideoSessionRequest) (*VideoSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EndVideoSession not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ListSpecializations(context.Context, *ListSpecializationsRequest) (*ListSpecializationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSpecializations not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetSpecialization(context.Context, *GetSpecializationRequest) (*SpecializationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpecialization not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) UpdateSpecialization(context.Context, *UpdateSpecializationRequest) (*SpecializationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSpecialization not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ArchiveSpecialization(context.Context, *SpecializationIdRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveSpecialization not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) RestoreSpecialization(context.Context, *SpecializationIdRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSpecialization not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetPriceQuote(context.Context, *GetPriceQuoteRequest) (*GetPriceQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceQuote not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) SetConsultationPrice(context.Context, *ConsultationPrice) (*ConsultationPriceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConsultationPrice not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ListConsultationPrices(context.Context, *ListPricingRequest) (*ListConsultationPricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConsultationPrices not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) DeleteConsultationPrice(context.Context, *DeletePricingRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteConsultationPrice not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) AddPriceSurcharge(context.Context, *PriceSurcharge) (*PriceSurchargeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPriceSurcharge not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ListPriceSurcharges(context.Context, *ListPricingRequest) (*ListPriceSurchargesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPriceSurcharges not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) DeletePriceSurcharge(context.Context, *DeletePricingRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePriceSurcharge not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) BookAppointment(context.Context, *BookAppointmentRequest) (*BookAppointmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BookAppointment not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) CreatePromoCode(context.Context, *PromoCode) (*PromoCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePromoCode not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ListPromoCodes(context.Context, *ListPromoCodesRequest) (*ListPromoCodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPromoCodes not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) DeactivatePromoCode(context.Context, *PromoCodeRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeactivatePromoCode not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ValidatePromoCode(context.Context, *ValidatePromoCodeRequest) (*ValidatePromoCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidatePromoCode not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ListPromoRedemptions(context.Context, *ListPromoRedemptionsRequest) (*ListPromoRedemptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPromoRedemptions not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ExportClaims(context.Context, *ExportClaimsRequest) (*ExportClaimsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportClaims not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) RecordClinicPayment(context.Context, *RecordClinicPaymentRequest) (*RecordClinicPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordClinicPayment not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) FetchRevenueStatistics(context.Context, *RevenueStatisticsRequest) (*RevenueStatisticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchRevenueStatistics not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) SearchAppointments(context.Context, *SearchAppointmentsRequest) (*SearchAppointmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchAppointments not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetPatientHistory(context.Context, *PatientHistoryRequest) (*PatientHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPatientHistory not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetStatisticsReport(context.Context, *StatisticsReportRequest) (*StatisticsReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatisticsReport not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) DoctorCancelAppointment(context.Context, *DoctorCancelAppointmentRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DoctorCancelAppointment not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) RateAppointment(context.Context, *RateAppointmentRequest) (*StandardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RateAppointment not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) GetDoctorPerformance(context.Context, *DoctorPerformanceRequest) (*DoctorPerformanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDoctorPerformance not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ExportReport(*ExportReportRequest, AppointmentExtService_ExportReportServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportReport not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) FetchStatisticsDashboard(context.Context, *StatisticsDashboardRequest) (*StatisticsDashboardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchStatisticsDashboard not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) QueryAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAuditLog not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*VerifyAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAuditLog not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ExportPatientData(context.Context, *PatientDataRequest) (*PatientDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportPatientData not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) ErasePatientData(context.Context, *PatientDataRequest) (*ErasePatientDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ErasePatientData not implemented")
+}
+func (UnimplementedAppointmentExtServiceServer) mustEmbedUnimplementedAppointmentExtServiceServer() {}
+
+func RegisterAppointmentExtServiceServer(s grpc.ServiceRegistrar, srv AppointmentExtServiceServer) {
+	s.RegisterService(&AppointmentExtService_ServiceDesc, srv)
+}
+
+// unaryHandler adapts a typed server method to a grpc.MethodHandler.
+func unaryHandler[Req any, Resp any](method string, call func(AppointmentExtServiceServer, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(AppointmentExtServiceServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + ServiceName + "/" + method,
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(AppointmentExtServiceServer), ctx, req.(*Req))
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// AppointmentExtService_ServiceDesc is the grpc.ServiceDesc for AppointmentExtService service.
+var AppointmentExtService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AppointmentExtServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler("CompleteAppointment", AppointmentExtServiceServer.CompleteAppointment),
+		unaryHandler("GetPrescriptionHistory", AppointmentExtServiceServer.GetPrescriptionHistory),
+		unaryHandler("GetVisitDocument", AppointmentExtServiceServer.GetVisitDocument),
+		unaryHandler("CreateFollowUp", AppointmentExtServiceServer.CreateFollowUp),
+		unaryHandler("BookFollowUp", AppointmentExtServiceServer.BookFollowUp),
+		unaryHandler("GetFollowUpAdherence", AppointmentExtServiceServer.GetFollowUpAdherence),
+		unaryHandler("CreateVideoRoom", AppointmentExtServiceServer.CreateVideoRoom),
+		unaryHandler("GetVideoRoom", AppointmentExtServiceServer.GetVideoRoom),
+		unaryHandler("JoinVideoSession", AppointmentExtServiceServer.JoinVideoSession),
+		unaryHandler("LeaveVideoSession", AppointmentExtServiceServer.LeaveVideoSession),
+		unaryHandler("EndVideoSession", AppointmentExtServiceServer.EndVideoSession),
+		unaryHandler("ListSpecializations", AppointmentExtServiceServer.ListSpecializations),
+		unaryHandler("GetSpecialization", AppointmentExtServiceServer.GetSpecialization),
+		unaryHandler("UpdateSpecialization", AppointmentExtServiceServer.UpdateSpecialization),
+		unaryHandler("ArchiveSpecialization", AppointmentExtServiceServer.ArchiveSpecialization),
+		unaryHandler("RestoreSpecialization", AppointmentExtServiceServer.RestoreSpecialization),
+		unaryHandler("GetPriceQuote", AppointmentExtServiceServer.GetPriceQuote),
+		unaryHandler("SetConsultationPrice", AppointmentExtServiceServer.SetConsultationPrice),
+		unaryHandler("ListConsultationPrices", AppointmentExtServiceServer.ListConsultationPrices),
+		unaryHandler("DeleteConsultationPrice", AppointmentExtServiceServer.DeleteConsultationPrice),
+		unaryHandler("AddPriceSurcharge", AppointmentExtServiceServer.AddPriceSurcharge),
+		unaryHandler("ListPriceSurcharges", AppointmentExtServiceServer.ListPriceSurcharges),
+		unaryHandler("DeletePriceSurcharge", AppointmentExtServiceServer.DeletePriceSurcharge),
+		unaryHandler("BookAppointment", AppointmentExtServiceServer.BookAppointment),
+		unaryHandler("CreatePromoCode", AppointmentExtServiceServer.CreatePromoCode),
+		unaryHandler("ListPromoCodes", AppointmentExtServiceServer.ListPromoCodes),
+		unaryHandler("DeactivatePromoCode", AppointmentExtServiceServer.DeactivatePromoCode),
+		unaryHandler("ValidatePromoCode", AppointmentExtServiceServer.ValidatePromoCode),
+		unaryHandler("ListPromoRedemptions", AppointmentExtServiceServer.ListPromoRedemptions),
+		unaryHandler("ExportClaims", AppointmentExtServiceServer.ExportClaims),
+		unaryHandler("RecordClinicPayment", AppointmentExtServiceServer.RecordClinicPayment),
+		unaryHandler("FetchRevenueStatistics", AppointmentExtServiceServer.FetchRevenueStatistics),
+		unaryHandler("SearchAppointments", AppointmentExtServiceServer.SearchAppointments),
+		unaryHandler("GetPatientHistory", AppointmentExtServiceServer.GetPatientHistory),
+		unaryHandler("GetStatisticsReport", AppointmentExtServiceServer.GetStatisticsReport),
+		unaryHandler("DoctorCancelAppointment", AppointmentExtServiceServer.DoctorCancelAppointment),
+		unaryHandler("RateAppointment", AppointmentExtServiceServer.RateAppointment),
+		unaryHandler("GetDoctorPerformance", AppointmentExtServiceServer.GetDoctorPerformance),
+		unaryHandler("FetchStatisticsDashboard", AppointmentExtServiceServer.FetchStatisticsDashboard),
+		unaryHandler("QueryAuditLog", AppointmentExtServiceServer.QueryAuditLog),
+		unaryHandler("VerifyAuditLog", AppointmentExtServiceServer.VerifyAuditLog),
+		unaryHandler("ExportPatientData", AppointmentExtServiceServer.ExportPatientData),
+		unaryHandler("ErasePatientData", AppointmentExtServiceServer.ErasePatientData),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportReport",
+			Handler:       _AppointmentExtService_ExportReport_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// AppointmentExtService_ExportReportServer is the server side of the ExportReport stream.
+type AppointmentExtService_ExportReportServer interface {
+	Send(*ReportChunk) error
+	grpc.ServerStream
+}
+
+type appointmentExtServiceExportReportServer struct {
+	grpc.ServerStream
+}
+
+func (x *appointmentExtServiceExportReportServer) Send(m *ReportChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AppointmentExtService_ExportReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AppointmentExtServiceServer).ExportReport(m, &appointmentExtServiceExportReportServer{stream})
+}
@@ -0,0 +1,51 @@
+package extpb
+
+import "time"
+
+type Specialization struct {
+	Id           uint64     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Slug         string     `json:"slug"`
+	Icon         string     `json:"icon"`
+	DisplayOrder int32      `json:"display_order"`
+	IsActive     bool       `json:"is_active"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+}
+
+type ListSpecializationsRequest struct {
+	IncludeArchived bool `json:"include_archived"`
+}
+
+type ListSpecializationsResponse struct {
+	Status          string           `json:"status"`
+	StatusCode      int32            `json:"status_code"`
+	Message         string           `json:"message"`
+	Specializations []Specialization `json:"specializations"`
+}
+
+// GetSpecializationRequest looks a specialization up by id, or by slug when id is zero.
+type GetSpecializationRequest struct {
+	Id   uint64 `json:"id"`
+	Slug string `json:"slug"`
+}
+
+type UpdateSpecializationRequest struct {
+	Id           uint64 `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Slug         string `json:"slug"`
+	Icon         string `json:"icon"`
+	DisplayOrder int32  `json:"display_order"`
+}
+
+type SpecializationResponse struct {
+	Status         string          `json:"status"`
+	StatusCode     int32           `json:"status_code"`
+	Message        string          `json:"message"`
+	Specialization *Specialization `json:"specialization,omitempty"`
+}
+
+type SpecializationIdRequest struct {
+	Id uint64 `json:"id"`
+}
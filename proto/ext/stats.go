@@ -0,0 +1,81 @@
+package extpb
+
+import "time"
+
+// StatisticsReportRequest covers [from, to); interval is "day" (default) or "week"
+type StatisticsReportRequest struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Interval string    `json:"interval"`
+}
+
+type StatsBucket struct {
+	Start        time.Time `json:"start"`
+	Appointments int32     `json:"appointments"`
+	Completed    int32     `json:"completed"`
+	Cancelled    int32     `json:"cancelled"`
+	NoShows      int32     `json:"no_shows"`
+	Revenue      float64   `json:"revenue"`
+}
+
+type SpecializationReport struct {
+	SpecializationName string  `json:"specialization_name"`
+	Appointments       int32   `json:"appointments"`
+	Cancelled          int32   `json:"cancelled"`
+	NoShows            int32   `json:"no_shows"`
+	Revenue            float64 `json:"revenue"`
+}
+
+type DoctorUtilisation struct {
+	DoctorId       string  `json:"doctor_id"`
+	BookedSlots    int32   `json:"booked_slots"`
+	AvailableSlots int32   `json:"available_slots"`
+	Utilisation    float64 `json:"utilisation"`
+}
+
+// StatisticsReportResponse rates and utilisation are fractions between 0 and 1.
+// revenue is the booked amount of appointments that were not cancelled.
+type StatisticsReportResponse struct {
+	Status           string                 `json:"status"`
+	StatusCode       int32                  `json:"status_code"`
+	Message          string                 `json:"message"`
+	From             time.Time              `json:"from"`
+	To               time.Time              `json:"to"`
+	Interval         string                 `json:"interval"`
+	Appointments     int32                  `json:"appointments"`
+	Completed        int32                  `json:"completed"`
+	Cancelled        int32                  `json:"cancelled"`
+	NoShows          int32                  `json:"no_shows"`
+	CancellationRate float64                `json:"cancellation_rate"`
+	NoShowRate       float64                `json:"no_show_rate"`
+	Revenue          float64                `json:"revenue"`
+	Utilisation      float64                `json:"utilisation"`
+	Series           []StatsBucket          `json:"series"`
+	Specializations  []SpecializationReport `json:"specializations"`
+	Doctors          []DoctorUtilisation    `json:"doctors"`
+}
+
+// StatisticsDashboardRequest takes the same param as FetchStatisticsDetails: day, week, month or all
+type StatisticsDashboardRequest struct {
+	Param string `json:"param"`
+}
+
+type SpecializationCount struct {
+	SpecializationName string `json:"specialization_name"`
+	AppointmentCount   int32  `json:"appointment_count"`
+}
+
+// StatisticsDashboardResponse is FetchStatisticsDetails with partial results:
+// sections listed in unavailable could not be fetched and are left at zero.
+type StatisticsDashboardResponse struct {
+	Status              string                `json:"status"`
+	StatusCode          int32                 `json:"status_code"`
+	Message             string                `json:"message"`
+	TotalPatients       int32                 `json:"total_patients"`
+	TotalDoctors        int32                 `json:"total_doctors"`
+	TotalAppointments   int32                 `json:"total_appointments"`
+	TotalRevenue        float64               `json:"total_revenue"`
+	ExpectedRevenue     float64               `json:"expected_revenue"`
+	SpecializationStats []SpecializationCount `json:"specialization_stats"`
+	Unavailable         []string              `json:"unavailable"`
+}
@@ -0,0 +1,37 @@
+package extpb
+
+import "time"
+
+type VideoSessionRequest struct {
+	RoomId        string `json:"room_id"`
+	ParticipantId string `json:"participant_id"`
+}
+
+type VideoSessionResponse struct {
+	Status              string     `json:"status"`
+	StatusCode          int32      `json:"status_code"`
+	Message             string     `json:"message"`
+	SessionStatus       string     `json:"session_status"`
+	StartedAt           *time.Time `json:"started_at,omitempty"`
+	EndedAt             *time.Time `json:"ended_at,omitempty"`
+	ConsultationSeconds int32      `json:"consultation_seconds"`
+	DoctorNoShow        bool       `json:"doctor_no_show"`
+	PatientNoShow       bool       `json:"patient_no_show"`
+}
+
+type CreateVideoRoomRequest struct {
+	AppointmentId int32  `json:"appointment_id"`
+	DoctorId      string `json:"doctor_id"`
+}
+
+type GetVideoRoomRequest struct {
+	AppointmentId int32  `json:"appointment_id"`
+	PatientId     string `json:"patient_id"`
+}
+
+type VideoRoomResponse struct {
+	Status     string `json:"status"`
+	StatusCode int32  `json:"status_code"`
+	Message    string `json:"message"`
+	RoomUrl    string `json:"room_url"`
+}